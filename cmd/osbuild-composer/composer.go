@@ -16,6 +16,7 @@ import (
 	"github.com/osbuild/osbuild-composer/internal/common"
 	"github.com/osbuild/osbuild-composer/internal/jobqueue/fsjobqueue"
 	"github.com/osbuild/osbuild-composer/internal/kojiapi"
+	"github.com/osbuild/osbuild-composer/internal/ostree"
 	"github.com/osbuild/osbuild-composer/internal/rpmmd"
 	"github.com/osbuild/osbuild-composer/internal/store"
 	"github.com/osbuild/osbuild-composer/internal/upload/koji"
@@ -42,8 +43,9 @@ type Composer struct {
 	weldr   *weldr.API
 	api     *cloudapi.Server
 	koji    *kojiapi.Server
+	ostree  *ostree.Repo
 
-	weldrListener, localWorkerListener, workerListener, apiListener net.Listener
+	weldrListener, localWorkerListener, workerListener, apiListener, ostreeListener net.Listener
 }
 
 func NewComposer(config *ComposerConfigFile, stateDir, cacheDir string, logger *log.Logger) (*Composer, error) {
@@ -124,6 +126,20 @@ func (c *Composer) InitWeldr(repoPaths []string, weldrListener, localWorkerListe
 
 	c.weldr = weldr.New(c.rpm, arch, hostDistro, repos[archName], c.logger, store, c.workers, compatOutputDir)
 
+	if c.config.OSTree.ImportCommits {
+		ostreeDir, err := c.ensureStateDirectory("ostree", 0755)
+		if err != nil {
+			return err
+		}
+
+		c.ostree, err = ostree.NewRepo(ostreeDir)
+		if err != nil {
+			return err
+		}
+
+		c.weldr.SetOSTreeRepo(c.ostree)
+	}
+
 	c.weldrListener = weldrListener
 	c.localWorkerListener = localWorkerListener
 
@@ -175,6 +191,18 @@ func (c *Composer) InitRemoteWorkers(cert, key string, l net.Listener) error {
 	return nil
 }
 
+// InitOSTreeRepo serves the repository of imported commits on l. It must be
+// called after InitWeldr(). Without a repository, because importing commits
+// is not enabled, the listener stays unused.
+func (c *Composer) InitOSTreeRepo(l net.Listener) {
+	if c.ostree == nil {
+		log.Println("Warning: osbuild-composer-ostree.socket is enabled, but importing commits is not, the socket is not served")
+		return
+	}
+
+	c.ostreeListener = l
+}
+
 // Start Composer with all the APIs that had their respective Init*() called.
 //
 // Running without the weldr API is currently not supported.
@@ -226,6 +254,20 @@ func (c *Composer) Start() error {
 		}()
 	}
 
+	if c.ostreeListener != nil {
+		go func() {
+			s := &http.Server{
+				ErrorLog: c.logger,
+				Handler:  c.ostree.Handler(),
+			}
+
+			err := s.Serve(c.ostreeListener)
+			if err != nil {
+				panic(err)
+			}
+		}()
+	}
+
 	return c.weldr.Serve(c.weldrListener)
 }
 
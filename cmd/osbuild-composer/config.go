@@ -21,6 +21,9 @@ type ComposerConfigFile struct {
 		AllowedDomains []string `toml:"allowed_domains"`
 		CA             string   `toml:"ca"`
 	} `toml:"worker"`
+	OSTree struct {
+		ImportCommits bool `toml:"import_commits"`
+	} `toml:"ostree"`
 }
 
 func LoadConfig(name string) (*ComposerConfigFile, error) {
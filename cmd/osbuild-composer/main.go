@@ -88,6 +88,14 @@ func main() {
 		}
 	}
 
+	if l, exists := listeners["osbuild-composer-ostree.socket"]; exists {
+		if len(l) != 1 {
+			log.Fatal("The osbuild-composer-ostree.socket unit is misconfigured. It should contain only one socket.")
+		}
+
+		composer.InitOSTreeRepo(l[0])
+	}
+
 	if l, exists := listeners["osbuild-remote-worker.socket"]; exists {
 		if len(l) != 1 {
 			log.Fatal("The osbuild-remote-worker.socket unit is misconfigured. It should contain only one socket.")
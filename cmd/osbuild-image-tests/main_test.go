@@ -29,7 +29,6 @@ import (
 	"github.com/osbuild/osbuild-composer/internal/boot/openstacktest"
 	"github.com/osbuild/osbuild-composer/internal/boot/vmwaretest"
 	"github.com/osbuild/osbuild-composer/internal/common"
-	"github.com/osbuild/osbuild-composer/internal/upload/vmware"
 )
 
 type testcaseStruct struct {
@@ -373,16 +372,6 @@ func testBootUsingVMware(t *testing.T, imagePath string) {
 	}
 	require.NoError(t, err)
 
-	// convert to streamOptimized vmdk
-	imageF, err := vmware.OpenAsStreamOptimizedVmdk(imagePath)
-	require.NoError(t, err)
-	// we don't need the file descriptor to be opened b/c import.vmdk operates
-	// on the file path
-	imageF.Close()
-	imagePath = imageF.Name()
-	require.NotEqual(t, "", imagePath)
-	defer os.Remove(imagePath)
-
 	// create a random test id to name all the resources used in this test
 	imageName, err := GenerateCIArtifactName("osbuild-image-tests-vmware-image-")
 	require.NoError(t, err)
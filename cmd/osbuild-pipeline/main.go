@@ -128,7 +128,7 @@ func main() {
 		panic("Could not depsolve: " + err.Error())
 	}
 
-	buildPkgs := imageType.BuildPackages()
+	buildPkgs := imageType.BuildPackages(composeRequest.Blueprint)
 	buildPackageSpecs, _, err := rpmmd.Depsolve(buildPkgs, nil, repos, d.ModulePlatformID(), arch.Name())
 	if err != nil {
 		panic("Could not depsolve build packages: " + err.Error())
@@ -22,7 +22,7 @@ func getManifest(bp blueprint.Blueprint, t distro.ImageType, a distro.Arch, d di
 	if err != nil {
 		panic(err)
 	}
-	buildPkgs, _, err := rpmmd.Depsolve(t.BuildPackages(), nil, repos, d.ModulePlatformID(), a.Name())
+	buildPkgs, _, err := rpmmd.Depsolve(t.BuildPackages(bp), nil, repos, d.ModulePlatformID(), a.Name())
 	if err != nil {
 		panic(err)
 	}
@@ -2,11 +2,13 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"net/http"
@@ -19,11 +21,14 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/osbuild/osbuild-composer/internal/common"
+	"github.com/osbuild/osbuild-composer/internal/distro"
 	"github.com/osbuild/osbuild-composer/internal/osbuild"
 	"github.com/osbuild/osbuild-composer/internal/target"
 	"github.com/osbuild/osbuild-composer/internal/upload/awsupload"
 	"github.com/osbuild/osbuild-composer/internal/upload/azure"
+	"github.com/osbuild/osbuild-composer/internal/upload/gcp"
 	"github.com/osbuild/osbuild-composer/internal/upload/koji"
+	"github.com/osbuild/osbuild-composer/internal/upload/registry"
 	"github.com/osbuild/osbuild-composer/internal/upload/vmware"
 	"github.com/osbuild/osbuild-composer/internal/worker"
 )
@@ -96,10 +101,59 @@ func osbuildStagesToRPMs(stages []osbuild.StageResult) []koji.RPM {
 	return rpms
 }
 
-func RunJob(job worker.Job, store string, kojiServers map[string]koji.GSSAPICredentials) (*osbuild.Result, error) {
+// Downloads the inputs of job into directory and resolves them in manifest.
+func fetchInputs(job worker.Job, manifest distro.Manifest, directory string) (distro.Manifest, error) {
+	inputs, err := job.OSBuildInputs()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, input := range inputs {
+		p := path.Join(directory, input.Name)
+		f, err := os.Create(p)
+		if err != nil {
+			return nil, fmt.Errorf("error creating input file: %v", err)
+		}
+
+		hash := sha256.New()
+		err = job.DownloadInput(input.Name, io.MultiWriter(f, hash))
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		checksum := fmt.Sprintf("sha256:%x", hash.Sum(nil))
+		manifest, err = osbuild.ResolveInput(manifest, input.Name, checksum, "file://"+p)
+		if err != nil {
+			return nil, fmt.Errorf("error resolving input %s: %v", input.Name, err)
+		}
+	}
+
+	return manifest, nil
+}
+
+// Uploads the file at imagePath as the artifact name of job, optionally
+// converting it to a stream optimized vmdk first.
+func uploadLocalArtifact(job worker.Job, imagePath, name string, streamOptimized bool) error {
+	var f *os.File
+	var err error
+	if streamOptimized {
+		f, err = vmware.OpenAsStreamOptimizedVmdk(imagePath)
+	} else {
+		f, err = os.Open(imagePath)
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return job.UploadArtifact(name, f)
+}
+
+func RunJob(job worker.Job, store string, kojiServers map[string]koji.GSSAPICredentials) (*osbuild.Result, []*target.TargetResult, error) {
 	outputDirectory, err := ioutil.TempDir("/var/tmp", "osbuild-worker-*")
 	if err != nil {
-		return nil, fmt.Errorf("error creating temporary output directory: %v", err)
+		return nil, nil, fmt.Errorf("error creating temporary output directory: %v", err)
 	}
 	defer func() {
 		err := os.RemoveAll(outputDirectory)
@@ -110,45 +164,53 @@ func RunJob(job worker.Job, store string, kojiServers map[string]koji.GSSAPICred
 
 	manifest, targets, err := job.OSBuildArgs()
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	inputDirectory, err := ioutil.TempDir("/var/tmp", "osbuild-worker-inputs-*")
+	if err != nil {
+		return nil, nil, fmt.Errorf("error creating temporary input directory: %v", err)
+	}
+	defer func() {
+		err := os.RemoveAll(inputDirectory)
+		if err != nil {
+			log.Printf("Error removing temporary input directory (%s): %v", inputDirectory, err)
+		}
+	}()
+
+	manifest, err = fetchInputs(job, manifest, inputDirectory)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	start_time := time.Now()
 
 	result, err := RunOSBuild(manifest, store, outputDirectory, os.Stderr)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	end_time := time.Now()
 
 	var r []error
+	var targetResults []*target.TargetResult
 
 	for _, t := range targets {
 		switch options := t.Options.(type) {
 		case *target.LocalTargetOptions:
-			var f *os.File
-			imagePath := path.Join(outputDirectory, options.Filename)
-			if options.StreamOptimized {
-				f, err = vmware.OpenAsStreamOptimizedVmdk(imagePath)
-				if err != nil {
-					r = append(r, err)
-					continue
-				}
-			} else {
-				f, err = os.Open(imagePath)
+			filenames := options.Filenames
+			if len(filenames) == 0 {
+				filenames = []string{options.Filename}
+			}
+
+			for _, filename := range filenames {
+				err = uploadLocalArtifact(job, path.Join(outputDirectory, filename), filename, options.StreamOptimized)
 				if err != nil {
 					r = append(r, err)
-					continue
+					break
 				}
 			}
 
-			err = job.UploadArtifact(options.Filename, f)
-			if err != nil {
-				r = append(r, err)
-				continue
-			}
-
 		case *target.AWSTargetOptions:
 
 			a, err := awsupload.New(options.Region, options.AccessKeyID, options.SecretAccessKey)
@@ -287,6 +349,75 @@ func RunJob(job worker.Job, store string, kojiServers map[string]koji.GSSAPICred
 				r = append(r, err)
 				continue
 			}
+		case *target.ContainerRegistryTargetOptions:
+			var credentials *registry.Credentials
+			if options.AuthFile != "" {
+				credentials, err = registry.ReadAuthFile(options.AuthFile, options.Registry)
+				if err != nil {
+					r = append(r, err)
+					continue
+				}
+			} else if options.Username != "" {
+				credentials = &registry.Credentials{
+					Username: options.Username,
+					Password: options.Password,
+				}
+			}
+
+			client, err := registry.NewClient(options.Registry, credentials)
+			if err != nil {
+				r = append(r, err)
+				continue
+			}
+
+			digest, err := client.Push(path.Join(outputDirectory, options.Filename), options.Repository, options.Tag)
+			if err != nil {
+				r = append(r, err)
+				continue
+			}
+
+			log.Printf("Pushed %s/%s:%s (%s)", options.Registry, options.Repository, options.Tag, digest)
+			targetResults = append(targetResults, target.NewContainerRegistryTargetResult(&target.ContainerRegistryTargetResultOptions{
+				Registry:   options.Registry,
+				Repository: options.Repository,
+				Tag:        options.Tag,
+				Digest:     digest,
+			}))
+		case *target.GCPTargetOptions:
+			g, err := gcp.New([]byte(options.Credentials))
+			if err != nil {
+				r = append(r, err)
+				continue
+			}
+
+			object := options.Object
+			if object == "" {
+				object = uuid.New().String() + ".tar.gz"
+			}
+
+			err = g.Upload(path.Join(outputDirectory, options.Filename), options.Bucket, object)
+			if err != nil {
+				r = append(r, err)
+				continue
+			}
+
+			err = g.CreateImage(options.Project, options.ImageName, options.Bucket, object)
+			if err != nil {
+				r = append(r, err)
+				continue
+			}
+
+			// the image does not need the uploaded file once it exists
+			err = g.DeleteObject(options.Bucket, object)
+			if err != nil {
+				log.Printf("Error deleting %s from bucket %s: %v", object, options.Bucket, err)
+			}
+
+			log.Printf("Created image %s in project %s", options.ImageName, options.Project)
+			targetResults = append(targetResults, target.NewGCPTargetResult(&target.GCPTargetResultOptions{
+				Project:   options.Project,
+				ImageName: options.ImageName,
+			}))
 		default:
 			r = append(r, fmt.Errorf("invalid target type"))
 		}
@@ -298,10 +429,10 @@ func RunJob(job worker.Job, store string, kojiServers map[string]koji.GSSAPICred
 	}
 
 	if len(r) > 0 {
-		return result, &TargetsError{r}
+		return result, targetResults, &TargetsError{r}
 	}
 
-	return result, nil
+	return result, targetResults, nil
 }
 
 func FailJob(job worker.Job, kojiServers map[string]koji.GSSAPICredentials) {
@@ -460,7 +591,7 @@ func main() {
 		go WatchJob(ctx, job)
 
 		var status common.ImageBuildState
-		result, err := RunJob(job, store, kojiServers)
+		result, targetResults, err := RunJob(job, store, kojiServers)
 		if err != nil {
 			log.Printf("  Job failed: %v", err)
 			status = common.IBFailed
@@ -496,7 +627,7 @@ func main() {
 		// signal to WatchJob() that it can stop watching
 		cancel()
 
-		err = job.Update(status, result)
+		err = job.Update(status, result, targetResults)
 		if err != nil {
 			log.Fatalf("Error reporting job result: %v", err)
 		}
@@ -28,4 +28,5 @@ require (
 	github.com/vmware/govmomi v0.23.0
 	golang.org/x/net v0.0.0-20200202094626-16171245cfb2 // indirect
 	golang.org/x/sys v0.0.0-20200212091648-12a6c2dcc1e4
+	gopkg.in/yaml.v2 v2.3.0
 )
@@ -54,7 +54,18 @@ func (b *Blueprint) DeepCopy() Blueprint {
 	return bp
 }
 
+// Redacted returns a deep copy of the blueprint without secrets, which is
+// safe to show to API clients
+func (b *Blueprint) Redacted() Blueprint {
+	bp := b.DeepCopy()
+	if bp.Customizations != nil && bp.Customizations.Subscription != nil {
+		bp.Customizations.Subscription.ActivationKey = ""
+	}
+	return bp
+}
+
 // Initialize ensures that the blueprint has sane defaults for any missing fields
+// and rejects customizations that cannot be applied to an image
 func (b *Blueprint) Initialize() error {
 	if b.Packages == nil {
 		b.Packages = []Package{}
@@ -73,7 +84,7 @@ func (b *Blueprint) Initialize() error {
 	if err != nil {
 		return fmt.Errorf("Invalid 'version', must use Semantic Versioning: %s", err.Error())
 	}
-	return nil
+	return b.Customizations.Validate()
 }
 
 // BumpVersion increments the previous blueprint's version
@@ -30,6 +30,33 @@ func TestDeepCopy(t *testing.T) {
 	require.Equalf(t, bpCopy.Packages[0].Version, "1.2.3", "Blueprint.DeepCopy failed, copy modified.")
 }
 
+func TestRedacted(t *testing.T) {
+	bpOrig := Blueprint{
+		Name:    "redacted-test",
+		Version: "0.0.1",
+		Customizations: &Customizations{
+			Subscription: &SubscriptionCustomization{
+				Organization:  2040324,
+				ActivationKey: "my-secret-key",
+				ServerURL:     "subscription.rhsm.redhat.com",
+				BaseURL:       "http://cdn.redhat.com/",
+			},
+		},
+	}
+
+	bpRedacted := bpOrig.Redacted()
+	require.Equal(t, "", bpRedacted.Customizations.Subscription.ActivationKey)
+	require.Equal(t, 2040324, bpRedacted.Customizations.Subscription.Organization)
+	require.Equal(t, "my-secret-key", bpOrig.Customizations.Subscription.ActivationKey)
+
+	// pushing a fetched blueprint back must not silently drop the key
+	require.NoError(t, bpOrig.Initialize())
+	require.Error(t, bpRedacted.Initialize())
+
+	bpEmpty := Blueprint{Name: "redacted-empty"}
+	require.Equal(t, bpEmpty, bpEmpty.Redacted())
+}
+
 func TestBlueprintInitialize(t *testing.T) {
 	cases := []struct {
 		NewBlueprint  Blueprint
@@ -43,6 +70,12 @@ func TestBlueprintInitialize(t *testing.T) {
 		{Blueprint{Name: "bp-test-5", Description: "Invalid version 5", Version: "foo"}, true},
 		{Blueprint{Name: "bp-test-7", Description: "Zero version", Version: "0.0.0"}, false},
 		{Blueprint{Name: "bp-test-8", Description: "X.Y.Z version", Version: "2.1.3"}, false},
+		{Blueprint{Name: "bp-test-9", Description: "Valid SELinux mode", Customizations: &Customizations{SELinux: &SELinuxCustomization{Mode: "permissive"}}}, false},
+		{Blueprint{Name: "bp-test-10", Description: "Invalid SELinux mode", Customizations: &Customizations{SELinux: &SELinuxCustomization{Mode: "disabled"}}}, true},
+		{Blueprint{Name: "bp-test-11", Description: "Invalid subscription organization", Customizations: &Customizations{Subscription: &SubscriptionCustomization{ActivationKey: "key", ServerURL: "subscription.rhsm.redhat.com", BaseURL: "http://cdn.redhat.com/"}}}, true},
+		{Blueprint{Name: "bp-test-12", Description: "Valid subscription", Customizations: &Customizations{Subscription: &SubscriptionCustomization{Organization: 2040324, ActivationKey: "key", ServerURL: "subscription.rhsm.redhat.com", BaseURL: "http://cdn.redhat.com/"}}}, false},
+		{Blueprint{Name: "bp-test-13", Description: "Subscription without server URL", Customizations: &Customizations{Subscription: &SubscriptionCustomization{Organization: 2040324, ActivationKey: "key", BaseURL: "http://cdn.redhat.com/"}}}, true},
+		{Blueprint{Name: "bp-test-14", Description: "Subscription without base URL", Customizations: &Customizations{Subscription: &SubscriptionCustomization{Organization: 2040324, ActivationKey: "key", ServerURL: "subscription.rhsm.redhat.com"}}}, true},
 	}
 
 	for _, c := range cases {
@@ -0,0 +1,129 @@
+package blueprint
+
+import (
+	"fmt"
+	"regexp"
+
+	"gopkg.in/yaml.v2"
+)
+
+// A CloudInitCustomization configures cloud-init in image types which
+// include it. Snippets are additional files for /etc/cloud/cloud.cfg.d,
+// given as YAML.
+type CloudInitCustomization struct {
+	DefaultUser string                          `json:"default_user,omitempty" toml:"default_user,omitempty"`
+	DisableRoot *bool                           `json:"disable_root,omitempty" toml:"disable_root,omitempty"`
+	Datasources []string                        `json:"datasources,omitempty" toml:"datasources,omitempty"`
+	Snippets    []CloudInitSnippetCustomization `json:"snippets,omitempty" toml:"snippets,omitempty"`
+}
+
+type CloudInitSnippetCustomization struct {
+	Filename string `json:"filename" toml:"filename"`
+	Content  string `json:"content" toml:"content"`
+}
+
+// CloudInitConfigFilename is the name of the file in /etc/cloud/cloud.cfg.d
+// the settings of CloudInitCustomization are written to.
+const CloudInitConfigFilename = "10-osbuild-composer.cfg"
+
+func (c *Customizations) GetCloudInit() *CloudInitCustomization {
+	if c == nil {
+		return nil
+	}
+
+	return c.CloudInit
+}
+
+var validCloudInitSnippetFilename = regexp.MustCompile(`^[a-zA-Z0-9_-][a-zA-Z0-9_.-]*\.cfg$`)
+var validCloudInitDatasource = regexp.MustCompile(`^[a-zA-Z0-9]+$`)
+var validUserName = regexp.MustCompile(`^[a-z_][a-z0-9_-]*[$]?$`)
+
+func (c *CloudInitCustomization) validate() error {
+	if c.DefaultUser != "" && !validUserName.MatchString(c.DefaultUser) {
+		return &CustomizationError{fmt.Sprintf("Invalid cloud-init default user: %q", c.DefaultUser)}
+	}
+
+	for _, datasource := range c.Datasources {
+		if !validCloudInitDatasource.MatchString(datasource) {
+			return &CustomizationError{fmt.Sprintf("Invalid cloud-init datasource: %q", datasource)}
+		}
+	}
+
+	filenames := map[string]bool{CloudInitConfigFilename: true}
+	for _, snippet := range c.Snippets {
+		if !validCloudInitSnippetFilename.MatchString(snippet.Filename) {
+			return &CustomizationError{fmt.Sprintf("Invalid cloud-init snippet filename: %q", snippet.Filename)}
+		}
+		if filenames[snippet.Filename] {
+			return &CustomizationError{fmt.Sprintf("Duplicate cloud-init snippet: %s", snippet.Filename)}
+		}
+		filenames[snippet.Filename] = true
+
+		if _, err := snippet.GetConfig(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetConfig returns the settings of the customization as cloud-init config,
+// i.e. in the structure of cloud.cfg. It returns nil if nothing is set.
+func (c *CloudInitCustomization) GetConfig() map[string]interface{} {
+	config := map[string]interface{}{}
+
+	if c.DefaultUser != "" {
+		config["system_info"] = map[string]interface{}{
+			"default_user": map[string]interface{}{
+				"name": c.DefaultUser,
+			},
+		}
+	}
+	if c.DisableRoot != nil {
+		config["disable_root"] = *c.DisableRoot
+	}
+	if len(c.Datasources) > 0 {
+		config["datasource_list"] = c.Datasources
+	}
+
+	if len(config) == 0 {
+		return nil
+	}
+	return config
+}
+
+// GetConfig parses the content of the snippet, which must be a YAML mapping.
+func (s *CloudInitSnippetCustomization) GetConfig() (map[string]interface{}, error) {
+	var content interface{}
+	if err := yaml.Unmarshal([]byte(s.Content), &content); err != nil {
+		return nil, &CustomizationError{fmt.Sprintf("Invalid cloud-init snippet %s: %v", s.Filename, err)}
+	}
+
+	config, ok := convertYAML(content).(map[string]interface{})
+	if !ok {
+		return nil, &CustomizationError{fmt.Sprintf("Invalid cloud-init snippet %s: not a mapping", s.Filename)}
+	}
+
+	return config, nil
+}
+
+// convertYAML converts the maps decoded by the yaml package, whose keys are
+// interface{}, to maps with string keys, so that the result can be encoded
+// as JSON.
+func convertYAML(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			m[fmt.Sprint(key)] = convertYAML(value)
+		}
+		return m
+	case []interface{}:
+		for i, value := range v {
+			v[i] = convertYAML(value)
+		}
+		return v
+	default:
+		return v
+	}
+}
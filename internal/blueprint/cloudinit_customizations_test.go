@@ -0,0 +1,74 @@
+package blueprint
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCloudInit(t *testing.T) {
+	disableRoot := true
+	expectedCloudInit := CloudInitCustomization{
+		DefaultUser: "cloud-user",
+		DisableRoot: &disableRoot,
+		Datasources: []string{"Ec2", "None"},
+		Snippets: []CloudInitSnippetCustomization{
+			{
+				Filename: "99-ssh.cfg",
+				Content:  "ssh_pwauth: false\nssh_authorized_keys:\n  - ssh-ed25519 AAAA\nphone_home:\n  tries: 3\n",
+			},
+		},
+	}
+
+	TestCustomizations := Customizations{
+		CloudInit: &expectedCloudInit,
+	}
+
+	retCloudInit := TestCustomizations.GetCloudInit()
+	assert.Equal(t, &expectedCloudInit, retCloudInit)
+	assert.NoError(t, TestCustomizations.Validate())
+
+	assert.Equal(t, map[string]interface{}{
+		"system_info": map[string]interface{}{
+			"default_user": map[string]interface{}{
+				"name": "cloud-user",
+			},
+		},
+		"disable_root":    true,
+		"datasource_list": []string{"Ec2", "None"},
+	}, retCloudInit.GetConfig())
+
+	config, err := retCloudInit.Snippets[0].GetConfig()
+	require.NoError(t, err)
+	data, err := json.Marshal(config)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"ssh_pwauth":false,"ssh_authorized_keys":["ssh-ed25519 AAAA"],"phone_home":{"tries":3}}`, string(data))
+
+	assert.Nil(t, (&CloudInitCustomization{}).GetConfig())
+}
+
+func TestValidateCloudInit(t *testing.T) {
+	cases := []CloudInitCustomization{
+		{DefaultUser: "Cloud User"},
+		{Datasources: []string{"Ec2", "../None"}},
+		{Snippets: []CloudInitSnippetCustomization{{Filename: "99-ssh", Content: "ssh_pwauth: false"}}},
+		{Snippets: []CloudInitSnippetCustomization{{Filename: "../99-ssh.cfg", Content: "ssh_pwauth: false"}}},
+		{Snippets: []CloudInitSnippetCustomization{{Filename: CloudInitConfigFilename, Content: "ssh_pwauth: false"}}},
+		{Snippets: []CloudInitSnippetCustomization{
+			{Filename: "99-ssh.cfg", Content: "ssh_pwauth: false"},
+			{Filename: "99-ssh.cfg", Content: "disable_root: true"},
+		}},
+		{Snippets: []CloudInitSnippetCustomization{{Filename: "99-ssh.cfg", Content: "ssh_pwauth: [false"}}},
+		{Snippets: []CloudInitSnippetCustomization{{Filename: "99-ssh.cfg", Content: "- ssh_pwauth"}}},
+		{Snippets: []CloudInitSnippetCustomization{{Filename: "99-ssh.cfg", Content: ""}}},
+	}
+
+	for _, c := range cases {
+		TestCustomizations := Customizations{
+			CloudInit: &c,
+		}
+		assert.Errorf(t, TestCustomizations.Validate(), "Validate(%#v) accepted invalid customizations", c)
+	}
+}
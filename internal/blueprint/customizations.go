@@ -1,15 +1,45 @@
 package blueprint
 
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
 type Customizations struct {
-	Hostname *string                `json:"hostname,omitempty" toml:"hostname,omitempty"`
-	Kernel   *KernelCustomization   `json:"kernel,omitempty" toml:"kernel,omitempty"`
-	SSHKey   []SSHKeyCustomization  `json:"sshkey,omitempty" toml:"sshkey,omitempty"`
-	User     []UserCustomization    `json:"user,omitempty" toml:"user,omitempty"`
-	Group    []GroupCustomization   `json:"group,omitempty" toml:"group,omitempty"`
-	Timezone *TimezoneCustomization `json:"timezone,omitempty" toml:"timezone,omitempty"`
-	Locale   *LocaleCustomization   `json:"locale,omitempty" toml:"locale,omitempty"`
-	Firewall *FirewallCustomization `json:"firewall,omitempty" toml:"firewall,omitempty"`
-	Services *ServicesCustomization `json:"services,omitempty" toml:"services,omitempty"`
+	Hostname     *string                    `json:"hostname,omitempty" toml:"hostname,omitempty"`
+	Kernel       *KernelCustomization       `json:"kernel,omitempty" toml:"kernel,omitempty"`
+	SSHKey       []SSHKeyCustomization      `json:"sshkey,omitempty" toml:"sshkey,omitempty"`
+	User         []UserCustomization        `json:"user,omitempty" toml:"user,omitempty"`
+	Group        []GroupCustomization       `json:"group,omitempty" toml:"group,omitempty"`
+	Timezone     *TimezoneCustomization     `json:"timezone,omitempty" toml:"timezone,omitempty"`
+	Locale       *LocaleCustomization       `json:"locale,omitempty" toml:"locale,omitempty"`
+	Firewall     *FirewallCustomization     `json:"firewall,omitempty" toml:"firewall,omitempty"`
+	Services     *ServicesCustomization     `json:"services,omitempty" toml:"services,omitempty"`
+	FirstBoot    *FirstBootCustomization    `json:"firstboot,omitempty" toml:"firstboot,omitempty"`
+	SELinux      *SELinuxCustomization      `json:"selinux,omitempty" toml:"selinux,omitempty"`
+	Network      *NetworkCustomization      `json:"network,omitempty" toml:"network,omitempty"`
+	CACerts      *CACertsCustomization      `json:"cacerts,omitempty" toml:"cacerts,omitempty"`
+	Sysctl       map[string]string          `json:"sysctl,omitempty" toml:"sysctl,omitempty"`
+	Modules      *ModulesCustomization      `json:"modules,omitempty" toml:"modules,omitempty"`
+	Tuned        *TunedCustomization        `json:"tuned,omitempty" toml:"tuned,omitempty"`
+	CloudInit    *CloudInitCustomization    `json:"cloudinit,omitempty" toml:"cloudinit,omitempty"`
+	Sudo         []SudoCustomization        `json:"sudo,omitempty" toml:"sudo,omitempty"`
+	SSHD         *SSHDCustomization         `json:"sshd,omitempty" toml:"sshd,omitempty"`
+	Repositories []RepositoryCustomization  `json:"repositories,omitempty" toml:"repositories,omitempty"`
+	Subscription *SubscriptionCustomization `json:"subscription,omitempty" toml:"subscription,omitempty"`
+	Swap         *SwapCustomization         `json:"swap,omitempty" toml:"swap,omitempty"`
+	Filesystem   []FilesystemCustomization  `json:"filesystem,omitempty" toml:"filesystem,omitempty"`
+	Encryption   *EncryptionCustomization   `json:"encryption,omitempty" toml:"encryption,omitempty"`
+	Size         uint64                     `json:"size,omitempty" toml:"size,omitempty"`
+	Container    *ContainerCustomization    `json:"container,omitempty" toml:"container,omitempty"`
 }
 
 type KernelCustomization struct {
@@ -49,8 +79,10 @@ type LocaleCustomization struct {
 }
 
 type FirewallCustomization struct {
-	Ports    []string                       `json:"ports,omitempty" toml:"ports,omitempty"`
-	Services *FirewallServicesCustomization `json:"services,omitempty" toml:"services,omitempty"`
+	Ports       []string                       `json:"ports,omitempty" toml:"ports,omitempty"`
+	Services    *FirewallServicesCustomization `json:"services,omitempty" toml:"services,omitempty"`
+	DefaultZone string                         `json:"default_zone,omitempty" toml:"default_zone,omitempty"`
+	Zones       []FirewallZoneCustomization    `json:"zones,omitempty" toml:"zones,omitempty"`
 }
 
 type FirewallServicesCustomization struct {
@@ -58,11 +90,140 @@ type FirewallServicesCustomization struct {
 	Disabled []string `json:"disabled,omitempty" toml:"disabled,omitempty"`
 }
 
+// A FirewallZoneCustomization binds sources, given in CIDR notation, to a
+// zone and enables services in it.
+type FirewallZoneCustomization struct {
+	Name     string   `json:"name" toml:"name"`
+	Sources  []string `json:"sources,omitempty" toml:"sources,omitempty"`
+	Services []string `json:"services,omitempty" toml:"services,omitempty"`
+}
+
 type ServicesCustomization struct {
 	Enabled  []string `json:"enabled,omitempty" toml:"enabled,omitempty"`
 	Disabled []string `json:"disabled,omitempty" toml:"disabled,omitempty"`
 }
 
+type FirstBootCustomization struct {
+	Commands       []string `json:"commands,omitempty" toml:"commands,omitempty"`
+	WaitForNetwork bool     `json:"wait_for_network,omitempty" toml:"wait_for_network,omitempty"`
+}
+
+type SELinuxCustomization struct {
+	Mode     string   `json:"mode,omitempty" toml:"mode,omitempty"`
+	Booleans []string `json:"booleans,omitempty" toml:"booleans,omitempty"`
+}
+
+// A CACertsCustomization contains a bundle of PEM encoded certificates,
+// which are added to the system-wide trust store as anchors.
+type CACertsCustomization struct {
+	PEM string `json:"pem" toml:"pem"`
+}
+
+type ModulesCustomization struct {
+	Load      []string `json:"load,omitempty" toml:"load,omitempty"`
+	Blacklist []string `json:"blacklist,omitempty" toml:"blacklist,omitempty"`
+}
+
+type TunedCustomization struct {
+	Profile string `json:"profile" toml:"profile"`
+}
+
+// A SudoCustomization allows either a user or the members of a group to run
+// the commands as RunAs, which defaults to any user. Commands are absolute
+// paths, optionally followed by arguments, or "ALL".
+type SudoCustomization struct {
+	User       string   `json:"user,omitempty" toml:"user,omitempty"`
+	Group      string   `json:"group,omitempty" toml:"group,omitempty"`
+	RunAs      string   `json:"runas,omitempty" toml:"runas,omitempty"`
+	Commands   []string `json:"commands" toml:"commands"`
+	NoPassword bool     `json:"nopasswd,omitempty" toml:"nopasswd,omitempty"`
+}
+
+// SSHDCustomization is the subset of sshd_config(5) options which can be set
+// in a blueprint. Options which are not set keep the distribution's default.
+type SSHDCustomization struct {
+	PermitRootLogin                 *string  `json:"permit_root_login,omitempty" toml:"permit_root_login,omitempty"`
+	PasswordAuthentication          *bool    `json:"password_authentication,omitempty" toml:"password_authentication,omitempty"`
+	ChallengeResponseAuthentication *bool    `json:"challenge_response_authentication,omitempty" toml:"challenge_response_authentication,omitempty"`
+	Ciphers                         []string `json:"ciphers,omitempty" toml:"ciphers,omitempty"`
+	MACs                            []string `json:"macs,omitempty" toml:"macs,omitempty"`
+	KexAlgorithms                   []string `json:"kex_algorithms,omitempty" toml:"kex_algorithms,omitempty"`
+}
+
+// A SubscriptionCustomization registers the image with RHSM on first boot.
+// The activation key is a secret and is not returned by the API, see
+// Blueprint.Redacted().
+type SubscriptionCustomization struct {
+	Organization  int    `json:"organization" toml:"organization"`
+	ActivationKey string `json:"activation_key,omitempty" toml:"activation_key,omitempty"`
+	ServerURL     string `json:"server_url,omitempty" toml:"server_url,omitempty"`
+	BaseURL       string `json:"base_url,omitempty" toml:"base_url,omitempty"`
+	Insights      bool   `json:"insights,omitempty" toml:"insights,omitempty"`
+}
+
+// A SwapCustomization adds swap space of the given size in bytes to the
+// image, either as a partition or as a file in the root filesystem. The
+// image grows by the size of the swap space.
+type SwapCustomization struct {
+	Size uint64 `json:"size" toml:"size"`
+	Type string `json:"type,omitempty" toml:"type,omitempty"`
+}
+
+const (
+	SwapTypeFile      = "file"
+	SwapTypePartition = "partition"
+)
+
+// A FilesystemCustomization requests a separate filesystem of the given size
+// in bytes for a mountpoint. Disk images with filesystem customizations use
+// an LVM layout with one logical volume per mountpoint. The logical volume
+// of the root filesystem fills the space left by the others, its size is the
+// minimum.
+type FilesystemCustomization struct {
+	Mountpoint string `json:"mountpoint" toml:"mountpoint"`
+	Size       uint64 `json:"size" toml:"size"`
+}
+
+// RootLogicalVolumeName is the name of the logical volume holding the root
+// filesystem, which no other mountpoint may use.
+const RootLogicalVolumeName = "root"
+
+// GetLogicalVolumeName returns the name of the logical volume of the
+// filesystem, which is derived from its mountpoint: "/var/log" becomes
+// "var_log".
+func (fs *FilesystemCustomization) GetLogicalVolumeName() string {
+	if fs.Mountpoint == "/" {
+		return RootLogicalVolumeName
+	}
+	return strings.ReplaceAll(strings.TrimPrefix(fs.Mountpoint, "/"), "/", "_")
+}
+
+// An EncryptionCustomization encrypts the root partition of disk images with
+// LUKS2. Until first boot, the partition is unlocked automatically. On first
+// boot, it is bound to the tang servers and the TPM2 with clevis, of which
+// Threshold need to be available to unlock it. Threshold defaults to 1.
+type EncryptionCustomization struct {
+	Tang      []TangCustomization `json:"tang,omitempty" toml:"tang,omitempty"`
+	TPM2      bool                `json:"tpm2,omitempty" toml:"tpm2,omitempty"`
+	Threshold int                 `json:"threshold,omitempty" toml:"threshold,omitempty"`
+}
+
+// A TangCustomization is a tang server. If the thumbprint of its signing key
+// is not given, the key is trusted on first use.
+type TangCustomization struct {
+	URL        string `json:"url" toml:"url"`
+	Thumbprint string `json:"thumbprint,omitempty" toml:"thumbprint,omitempty"`
+}
+
+// A ContainerCustomization sets the config of container images. Env entries
+// have the form KEY=VALUE.
+type ContainerCustomization struct {
+	Entrypoint []string          `json:"entrypoint,omitempty" toml:"entrypoint,omitempty"`
+	Cmd        []string          `json:"cmd,omitempty" toml:"cmd,omitempty"`
+	Env        []string          `json:"env,omitempty" toml:"env,omitempty"`
+	Labels     map[string]string `json:"labels,omitempty" toml:"labels,omitempty"`
+}
+
 type CustomizationError struct {
 	Message string
 }
@@ -176,3 +337,450 @@ func (c *Customizations) GetServices() *ServicesCustomization {
 
 	return c.Services
 }
+
+func (c *Customizations) GetFirstBoot() *FirstBootCustomization {
+	if c == nil {
+		return nil
+	}
+
+	return c.FirstBoot
+}
+
+func (c *Customizations) GetSELinux() *SELinuxCustomization {
+	if c == nil {
+		return nil
+	}
+
+	return c.SELinux
+}
+
+func (c *Customizations) GetCACerts() *CACertsCustomization {
+	if c == nil {
+		return nil
+	}
+
+	return c.CACerts
+}
+
+func (c *Customizations) GetSysctl() map[string]string {
+	if c == nil {
+		return nil
+	}
+
+	return c.Sysctl
+}
+
+func (c *Customizations) GetModules() *ModulesCustomization {
+	if c == nil {
+		return nil
+	}
+
+	return c.Modules
+}
+
+func (c *Customizations) GetTuned() *TunedCustomization {
+	if c == nil {
+		return nil
+	}
+
+	return c.Tuned
+}
+
+func (c *Customizations) GetSudo() []SudoCustomization {
+	if c == nil {
+		return nil
+	}
+
+	return c.Sudo
+}
+
+func (c *Customizations) GetSSHD() *SSHDCustomization {
+	if c == nil {
+		return nil
+	}
+
+	return c.SSHD
+}
+
+func (c *Customizations) GetSubscription() *SubscriptionCustomization {
+	if c == nil {
+		return nil
+	}
+
+	return c.Subscription
+}
+
+func (c *Customizations) GetSwap() *SwapCustomization {
+	if c == nil {
+		return nil
+	}
+
+	return c.Swap
+}
+
+func (c *Customizations) GetFilesystems() []FilesystemCustomization {
+	if c == nil {
+		return nil
+	}
+
+	return c.Filesystem
+}
+
+func (c *Customizations) GetEncryption() *EncryptionCustomization {
+	if c == nil {
+		return nil
+	}
+
+	return c.Encryption
+}
+
+// GetSize returns the image size in bytes requested by the blueprint, or 0 if
+// the default size of the image type should be used
+func (c *Customizations) GetSize() uint64 {
+	if c == nil {
+		return 0
+	}
+
+	return c.Size
+}
+
+func (c *Customizations) GetContainer() *ContainerCustomization {
+	if c == nil {
+		return nil
+	}
+
+	return c.Container
+}
+
+// GetClevisPolicy returns the configuration of the clevis sss pin, which
+// combines the tang servers and the TPM2.
+func (e *EncryptionCustomization) GetClevisPolicy() string {
+	type tangPin struct {
+		URL        string `json:"url"`
+		Thumbprint string `json:"thp,omitempty"`
+	}
+	type pins struct {
+		Tang []tangPin `json:"tang,omitempty"`
+		TPM2 *struct{} `json:"tpm2,omitempty"`
+	}
+	policy := struct {
+		Threshold int  `json:"t"`
+		Pins      pins `json:"pins"`
+	}{
+		Threshold: e.Threshold,
+	}
+
+	if policy.Threshold == 0 {
+		policy.Threshold = 1
+	}
+	for _, tang := range e.Tang {
+		policy.Pins.Tang = append(policy.Pins.Tang, tangPin{tang.URL, tang.Thumbprint})
+	}
+	if e.TPM2 {
+		policy.Pins.TPM2 = &struct{}{}
+	}
+
+	data, err := json.Marshal(policy)
+	if err != nil {
+		panic(err)
+	}
+	return string(data)
+}
+
+// GetSize returns the size of the swap space rounded up to whole MiB, so
+// that a swap partition keeps the following partitions aligned and a swap
+// file is a multiple of the page size. The image grows by this size.
+func (s *SwapCustomization) GetSize() uint64 {
+	const MegaByte = 1024 * 1024
+	return (s.Size + MegaByte - 1) / MegaByte * MegaByte
+}
+
+// GetType returns the type of the swap space, which defaults to a file.
+func (s *SwapCustomization) GetType() string {
+	if s.Type == "" {
+		return SwapTypeFile
+	}
+	return s.Type
+}
+
+// Validate checks the customizations for values which would make the image
+// build fail, so that they can be rejected when the blueprint is saved.
+func (c *Customizations) Validate() error {
+	if c == nil {
+		return nil
+	}
+
+	if c.Firewall != nil {
+		if err := c.Firewall.validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.SELinux != nil {
+		if err := c.SELinux.validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.Network != nil {
+		if err := c.Network.validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.CACerts != nil {
+		if _, err := c.CACerts.GetCertificates(); err != nil {
+			return err
+		}
+	}
+
+	for key, value := range c.Sysctl {
+		if !validSysctlKey.MatchString(key) {
+			return &CustomizationError{fmt.Sprintf("Invalid sysctl key: %q", key)}
+		}
+		if strings.ContainsAny(value, "\n") {
+			return &CustomizationError{fmt.Sprintf("Invalid value for sysctl %s: %q", key, value)}
+		}
+	}
+
+	if c.Modules != nil {
+		for _, modules := range [][]string{c.Modules.Load, c.Modules.Blacklist} {
+			for _, module := range modules {
+				if !validModuleName.MatchString(module) {
+					return &CustomizationError{fmt.Sprintf("Invalid kernel module name: %q", module)}
+				}
+			}
+		}
+	}
+
+	if c.CloudInit != nil {
+		if err := c.CloudInit.validate(); err != nil {
+			return err
+		}
+	}
+
+	if c.Tuned != nil && !validTunedProfile.MatchString(c.Tuned.Profile) {
+		return &CustomizationError{fmt.Sprintf("Invalid tuned profile: %q", c.Tuned.Profile)}
+	}
+
+	if err := validateRepositories(c.Repositories); err != nil {
+		return err
+	}
+
+	if c.Subscription != nil {
+		if err := c.Subscription.validate(); err != nil {
+			return err
+		}
+	}
+
+	if err := validateFilesystems(c.Filesystem); err != nil {
+		return err
+	}
+
+	if c.Encryption != nil {
+		if err := c.Encryption.validate(); err != nil {
+			return err
+		}
+		if c.Swap != nil && c.Swap.GetType() == SwapTypePartition {
+			return &CustomizationError{"Swap partitions are not encrypted, use a swap file with an encrypted image"}
+		}
+	}
+
+	if c.Swap != nil {
+		if c.Swap.Size == 0 {
+			return &CustomizationError{"Swap size must be greater than zero"}
+		}
+		switch c.Swap.GetType() {
+		case SwapTypeFile, SwapTypePartition:
+		default:
+			return &CustomizationError{fmt.Sprintf("Invalid swap type: %s", c.Swap.Type)}
+		}
+	}
+
+	if c.Container != nil {
+		for _, env := range c.Container.Env {
+			if !strings.Contains(env, "=") || strings.HasPrefix(env, "=") {
+				return &CustomizationError{fmt.Sprintf("Invalid container environment variable: %q", env)}
+			}
+		}
+	}
+
+	return nil
+}
+
+var validSysctlKey = regexp.MustCompile(`^[a-zA-Z0-9_*-]+([./][a-zA-Z0-9_*-]+)*$`)
+var validModuleName = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+var validTunedProfile = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+var validFirewallZone = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+var validSELinuxBoolean = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+
+var validLogicalVolumeName = regexp.MustCompile(`^[a-zA-Z0-9+_.][a-zA-Z0-9+_.-]{0,126}$`)
+
+func validateFilesystems(filesystems []FilesystemCustomization) error {
+	mountpoints := map[string]bool{}
+	volumes := map[string]string{}
+	for _, fs := range filesystems {
+		if !path.IsAbs(fs.Mountpoint) || path.Clean(fs.Mountpoint) != fs.Mountpoint {
+			return &CustomizationError{fmt.Sprintf("Invalid mountpoint: %q", fs.Mountpoint)}
+		}
+		if fs.Mountpoint == "/boot" || strings.HasPrefix(fs.Mountpoint, "/boot/") {
+			return &CustomizationError{fmt.Sprintf("Mountpoint %s is managed by the image type", fs.Mountpoint)}
+		}
+		if mountpoints[fs.Mountpoint] {
+			return &CustomizationError{fmt.Sprintf("Duplicate mountpoint: %s", fs.Mountpoint)}
+		}
+		mountpoints[fs.Mountpoint] = true
+
+		if fs.Mountpoint != "/" {
+			name := fs.GetLogicalVolumeName()
+			if !validLogicalVolumeName.MatchString(name) {
+				return &CustomizationError{fmt.Sprintf("Mountpoint %s cannot be used as logical volume name", fs.Mountpoint)}
+			}
+			if name == RootLogicalVolumeName {
+				return &CustomizationError{fmt.Sprintf("Mountpoint %s clashes with the logical volume of the root filesystem", fs.Mountpoint)}
+			}
+			if other, ok := volumes[name]; ok {
+				return &CustomizationError{fmt.Sprintf("Mountpoints %s and %s use the same logical volume name %s", other, fs.Mountpoint, name)}
+			}
+			volumes[name] = fs.Mountpoint
+		}
+
+		if fs.Size == 0 {
+			return &CustomizationError{fmt.Sprintf("Filesystem %s needs a size", fs.Mountpoint)}
+		}
+	}
+
+	return nil
+}
+
+func (s *SubscriptionCustomization) validate() error {
+	if s.Organization <= 0 {
+		return &CustomizationError{fmt.Sprintf("Invalid subscription organization: %d", s.Organization)}
+	}
+	// The API never returns the activation key, so a blueprint, which was
+	// fetched and pushed again, lacks it and must not be accepted silently.
+	if s.ActivationKey == "" {
+		return &CustomizationError{"Subscription needs an activation key, which is not returned by the API and must be set again when a blueprint is pushed"}
+	}
+	if s.ServerURL == "" {
+		return &CustomizationError{"Subscription needs a server URL"}
+	}
+	if s.BaseURL == "" {
+		return &CustomizationError{"Subscription needs a base URL"}
+	}
+
+	return nil
+}
+
+func (e *EncryptionCustomization) validate() error {
+	pins := len(e.Tang)
+	if e.TPM2 {
+		pins++
+	}
+	if pins == 0 {
+		return &CustomizationError{"Encryption needs at least one tang server or the TPM2"}
+	}
+	if e.Threshold < 0 || e.Threshold > pins {
+		return &CustomizationError{fmt.Sprintf("Invalid encryption threshold: %d", e.Threshold)}
+	}
+
+	for _, tang := range e.Tang {
+		u, err := url.Parse(tang.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return &CustomizationError{fmt.Sprintf("Invalid tang server URL: %q", tang.URL)}
+		}
+	}
+
+	return nil
+}
+
+func (f *FirewallCustomization) validate() error {
+	if f.DefaultZone != "" && !validFirewallZone.MatchString(f.DefaultZone) {
+		return &CustomizationError{fmt.Sprintf("Invalid firewall default zone: %q", f.DefaultZone)}
+	}
+
+	zones := map[string]bool{}
+	for _, zone := range f.Zones {
+		if !validFirewallZone.MatchString(zone.Name) {
+			return &CustomizationError{fmt.Sprintf("Invalid firewall zone: %q", zone.Name)}
+		}
+		if zones[zone.Name] {
+			return &CustomizationError{fmt.Sprintf("Duplicate firewall zone: %s", zone.Name)}
+		}
+		zones[zone.Name] = true
+
+		for _, source := range zone.Sources {
+			if _, _, err := net.ParseCIDR(source); err != nil && net.ParseIP(source) == nil {
+				return &CustomizationError{fmt.Sprintf("Invalid source for firewall zone %s: %s", zone.Name, source)}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *SELinuxCustomization) validate() error {
+	switch s.Mode {
+	case "", "enforcing", "permissive":
+	default:
+		return &CustomizationError{fmt.Sprintf("Invalid SELinux mode: %s", s.Mode)}
+	}
+
+	_, err := s.GetBooleans()
+	return err
+}
+
+// GetBooleans parses the booleans, which are given in the same "name=value"
+// form setsebool accepts.
+func (s *SELinuxCustomization) GetBooleans() (map[string]bool, error) {
+	booleans := map[string]bool{}
+	for _, b := range s.Booleans {
+		parts := strings.SplitN(b, "=", 2)
+		if len(parts) != 2 || !validSELinuxBoolean.MatchString(parts[0]) {
+			return nil, &CustomizationError{fmt.Sprintf("Invalid SELinux boolean: %s", b)}
+		}
+
+		switch strings.ToLower(parts[1]) {
+		case "1", "on", "true":
+			booleans[parts[0]] = true
+		case "0", "off", "false":
+			booleans[parts[0]] = false
+		default:
+			return nil, &CustomizationError{fmt.Sprintf("Invalid value for SELinux boolean %s: %s", parts[0], parts[1])}
+		}
+	}
+
+	return booleans, nil
+}
+
+// GetCertificates splits the bundle into its certificates, each of them PEM
+// encoded. It fails if the bundle contains anything but valid certificates.
+func (c *CACertsCustomization) GetCertificates() ([]string, error) {
+	var certs []string
+	rest := []byte(c.PEM)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			return nil, &CustomizationError{fmt.Sprintf("Invalid CA certificate bundle: unexpected PEM block %q", block.Type)}
+		}
+		if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+			return nil, &CustomizationError{fmt.Sprintf("Invalid CA certificate: %v", err)}
+		}
+		certs = append(certs, string(pem.EncodeToMemory(block)))
+	}
+
+	if len(bytes.TrimSpace(rest)) > 0 {
+		return nil, &CustomizationError{"Invalid CA certificate bundle: trailing data after the last certificate"}
+	}
+	if len(certs) == 0 {
+		return nil, &CustomizationError{"Invalid CA certificate bundle: no certificates found"}
+	}
+
+	return certs, nil
+}
@@ -1,8 +1,11 @@
 package blueprint
 
 import (
-	"github.com/stretchr/testify/assert"
+	"io/ioutil"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetHostname(t *testing.T) {
@@ -178,6 +181,35 @@ func TestGetFirewall(t *testing.T) {
 	assert.ElementsMatch(t, expectedFirewall.Services.Disabled, retFirewall.Services.Disabled)
 }
 
+func TestValidateFirewall(t *testing.T) {
+	cases := []struct {
+		Firewall      FirewallCustomization
+		ExpectedError bool
+	}{
+		{FirewallCustomization{Ports: []string{"22:tcp"}}, false},
+		{FirewallCustomization{
+			DefaultZone: "dmz",
+			Zones: []FirewallZoneCustomization{
+				{Name: "trusted", Sources: []string{"10.0.0.0/8", "fd00::/8", "192.168.1.1"}},
+				{Name: "internal", Services: []string{"ssh", "cockpit"}},
+			},
+		}, false},
+		{FirewallCustomization{DefaultZone: "my zone"}, true},
+		{FirewallCustomization{Zones: []FirewallZoneCustomization{{Name: ""}}}, true},
+		{FirewallCustomization{Zones: []FirewallZoneCustomization{{Name: "trusted"}, {Name: "trusted"}}}, true},
+		{FirewallCustomization{Zones: []FirewallZoneCustomization{{Name: "trusted", Sources: []string{"10.0.0.0/33"}}}}, true},
+		{FirewallCustomization{Zones: []FirewallZoneCustomization{{Name: "trusted", Sources: []string{"10.0.0/8"}}}}, true},
+	}
+
+	for _, c := range cases {
+		TestCustomizations := Customizations{
+			Firewall: &c.Firewall,
+		}
+		err := TestCustomizations.Validate()
+		assert.Equalf(t, c.ExpectedError, err != nil, "Validate(%#v) returned an unexpected error: %#v", c.Firewall, err)
+	}
+}
+
 func TestGetServices(t *testing.T) {
 
 	expectedServices := ServicesCustomization{
@@ -195,6 +227,298 @@ func TestGetServices(t *testing.T) {
 	assert.ElementsMatch(t, expectedServices.Disabled, retServices.Disabled)
 }
 
+func TestGetFirstBoot(t *testing.T) {
+
+	expectedFirstBoot := FirstBootCustomization{
+		Commands:       []string{"/usr/bin/touch /etc/first-boot-done"},
+		WaitForNetwork: true,
+	}
+
+	TestCustomizations := Customizations{
+		FirstBoot: &expectedFirstBoot,
+	}
+
+	retFirstBoot := TestCustomizations.GetFirstBoot()
+
+	assert.Equal(t, &expectedFirstBoot, retFirstBoot)
+}
+
+func TestGetSELinux(t *testing.T) {
+
+	expectedSELinux := SELinuxCustomization{
+		Mode:     "permissive",
+		Booleans: []string{"httpd_can_network_connect=on", "virt_use_nfs=0"},
+	}
+
+	TestCustomizations := Customizations{
+		SELinux: &expectedSELinux,
+	}
+
+	retSELinux := TestCustomizations.GetSELinux()
+	assert.Equal(t, &expectedSELinux, retSELinux)
+
+	retBooleans, err := retSELinux.GetBooleans()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"httpd_can_network_connect": true, "virt_use_nfs": false}, retBooleans)
+}
+
+func TestValidateSELinux(t *testing.T) {
+	cases := []struct {
+		SELinux       SELinuxCustomization
+		ExpectedError bool
+	}{
+		{SELinuxCustomization{}, false},
+		{SELinuxCustomization{Mode: "enforcing"}, false},
+		{SELinuxCustomization{Mode: "permissive", Booleans: []string{"httpd_can_network_connect=true"}}, false},
+		{SELinuxCustomization{Mode: "disabled"}, true},
+		{SELinuxCustomization{Mode: "Enforcing"}, true},
+		{SELinuxCustomization{Booleans: []string{"httpd_can_network_connect"}}, true},
+		{SELinuxCustomization{Booleans: []string{"httpd_can_network_connect=maybe"}}, true},
+		{SELinuxCustomization{Booleans: []string{"httpd can=on"}}, true},
+	}
+
+	for _, c := range cases {
+		TestCustomizations := Customizations{
+			SELinux: &c.SELinux,
+		}
+		err := TestCustomizations.Validate()
+		assert.Equalf(t, c.ExpectedError, err != nil, "Validate(%#v) returned an unexpected error: %#v", c.SELinux, err)
+	}
+}
+
+func TestGetCACerts(t *testing.T) {
+
+	caCert, err := ioutil.ReadFile("../../test/data/ca/ca-crt.pem")
+	require.NoError(t, err)
+	workerCert, err := ioutil.ReadFile("../../test/data/ca/worker-crt.pem")
+	require.NoError(t, err)
+
+	expectedCACerts := CACertsCustomization{
+		PEM: string(caCert) + "\n" + string(workerCert),
+	}
+
+	TestCustomizations := Customizations{
+		CACerts: &expectedCACerts,
+	}
+
+	retCACerts := TestCustomizations.GetCACerts()
+	assert.Equal(t, &expectedCACerts, retCACerts)
+
+	retCertificates, err := retCACerts.GetCertificates()
+	require.NoError(t, err)
+	assert.Equal(t, []string{string(caCert), string(workerCert)}, retCertificates)
+	assert.NoError(t, TestCustomizations.Validate())
+}
+
+func TestValidateCACerts(t *testing.T) {
+
+	caCert, err := ioutil.ReadFile("../../test/data/ca/ca-crt.pem")
+	require.NoError(t, err)
+	caKey, err := ioutil.ReadFile("../../test/data/ca/ca-key.pem")
+	require.NoError(t, err)
+
+	cases := []string{
+		"",
+		"not a certificate",
+		string(caCert) + "trailing garbage",
+		string(caCert) + string(caKey),
+		"-----BEGIN CERTIFICATE-----\nMIIBkTCB+wIJAKHBfpegPjMCMA0GCSqGSIb3DQEBBQUAMA0xCzAJBgNVBAYTAlVT\n-----END CERTIFICATE-----\n",
+	}
+
+	for _, c := range cases {
+		TestCustomizations := Customizations{
+			CACerts: &CACertsCustomization{PEM: c},
+		}
+		assert.Errorf(t, TestCustomizations.Validate(), "Validate() accepted an invalid bundle: %q", c)
+	}
+}
+
+func TestGetKernelTuning(t *testing.T) {
+
+	expectedSysctl := map[string]string{
+		"net.ipv4.ip_forward": "1",
+		"vm.swappiness":       "10",
+	}
+	expectedModules := ModulesCustomization{
+		Load:      []string{"br_netfilter"},
+		Blacklist: []string{"nouveau", "floppy"},
+	}
+	expectedTuned := TunedCustomization{
+		Profile: "virtual-guest",
+	}
+
+	TestCustomizations := Customizations{
+		Sysctl:  expectedSysctl,
+		Modules: &expectedModules,
+		Tuned:   &expectedTuned,
+	}
+
+	assert.Equal(t, expectedSysctl, TestCustomizations.GetSysctl())
+	assert.Equal(t, &expectedModules, TestCustomizations.GetModules())
+	assert.Equal(t, &expectedTuned, TestCustomizations.GetTuned())
+	assert.NoError(t, TestCustomizations.Validate())
+}
+
+func TestValidateKernelTuning(t *testing.T) {
+	cases := []Customizations{
+		{Sysctl: map[string]string{"net.ipv4.ip_forward = 1": "1"}},
+		{Sysctl: map[string]string{"": "1"}},
+		{Sysctl: map[string]string{"net..ipv4": "1"}},
+		{Sysctl: map[string]string{"vm.swappiness": "10\nkernel.panic = 1"}},
+		{Modules: &ModulesCustomization{Load: []string{"br_netfilter conf"}}},
+		{Modules: &ModulesCustomization{Blacklist: []string{"../nouveau"}}},
+		{Tuned: &TunedCustomization{}},
+		{Tuned: &TunedCustomization{Profile: "virtual guest"}},
+	}
+
+	for _, c := range cases {
+		assert.Errorf(t, c.Validate(), "Validate(%#v) accepted invalid customizations", c)
+	}
+}
+
+func TestGetSwap(t *testing.T) {
+
+	expectedSwap := SwapCustomization{
+		Size: 2 * 1024 * 1024 * 1024,
+	}
+
+	TestCustomizations := Customizations{
+		Swap: &expectedSwap,
+	}
+
+	retSwap := TestCustomizations.GetSwap()
+	assert.Equal(t, &expectedSwap, retSwap)
+	assert.Equal(t, SwapTypeFile, retSwap.GetType())
+	assert.Equal(t, uint64(2*1024*1024*1024), retSwap.GetSize())
+	assert.NoError(t, TestCustomizations.Validate())
+
+	// the size is rounded up to whole MiB
+	assert.Equal(t, uint64(2*1024*1024), (&SwapCustomization{Size: 1024*1024 + 1}).GetSize())
+
+	invalid := []SwapCustomization{
+		{Size: 0, Type: SwapTypePartition},
+		{Size: 1024 * 1024, Type: "zram"},
+	}
+	for _, swap := range invalid {
+		c := Customizations{Swap: &swap}
+		assert.Errorf(t, c.Validate(), "Validate(%#v) accepted invalid swap customization", swap)
+	}
+}
+
+func TestGetFilesystems(t *testing.T) {
+
+	expectedFilesystems := []FilesystemCustomization{
+		{Mountpoint: "/", Size: 4 * 1024 * 1024 * 1024},
+		{Mountpoint: "/var", Size: 2 * 1024 * 1024 * 1024},
+	}
+
+	TestCustomizations := Customizations{
+		Filesystem: expectedFilesystems,
+	}
+
+	assert.Equal(t, expectedFilesystems, TestCustomizations.GetFilesystems())
+	assert.NoError(t, TestCustomizations.Validate())
+	assert.Equal(t, "root", expectedFilesystems[0].GetLogicalVolumeName())
+	assert.Equal(t, "var_log", (&FilesystemCustomization{Mountpoint: "/var/log"}).GetLogicalVolumeName())
+
+	invalid := [][]FilesystemCustomization{
+		{{Mountpoint: "var", Size: 1024}},
+		{{Mountpoint: "/var/../etc", Size: 1024}},
+		{{Mountpoint: "/boot", Size: 1024}},
+		{{Mountpoint: "/var", Size: 1024}, {Mountpoint: "/var", Size: 2048}},
+		{{Mountpoint: "/home"}},
+		{{Mountpoint: "/var/log", Size: 1024}, {Mountpoint: "/var_log", Size: 1024}},
+		{{Mountpoint: "/root", Size: 1024}},
+		{{Mountpoint: "/srv/data@2", Size: 1024}},
+		{{Mountpoint: "/-srv", Size: 1024}},
+	}
+	for _, filesystems := range invalid {
+		c := Customizations{Filesystem: filesystems}
+		assert.Errorf(t, c.Validate(), "Validate(%#v) accepted invalid filesystem customizations", filesystems)
+	}
+}
+
+func TestGetEncryption(t *testing.T) {
+
+	expectedEncryption := EncryptionCustomization{
+		Tang: []TangCustomization{
+			{URL: "http://tang.example.com", Thumbprint: "x8CbJ0uQsPrOl8tUY0MEeQOpxjU"},
+		},
+		TPM2:      true,
+		Threshold: 2,
+	}
+
+	TestCustomizations := Customizations{
+		Encryption: &expectedEncryption,
+	}
+
+	retEncryption := TestCustomizations.GetEncryption()
+	assert.Equal(t, &expectedEncryption, retEncryption)
+	assert.NoError(t, TestCustomizations.Validate())
+	assert.Equal(t, `{"t":2,"pins":{"tang":[{"url":"http://tang.example.com","thp":"x8CbJ0uQsPrOl8tUY0MEeQOpxjU"}],"tpm2":{}}}`, retEncryption.GetClevisPolicy())
+	assert.Equal(t, `{"t":1,"pins":{"tpm2":{}}}`, (&EncryptionCustomization{TPM2: true}).GetClevisPolicy())
+
+	invalid := []Customizations{
+		{Encryption: &EncryptionCustomization{}},
+		{Encryption: &EncryptionCustomization{TPM2: true, Threshold: 2}},
+		{Encryption: &EncryptionCustomization{Tang: []TangCustomization{{URL: "tang.example.com"}}}},
+		{Encryption: &EncryptionCustomization{TPM2: true}, Swap: &SwapCustomization{Size: 1024 * 1024, Type: SwapTypePartition}},
+	}
+	for _, c := range invalid {
+		assert.Errorf(t, c.Validate(), "Validate(%#v) accepted invalid encryption customization", c)
+	}
+}
+
+func TestGetContainer(t *testing.T) {
+
+	expectedContainer := ContainerCustomization{
+		Entrypoint: []string{"/usr/bin/httpd"},
+		Cmd:        []string{"-DFOREGROUND"},
+		Env:        []string{"LANG=C.UTF-8", "EMPTY="},
+		Labels:     map[string]string{"vendor": "Example"},
+	}
+
+	TestCustomizations := Customizations{
+		Container: &expectedContainer,
+	}
+
+	assert.Equal(t, &expectedContainer, TestCustomizations.GetContainer())
+	assert.NoError(t, TestCustomizations.Validate())
+
+	for _, env := range []string{"LANG", "=C.UTF-8"} {
+		c := Customizations{Container: &ContainerCustomization{Env: []string{env}}}
+		assert.Errorf(t, c.Validate(), "Validate(%#v) accepted invalid container customization", c)
+	}
+}
+
+func TestGetSudoAndSSHD(t *testing.T) {
+
+	no := "no"
+	disabled := false
+
+	expectedSudo := []SudoCustomization{
+		{
+			User:       "deploy",
+			Commands:   []string{"/usr/bin/systemctl restart httpd"},
+			NoPassword: true,
+		},
+	}
+
+	expectedSSHD := SSHDCustomization{
+		PermitRootLogin:        &no,
+		PasswordAuthentication: &disabled,
+		Ciphers:                []string{"aes256-gcm@openssh.com"},
+	}
+
+	TestCustomizations := Customizations{
+		Sudo: expectedSudo,
+		SSHD: &expectedSSHD,
+	}
+
+	assert.Equal(t, expectedSudo, TestCustomizations.GetSudo())
+	assert.Equal(t, &expectedSSHD, TestCustomizations.GetSSHD())
+}
+
 func TestError(t *testing.T) {
 	expectedError := CustomizationError{
 		Message: "test error",
@@ -217,6 +541,22 @@ func TestNoCustomizationsInBlueprint(t *testing.T) {
 	assert.Nil(t, TestBP.Customizations.GetKernel())
 	assert.Nil(t, TestBP.Customizations.GetFirewall())
 	assert.Nil(t, TestBP.Customizations.GetServices())
+	assert.Nil(t, TestBP.Customizations.GetFirstBoot())
+	assert.Nil(t, TestBP.Customizations.GetSELinux())
+	assert.Nil(t, TestBP.Customizations.GetCACerts())
+	assert.Nil(t, TestBP.Customizations.GetSysctl())
+	assert.Nil(t, TestBP.Customizations.GetModules())
+	assert.Nil(t, TestBP.Customizations.GetTuned())
+	assert.Nil(t, TestBP.Customizations.GetSudo())
+	assert.Nil(t, TestBP.Customizations.GetSSHD())
+	assert.Nil(t, TestBP.Customizations.GetRepositories())
+	assert.Nil(t, TestBP.Customizations.GetSubscription())
+	assert.Nil(t, TestBP.Customizations.GetSwap())
+	assert.Nil(t, TestBP.Customizations.GetFilesystems())
+	assert.Nil(t, TestBP.Customizations.GetEncryption())
+	assert.Zero(t, TestBP.Customizations.GetSize())
+	assert.Nil(t, TestBP.Customizations.GetContainer())
+	assert.NoError(t, TestBP.Customizations.Validate())
 
 	nilLanguage, nilKeyboard := TestBP.Customizations.GetPrimaryLocale()
 	assert.Nil(t, nilLanguage)
@@ -0,0 +1,207 @@
+package blueprint
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+)
+
+type NetworkCustomization struct {
+	Connections []NetworkConnectionCustomization `json:"connections,omitempty" toml:"connections,omitempty"`
+}
+
+// A NetworkConnectionCustomization describes one NetworkManager connection
+// profile. Physical interfaces are matched either by their name or by their
+// MAC address, bonds and VLANs need the name of the interface to create.
+type NetworkConnectionCustomization struct {
+	Name      string                    `json:"name" toml:"name"`
+	Type      string                    `json:"type,omitempty" toml:"type,omitempty"`
+	Interface string                    `json:"interface,omitempty" toml:"interface,omitempty"`
+	MAC       string                    `json:"mac,omitempty" toml:"mac,omitempty"`
+	Master    string                    `json:"master,omitempty" toml:"master,omitempty"`
+	IPv4      *NetworkIPCustomization   `json:"ipv4,omitempty" toml:"ipv4,omitempty"`
+	IPv6      *NetworkIPCustomization   `json:"ipv6,omitempty" toml:"ipv6,omitempty"`
+	Bond      *NetworkBondCustomization `json:"bond,omitempty" toml:"bond,omitempty"`
+	VLAN      *NetworkVLANCustomization `json:"vlan,omitempty" toml:"vlan,omitempty"`
+}
+
+// A NetworkIPCustomization configures one address family of a connection.
+// Method defaults to "manual" if addresses are given and to "auto" otherwise.
+// Addresses are given in CIDR notation.
+type NetworkIPCustomization struct {
+	Method    string   `json:"method,omitempty" toml:"method,omitempty"`
+	Addresses []string `json:"addresses,omitempty" toml:"addresses,omitempty"`
+	Gateway   string   `json:"gateway,omitempty" toml:"gateway,omitempty"`
+	DNS       []string `json:"dns,omitempty" toml:"dns,omitempty"`
+}
+
+type NetworkBondCustomization struct {
+	Mode string `json:"mode,omitempty" toml:"mode,omitempty"`
+}
+
+type NetworkVLANCustomization struct {
+	ID     int    `json:"id" toml:"id"`
+	Parent string `json:"parent" toml:"parent"`
+}
+
+func (c *Customizations) GetNetwork() *NetworkCustomization {
+	if c == nil {
+		return nil
+	}
+
+	return c.Network
+}
+
+// GetType returns the type of the connection, which defaults to "ethernet".
+func (c *NetworkConnectionCustomization) GetType() string {
+	if c.Type == "" {
+		return "ethernet"
+	}
+	return c.Type
+}
+
+// GetMethod returns the configuration method of the address family.
+func (c *NetworkIPCustomization) GetMethod() string {
+	if c.Method != "" {
+		return c.Method
+	}
+	if len(c.Addresses) > 0 {
+		return "manual"
+	}
+	return "auto"
+}
+
+var validConnectionName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
+var validBondModes = map[string]bool{
+	"balance-rr":    true,
+	"active-backup": true,
+	"balance-xor":   true,
+	"broadcast":     true,
+	"802.3ad":       true,
+	"balance-tlb":   true,
+	"balance-alb":   true,
+}
+
+func (n *NetworkCustomization) validate() error {
+	names := map[string]bool{}
+	bonds := map[string]bool{}
+	for _, conn := range n.Connections {
+		if !validConnectionName.MatchString(conn.Name) {
+			return &CustomizationError{fmt.Sprintf("Invalid network connection name: %q", conn.Name)}
+		}
+		if names[conn.Name] {
+			return &CustomizationError{fmt.Sprintf("Duplicate network connection: %s", conn.Name)}
+		}
+		names[conn.Name] = true
+		if conn.GetType() == "bond" {
+			bonds[conn.Interface] = true
+		}
+	}
+
+	for _, conn := range n.Connections {
+		if err := conn.validate(bonds); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *NetworkConnectionCustomization) validate(bonds map[string]bool) error {
+	switch c.GetType() {
+	case "ethernet":
+		if c.Interface == "" && c.MAC == "" {
+			return &CustomizationError{fmt.Sprintf("Network connection %s must match an interface or a MAC address", c.Name)}
+		}
+	case "bond":
+		if c.Interface == "" {
+			return &CustomizationError{fmt.Sprintf("Bond %s needs an interface name", c.Name)}
+		}
+		if c.Bond != nil && c.Bond.Mode != "" && !validBondModes[c.Bond.Mode] {
+			return &CustomizationError{fmt.Sprintf("Invalid mode for bond %s: %s", c.Name, c.Bond.Mode)}
+		}
+	case "vlan":
+		if c.Interface == "" {
+			return &CustomizationError{fmt.Sprintf("VLAN %s needs an interface name", c.Name)}
+		}
+		if c.VLAN == nil || c.VLAN.Parent == "" {
+			return &CustomizationError{fmt.Sprintf("VLAN %s needs a parent interface", c.Name)}
+		}
+		if c.VLAN.ID < 1 || c.VLAN.ID > 4094 {
+			return &CustomizationError{fmt.Sprintf("Invalid ID for VLAN %s: %d", c.Name, c.VLAN.ID)}
+		}
+	default:
+		return &CustomizationError{fmt.Sprintf("Invalid type for network connection %s: %s", c.Name, c.Type)}
+	}
+
+	if c.MAC != "" {
+		if _, err := net.ParseMAC(c.MAC); err != nil {
+			return &CustomizationError{fmt.Sprintf("Invalid MAC address for network connection %s: %s", c.Name, c.MAC)}
+		}
+	}
+
+	if c.Bond != nil && c.GetType() != "bond" {
+		return &CustomizationError{fmt.Sprintf("Network connection %s has bond settings, but is not a bond", c.Name)}
+	}
+	if c.VLAN != nil && c.GetType() != "vlan" {
+		return &CustomizationError{fmt.Sprintf("Network connection %s has VLAN settings, but is not a VLAN", c.Name)}
+	}
+
+	if c.Master != "" {
+		if !bonds[c.Master] {
+			return &CustomizationError{fmt.Sprintf("Network connection %s is a port of an unknown bond: %s", c.Name, c.Master)}
+		}
+		if c.IPv4 != nil || c.IPv6 != nil {
+			return &CustomizationError{fmt.Sprintf("Network connection %s is a bond port and cannot have IP settings", c.Name)}
+		}
+	}
+
+	if c.IPv4 != nil {
+		if err := c.IPv4.validate(c.Name, "IPv4", false); err != nil {
+			return err
+		}
+	}
+	if c.IPv6 != nil {
+		if err := c.IPv6.validate(c.Name, "IPv6", true); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *NetworkIPCustomization) validate(name, family string, ipv6 bool) error {
+	switch c.GetMethod() {
+	case "auto", "manual", "disabled":
+	default:
+		return &CustomizationError{fmt.Sprintf("Invalid %s method for network connection %s: %s", family, name, c.Method)}
+	}
+
+	if c.GetMethod() == "manual" && len(c.Addresses) == 0 {
+		return &CustomizationError{fmt.Sprintf("Network connection %s needs %s addresses for the manual method", name, family)}
+	}
+	if c.GetMethod() == "disabled" && (len(c.Addresses) > 0 || c.Gateway != "" || len(c.DNS) > 0) {
+		return &CustomizationError{fmt.Sprintf("Network connection %s has %s disabled, but sets addresses", name, family)}
+	}
+
+	for _, address := range c.Addresses {
+		ip, _, err := net.ParseCIDR(address)
+		if err != nil || (ip.To4() == nil) != ipv6 {
+			return &CustomizationError{fmt.Sprintf("Invalid %s address for network connection %s: %s", family, name, address)}
+		}
+	}
+
+	ips := c.DNS
+	if c.Gateway != "" {
+		ips = append([]string{c.Gateway}, ips...)
+	}
+	for _, address := range ips {
+		ip := net.ParseIP(address)
+		if ip == nil || (ip.To4() == nil) != ipv6 {
+			return &CustomizationError{fmt.Sprintf("Invalid %s address for network connection %s: %s", family, name, address)}
+		}
+	}
+
+	return nil
+}
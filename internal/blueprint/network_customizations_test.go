@@ -0,0 +1,142 @@
+package blueprint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetNetwork(t *testing.T) {
+	expectedNetwork := NetworkCustomization{
+		Connections: []NetworkConnectionCustomization{
+			{
+				Name:      "eth0",
+				Interface: "eth0",
+				IPv4: &NetworkIPCustomization{
+					Addresses: []string{"192.168.1.10/24"},
+					Gateway:   "192.168.1.1",
+					DNS:       []string{"192.168.1.1"},
+				},
+			},
+		},
+	}
+
+	TestCustomizations := Customizations{
+		Network: &expectedNetwork,
+	}
+
+	retNetwork := TestCustomizations.GetNetwork()
+	assert.Equal(t, &expectedNetwork, retNetwork)
+	assert.Equal(t, "ethernet", retNetwork.Connections[0].GetType())
+	assert.Equal(t, "manual", retNetwork.Connections[0].IPv4.GetMethod())
+	assert.Equal(t, "auto", (&NetworkIPCustomization{}).GetMethod())
+}
+
+func TestValidateNetwork(t *testing.T) {
+	cases := []struct {
+		Name          string
+		Connections   []NetworkConnectionCustomization
+		ExpectedError bool
+	}{
+		{
+			Name: "static ipv4 and ipv6",
+			Connections: []NetworkConnectionCustomization{{
+				Name:      "eth0",
+				Interface: "eth0",
+				IPv4:      &NetworkIPCustomization{Addresses: []string{"10.0.0.2/8"}, Gateway: "10.0.0.1", DNS: []string{"10.0.0.1"}},
+				IPv6:      &NetworkIPCustomization{Addresses: []string{"fd00::2/64"}, Gateway: "fd00::1"},
+			}},
+		},
+		{
+			Name: "bond with ports and vlan",
+			Connections: []NetworkConnectionCustomization{
+				{Name: "bond0", Type: "bond", Interface: "bond0", Bond: &NetworkBondCustomization{Mode: "802.3ad"}},
+				{Name: "bond0-port1", MAC: "52:54:00:12:34:56", Master: "bond0"},
+				{Name: "bond0-port2", Interface: "ens4", Master: "bond0"},
+				{Name: "vlan42", Type: "vlan", Interface: "bond0.42", VLAN: &NetworkVLANCustomization{ID: 42, Parent: "bond0"},
+					IPv4: &NetworkIPCustomization{Addresses: []string{"172.16.0.2/16"}}},
+			},
+		},
+		{
+			Name:          "invalid name",
+			Connections:   []NetworkConnectionCustomization{{Name: "../eth0", Interface: "eth0"}},
+			ExpectedError: true,
+		},
+		{
+			Name: "duplicate name",
+			Connections: []NetworkConnectionCustomization{
+				{Name: "eth0", Interface: "eth0"},
+				{Name: "eth0", Interface: "eth1"},
+			},
+			ExpectedError: true,
+		},
+		{
+			Name:          "unmatched ethernet",
+			Connections:   []NetworkConnectionCustomization{{Name: "eth0"}},
+			ExpectedError: true,
+		},
+		{
+			Name:          "invalid type",
+			Connections:   []NetworkConnectionCustomization{{Name: "wl0", Type: "wifi", Interface: "wl0"}},
+			ExpectedError: true,
+		},
+		{
+			Name:          "invalid mac",
+			Connections:   []NetworkConnectionCustomization{{Name: "eth0", MAC: "52:54:00"}},
+			ExpectedError: true,
+		},
+		{
+			Name:          "address without prefix",
+			Connections:   []NetworkConnectionCustomization{{Name: "eth0", Interface: "eth0", IPv4: &NetworkIPCustomization{Addresses: []string{"10.0.0.2"}}}},
+			ExpectedError: true,
+		},
+		{
+			Name:          "ipv6 address for ipv4",
+			Connections:   []NetworkConnectionCustomization{{Name: "eth0", Interface: "eth0", IPv4: &NetworkIPCustomization{Addresses: []string{"fd00::2/64"}}}},
+			ExpectedError: true,
+		},
+		{
+			Name:          "invalid gateway",
+			Connections:   []NetworkConnectionCustomization{{Name: "eth0", Interface: "eth0", IPv4: &NetworkIPCustomization{Addresses: []string{"10.0.0.2/8"}, Gateway: "10.0.0"}}},
+			ExpectedError: true,
+		},
+		{
+			Name:          "invalid dns",
+			Connections:   []NetworkConnectionCustomization{{Name: "eth0", Interface: "eth0", IPv6: &NetworkIPCustomization{DNS: []string{"10.0.0.1"}}}},
+			ExpectedError: true,
+		},
+		{
+			Name:          "manual without addresses",
+			Connections:   []NetworkConnectionCustomization{{Name: "eth0", Interface: "eth0", IPv4: &NetworkIPCustomization{Method: "manual"}}},
+			ExpectedError: true,
+		},
+		{
+			Name:          "invalid bond mode",
+			Connections:   []NetworkConnectionCustomization{{Name: "bond0", Type: "bond", Interface: "bond0", Bond: &NetworkBondCustomization{Mode: "fast"}}},
+			ExpectedError: true,
+		},
+		{
+			Name:          "port of unknown bond",
+			Connections:   []NetworkConnectionCustomization{{Name: "eth0", Interface: "eth0", Master: "bond0"}},
+			ExpectedError: true,
+		},
+		{
+			Name:          "vlan without parent",
+			Connections:   []NetworkConnectionCustomization{{Name: "vlan42", Type: "vlan", Interface: "eth0.42", VLAN: &NetworkVLANCustomization{ID: 42}}},
+			ExpectedError: true,
+		},
+		{
+			Name:          "vlan with invalid id",
+			Connections:   []NetworkConnectionCustomization{{Name: "vlan0", Type: "vlan", Interface: "eth0.0", VLAN: &NetworkVLANCustomization{Parent: "eth0"}}},
+			ExpectedError: true,
+		},
+	}
+
+	for _, c := range cases {
+		TestCustomizations := Customizations{
+			Network: &NetworkCustomization{Connections: c.Connections},
+		}
+		err := TestCustomizations.Validate()
+		assert.Equalf(t, c.ExpectedError, err != nil, "%s: Validate() returned an unexpected error: %v", c.Name, err)
+	}
+}
@@ -0,0 +1,110 @@
+package blueprint
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// A RepositoryCustomization describes a repository which is configured in
+// /etc/yum.repos.d of the image. It is not used to build the image, the
+// repositories used for that are configured by the sources of the composer.
+//
+// GPGKeys are either URLs or ASCII armored public keys. Armored keys are
+// written into the image and imported into its rpm database.
+type RepositoryCustomization struct {
+	ID           string   `json:"id" toml:"id"`
+	Name         string   `json:"name,omitempty" toml:"name,omitempty"`
+	Filename     string   `json:"filename,omitempty" toml:"filename,omitempty"`
+	BaseURLs     []string `json:"baseurls,omitempty" toml:"baseurls,omitempty"`
+	Metalink     string   `json:"metalink,omitempty" toml:"metalink,omitempty"`
+	Mirrorlist   string   `json:"mirrorlist,omitempty" toml:"mirrorlist,omitempty"`
+	Enabled      *bool    `json:"enabled,omitempty" toml:"enabled,omitempty"`
+	GPGCheck     *bool    `json:"gpgcheck,omitempty" toml:"gpgcheck,omitempty"`
+	RepoGPGCheck *bool    `json:"repo_gpgcheck,omitempty" toml:"repo_gpgcheck,omitempty"`
+	GPGKeys      []string `json:"gpgkeys,omitempty" toml:"gpgkeys,omitempty"`
+}
+
+const armoredGPGKeyHeader = "-----BEGIN PGP PUBLIC KEY BLOCK-----"
+
+func (c *Customizations) GetRepositories() []RepositoryCustomization {
+	if c == nil {
+		return nil
+	}
+
+	return c.Repositories
+}
+
+// GetFilename returns the name of the file in /etc/yum.repos.d the
+// repository is written to, which defaults to the ID of the repository.
+func (r *RepositoryCustomization) GetFilename() string {
+	if r.Filename == "" {
+		return r.ID + ".repo"
+	}
+	return r.Filename
+}
+
+// GetGPGKeys splits the keys of the repository into URLs, which are
+// referenced as they are, and armored keys, which have to be written into
+// the image.
+func (r *RepositoryCustomization) GetGPGKeys() (urls []string, keys []string) {
+	for _, key := range r.GPGKeys {
+		if strings.HasPrefix(strings.TrimSpace(key), armoredGPGKeyHeader) {
+			keys = append(keys, key)
+		} else {
+			urls = append(urls, key)
+		}
+	}
+	return
+}
+
+var validRepositoryID = regexp.MustCompile(`^[a-zA-Z0-9_.:-]+$`)
+var validRepositoryFilename = regexp.MustCompile(`^[a-zA-Z0-9_.-]+\.repo$`)
+
+func validateRepositories(repos []RepositoryCustomization) error {
+	ids := map[string]bool{}
+	for _, repo := range repos {
+		if !validRepositoryID.MatchString(repo.ID) {
+			return &CustomizationError{fmt.Sprintf("Invalid repository id: %q", repo.ID)}
+		}
+		if ids[repo.ID] {
+			return &CustomizationError{fmt.Sprintf("Duplicate repository id: %s", repo.ID)}
+		}
+		ids[repo.ID] = true
+
+		if !validRepositoryFilename.MatchString(repo.GetFilename()) {
+			return &CustomizationError{fmt.Sprintf("Invalid filename for repository %s: %q", repo.ID, repo.Filename)}
+		}
+
+		if strings.ContainsAny(repo.Name, "\n") {
+			return &CustomizationError{fmt.Sprintf("Invalid name for repository %s: %q", repo.ID, repo.Name)}
+		}
+
+		sources := 0
+		if len(repo.BaseURLs) > 0 {
+			sources++
+		}
+		if repo.Metalink != "" {
+			sources++
+		}
+		if repo.Mirrorlist != "" {
+			sources++
+		}
+		if sources != 1 {
+			return &CustomizationError{fmt.Sprintf("Repository %s needs exactly one of baseurls, metalink or mirrorlist", repo.ID)}
+		}
+
+		urls, _ := repo.GetGPGKeys()
+		for _, u := range append(append([]string{repo.Metalink, repo.Mirrorlist}, repo.BaseURLs...), urls...) {
+			if u == "" {
+				continue
+			}
+			if parsed, err := url.Parse(u); err != nil || parsed.Scheme == "" || strings.ContainsAny(u, " \n") {
+				return &CustomizationError{fmt.Sprintf("Invalid URL for repository %s: %q", repo.ID, u)}
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package blueprint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testGPGKey = `-----BEGIN PGP PUBLIC KEY BLOCK-----
+
+mQINBFsy23UBEACUKSphFEIEvNpy68VeW4Dt6qv+mU6am9a2AAl10JANLj1oqWX+
+=Qy1T
+-----END PGP PUBLIC KEY BLOCK-----
+`
+
+func TestGetRepositories(t *testing.T) {
+	expectedRepositories := []RepositoryCustomization{
+		{
+			ID:       "satellite",
+			Name:     "Satellite",
+			BaseURLs: []string{"https://satellite.example.com/pulp/repos/"},
+			GPGKeys:  []string{"https://satellite.example.com/RPM-GPG-KEY", testGPGKey},
+		},
+	}
+
+	TestCustomizations := Customizations{
+		Repositories: expectedRepositories,
+	}
+
+	retRepositories := TestCustomizations.GetRepositories()
+	assert.Equal(t, expectedRepositories, retRepositories)
+	assert.Equal(t, "satellite.repo", retRepositories[0].GetFilename())
+
+	urls, keys := retRepositories[0].GetGPGKeys()
+	assert.Equal(t, []string{"https://satellite.example.com/RPM-GPG-KEY"}, urls)
+	assert.Equal(t, []string{testGPGKey}, keys)
+}
+
+func TestValidateRepositories(t *testing.T) {
+	cases := []struct {
+		Name          string
+		Repositories  []RepositoryCustomization
+		ExpectedError bool
+	}{
+		{
+			Name: "baseurl and metalink repositories in one file",
+			Repositories: []RepositoryCustomization{
+				{ID: "base", Filename: "example.repo", BaseURLs: []string{"https://example.com/base/"}},
+				{ID: "updates", Filename: "example.repo", Metalink: "https://example.com/metalink?repo=updates", GPGKeys: []string{testGPGKey}},
+			},
+		},
+		{
+			Name:          "invalid id",
+			Repositories:  []RepositoryCustomization{{ID: "base repo", BaseURLs: []string{"https://example.com/base/"}}},
+			ExpectedError: true,
+		},
+		{
+			Name: "duplicate id",
+			Repositories: []RepositoryCustomization{
+				{ID: "base", BaseURLs: []string{"https://example.com/base/"}},
+				{ID: "base", Filename: "other.repo", BaseURLs: []string{"https://example.com/base/"}},
+			},
+			ExpectedError: true,
+		},
+		{
+			Name:          "invalid filename",
+			Repositories:  []RepositoryCustomization{{ID: "base", Filename: "../base.repo", BaseURLs: []string{"https://example.com/base/"}}},
+			ExpectedError: true,
+		},
+		{
+			Name:          "no source",
+			Repositories:  []RepositoryCustomization{{ID: "base"}},
+			ExpectedError: true,
+		},
+		{
+			Name:          "baseurl and mirrorlist",
+			Repositories:  []RepositoryCustomization{{ID: "base", BaseURLs: []string{"https://example.com/base/"}, Mirrorlist: "https://example.com/mirrorlist"}},
+			ExpectedError: true,
+		},
+		{
+			Name:          "invalid gpgkey",
+			Repositories:  []RepositoryCustomization{{ID: "base", BaseURLs: []string{"https://example.com/base/"}, GPGKeys: []string{"not a key"}}},
+			ExpectedError: true,
+		},
+	}
+
+	for _, c := range cases {
+		TestCustomizations := Customizations{
+			Repositories: c.Repositories,
+		}
+		err := TestCustomizations.Validate()
+		assert.Equalf(t, c.ExpectedError, err != nil, "%s: Validate() returned an unexpected error: %v", c.Name, err)
+	}
+}
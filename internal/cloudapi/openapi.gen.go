@@ -66,10 +66,31 @@ type Customizations struct {
 	Subscription *Subscription `json:"subscription,omitempty"`
 }
 
+// GCPUploadRequestOptions defines model for GCPUploadRequestOptions.
+type GCPUploadRequestOptions struct {
+
+	// Bucket to upload the image to before it is imported
+	Bucket string `json:"bucket"`
+
+	// JSON key of a service account, which may write to the bucket and create images
+	Credentials string `json:"credentials"`
+
+	// Name of the Compute Engine image, generated if not set
+	ImageName *string `json:"image_name,omitempty"`
+	ProjectId string  `json:"project_id"`
+}
+
+// GCPUploadStatus defines model for GCPUploadStatus.
+type GCPUploadStatus struct {
+	ImageName string `json:"image_name"`
+	ProjectId string `json:"project_id"`
+}
+
 // ImageRequest defines model for ImageRequest.
 type ImageRequest struct {
 	Architecture   string          `json:"architecture"`
 	ImageType      string          `json:"image_type"`
+	Ostree         *OSTree         `json:"ostree,omitempty"`
 	Repositories   []Repository    `json:"repositories"`
 	UploadRequests []UploadRequest `json:"upload_requests"`
 }
@@ -80,6 +101,29 @@ type ImageStatus struct {
 	UploadStatuses *[]UploadStatus `json:"upload_statuses,omitempty"`
 }
 
+// OSTree defines model for OSTree.
+type OSTree struct {
+
+	// Ref or checksum of the parent commit
+	Parent *string     `json:"parent,omitempty"`
+	Ref    *string     `json:"ref,omitempty"`
+	Sign   *OSTreeSign `json:"sign,omitempty"`
+
+	// Generate a static delta from the parent commit
+	StaticDeltas *bool `json:"static_deltas,omitempty"`
+
+	// Repository to pull the parent commit from
+	Url *string `json:"url,omitempty"`
+}
+
+// OSTreeSign defines model for OSTreeSign.
+type OSTreeSign struct {
+
+	// Name of the signing key on the worker
+	Key  string `json:"key"`
+	Type string `json:"type"`
+}
+
 // Repository defines model for Repository.
 type Repository struct {
 	Baseurl string `json:"baseurl"`
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"regexp"
 
 	"github.com/go-chi/chi"
 	"github.com/google/uuid"
@@ -17,6 +18,9 @@ import (
 	"github.com/osbuild/osbuild-composer/internal/worker"
 )
 
+// Names of Compute Engine images must be RFC1035 labels
+var validGCPImageName = regexp.MustCompile(`^[a-z]([-a-z0-9]*[a-z0-9])?$`)
+
 // Server represents the state of the cloud Server
 type Server struct {
 	workers     *worker.Server
@@ -104,7 +108,7 @@ func (server *Server) Compose(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, fmt.Sprintf("Failed to depsolve base packages for %s/%s/%s: %s", ir.ImageType, ir.Architecture, request.Distribution, err), http.StatusInternalServerError)
 			return
 		}
-		buildPackageSpecs := imageType.BuildPackages()
+		buildPackageSpecs := imageType.BuildPackages(bp)
 		buildPackages, _, err := server.rpmMetadata.Depsolve(buildPackageSpecs, nil, repositories, distribution.ModulePlatformID(), arch.Name())
 		if err != nil {
 			http.Error(w, fmt.Sprintf("Failed to depsolve build packages for %s/%s/%s: %s", ir.ImageType, ir.Architecture, request.Distribution, err), http.StatusInternalServerError)
@@ -122,6 +126,14 @@ func (server *Server) Compose(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 
+		if ir.Ostree != nil {
+			imageOptions.OSTree, err = ostreeImageOptions(ir.Ostree, imageType)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Invalid ostree options: %v", err), http.StatusBadRequest)
+				return
+			}
+		}
+
 		manifest, err := imageType.Manifest(nil, imageOptions, repositories, packages, buildPackages)
 		if err != nil {
 			http.Error(w, fmt.Sprintf("Failed to get manifest for for %s/%s/%s: %s", ir.ImageType, ir.Architecture, request.Distribution, err), http.StatusBadRequest)
@@ -165,9 +177,47 @@ func (server *Server) Compose(w http.ResponseWriter, r *http.Request) {
 				t.ImageName = key
 			}
 
+			targets = append(targets, t)
+		} else if uploadRequest.Type == "gcp" {
+			if imageType.Name() != "gce" {
+				http.Error(w, fmt.Sprintf("Image type %s cannot be uploaded to gcp, use gce", ir.ImageType), http.StatusBadRequest)
+				return
+			}
+
+			var gcpUploadOptions GCPUploadRequestOptions
+			jsonUploadOptions, err := json.Marshal(uploadRequest.Options)
+			if err != nil {
+				http.Error(w, "Unable to marshal gcp upload request", http.StatusInternalServerError)
+				return
+			}
+			err = json.Unmarshal(jsonUploadOptions, &gcpUploadOptions)
+			if err != nil {
+				http.Error(w, "Unable to unmarshal gcp upload request", http.StatusInternalServerError)
+				return
+			}
+
+			key := fmt.Sprintf("composer-api-%s", uuid.New().String())
+			imageName := key
+			if gcpUploadOptions.ImageName != nil {
+				imageName = *gcpUploadOptions.ImageName
+				if !validGCPImageName.MatchString(imageName) {
+					http.Error(w, fmt.Sprintf("Invalid gcp image name %q, it must match %s", imageName, validGCPImageName), http.StatusBadRequest)
+					return
+				}
+			}
+			t := target.NewGCPTarget(&target.GCPTargetOptions{
+				Filename:    imageType.Filename(),
+				Project:     gcpUploadOptions.ProjectId,
+				Bucket:      gcpUploadOptions.Bucket,
+				Object:      key + ".tar.gz",
+				ImageName:   imageName,
+				Credentials: gcpUploadOptions.Credentials,
+			})
+			t.ImageName = imageName
+
 			targets = append(targets, t)
 		} else {
-			http.Error(w, "Unknown upload request type, only aws is supported", http.StatusBadRequest)
+			http.Error(w, "Unknown upload request type, only aws and gcp are supported", http.StatusBadRequest)
 			return
 		}
 	}
@@ -216,7 +266,8 @@ func (server *Server) ComposeStatus(w http.ResponseWriter, r *http.Request, id s
 		Status: status.State.ToString(), // TODO: map the status correctly
 		ImageStatuses: &[]ImageStatus{
 			{
-				Status: status.State.ToString(), // TODO: map the status correctly
+				Status:         status.State.ToString(), // TODO: map the status correctly
+				UploadStatuses: uploadStatuses(status.Result.TargetResults),
 			},
 		},
 	}
@@ -226,3 +277,50 @@ func (server *Server) ComposeStatus(w http.ResponseWriter, r *http.Request, id s
 		panic("Failed to write response")
 	}
 }
+
+// uploadStatuses converts the results of the targets, which the worker
+// reported, to the upload statuses of an image.
+func uploadStatuses(targetResults []*target.TargetResult) *[]UploadStatus {
+	var statuses []UploadStatus
+	for _, result := range targetResults {
+		if options, ok := result.Options.(*target.GCPTargetResultOptions); ok {
+			statuses = append(statuses, GCPUploadStatus{
+				ProjectId: options.Project,
+				ImageName: options.ImageName,
+			})
+		}
+	}
+	if statuses == nil {
+		return nil
+	}
+	return &statuses
+}
+
+// ostreeImageOptions converts the ostree options of an image request. A
+// parent ref in a remote repository is resolved to the checksum of its
+// commit.
+func ostreeImageOptions(request *OSTree, imageType distro.ImageType) (distro.OSTreeImageOptions, error) {
+	var options distro.OSTreeImageOptions
+	if request.Ref != nil {
+		options.Ref = *request.Ref
+	}
+	if request.Parent != nil {
+		options.Parent = *request.Parent
+	}
+	if request.Url != nil {
+		options.URL = *request.Url
+	}
+	if request.Sign != nil {
+		options.SignType = request.Sign.Type
+		options.SignKey = request.Sign.Key
+	}
+	if request.StaticDeltas != nil {
+		options.StaticDeltas = *request.StaticDeltas
+	}
+
+	if err := options.Validate(); err != nil {
+		return options, err
+	}
+
+	return options, options.ResolveParent(imageType.OSTreeRef())
+}
@@ -7,10 +7,12 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"regexp"
 	"sort"
 	"strings"
 
 	"github.com/osbuild/osbuild-composer/internal/blueprint"
+	"github.com/osbuild/osbuild-composer/internal/ostree"
 	"github.com/osbuild/osbuild-composer/internal/rpmmd"
 )
 
@@ -61,7 +63,17 @@ type ImageType interface {
 	// Returns the canonical filename for the image type.
 	Filename() string
 
-	// Retrns the MIME-type for the image type.
+	// Returns the names of all files an image of this type consists of.
+	// The first one is Filename(). Images with more than one file are
+	// served to users as a tarball of all of them.
+	Filenames() []string
+
+	// Returns the default ref of the OSTree commit the image type builds,
+	// or an empty string for image types which do not build a commit.
+	OSTreeRef() string
+
+	// Retrns the MIME-type for the image type, which is the type of the
+	// tarball for images with more than one file.
 	MIMEType() string
 
 	// Returns the proper image size for a given output format. If the input size
@@ -72,8 +84,9 @@ type ImageType interface {
 	// type.
 	Packages(bp blueprint.Blueprint) ([]string, []string)
 
-	// Returns the build packages for the output type.
-	BuildPackages() []string
+	// Returns the build packages for the output type, including the ones
+	// needed to assemble the layout requested by the blueprint.
+	BuildPackages(bp blueprint.Blueprint) []string
 
 	// Returns an osbuild manifest, containing the sources and pipeline necessary
 	// to build an image, given output format with all packages and customizations
@@ -84,14 +97,111 @@ type ImageType interface {
 // The ImageOptions specify options for a specific image build
 type ImageOptions struct {
 	OSTree       OSTreeImageOptions
+	OVF          OVFImageOptions
 	Size         uint64
 	Subscription *SubscriptionImageOptions
 }
 
 // The OSTreeImageOptions specify ostree-specific image options
+// Parent is the checksum of the parent commit
+// URL denotes the repository to pull the parent commit from
+// SignKey is the name of the key on the worker to sign the commit with
+// StaticDeltas denotes whether to generate a static delta from the parent
 type OSTreeImageOptions struct {
-	Ref    string
-	Parent string
+	Ref          string
+	Parent       string
+	URL          string
+	SignType     string
+	SignKey      string
+	StaticDeltas bool
+}
+
+var validSignKey = regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`)
+
+// ResolveParent pins the parent, which is pulled from the repository at URL,
+// to the checksum of its commit, so that a compose always builds on the same
+// parent. Without a parent, the commit Ref points to is used, or the one
+// defaultRef, the ref of the image type, points to.
+func (o *OSTreeImageOptions) ResolveParent(defaultRef string) error {
+	if o.URL == "" {
+		return nil
+	}
+
+	parent := o.Parent
+	if parent == "" {
+		parent = o.Ref
+	}
+	if parent == "" {
+		parent = defaultRef
+	}
+	if parent == "" {
+		return errors.New("a ref or parent is required to pull the parent commit from a repository")
+	}
+
+	if !ostree.IsChecksum(parent) {
+		var err error
+		parent, err = ostree.ResolveRef(o.URL, parent)
+		if err != nil {
+			return err
+		}
+	}
+	o.Parent = parent
+
+	return nil
+}
+
+// Validate returns an error if the options cannot be combined or the ref or
+// parent are not valid. It is called before resolving the parent.
+func (o OSTreeImageOptions) Validate() error {
+	if o.Ref != "" && !ostree.IsRef(o.Ref) {
+		return fmt.Errorf("invalid ref %q", o.Ref)
+	}
+	if o.Parent != "" && !ostree.IsChecksum(o.Parent) && !ostree.IsRef(o.Parent) {
+		return fmt.Errorf("invalid parent %q, use a ref or the checksum of a commit", o.Parent)
+	}
+	if o.SignType != "" || o.SignKey != "" {
+		if o.SignType != "gpg" && o.SignType != "ed25519" {
+			return fmt.Errorf("unsupported signature type %q, use gpg or ed25519", o.SignType)
+		}
+		if !validSignKey.MatchString(o.SignKey) {
+			return fmt.Errorf("invalid signing key name %q", o.SignKey)
+		}
+	}
+	// a parent is pulled from the repository at URL
+	if o.StaticDeltas && o.Parent == "" && o.URL == "" {
+		return errors.New("static deltas require a parent commit")
+	}
+	return nil
+}
+
+// The OVFImageOptions specify the virtual machine described by the OVF
+// descriptor of OVA images. Zero values are replaced by the defaults of the
+// image type.
+// Memory is given in MiB
+// Network is the name of the network the NIC is connected to
+// NICType is the type of the network adapter
+type OVFImageOptions struct {
+	CPUs    uint
+	Memory  uint64
+	Network string
+	NICType string
+}
+
+var validNICTypes = []string{"e1000", "e1000e", "vmxnet3"}
+
+// Validate returns an error if the options describe an unsupported virtual
+// machine.
+func (o OVFImageOptions) Validate() error {
+	if o.NICType != "" {
+		i := sort.SearchStrings(validNICTypes, o.NICType)
+		if i == len(validNICTypes) || validNICTypes[i] != o.NICType {
+			return fmt.Errorf("unsupported NIC type %q, use one of %s", o.NICType, strings.Join(validNICTypes, ", "))
+		}
+	}
+	if o.Memory != 0 && o.Memory < 256 {
+		return fmt.Errorf("memory of %d MiB is too small, use at least 256 MiB", o.Memory)
+	}
+	return nil
 }
 
 // The SubscriptionImageOptions specify subscription-specific image options
@@ -105,6 +215,17 @@ type SubscriptionImageOptions struct {
 	Insights      bool
 }
 
+// EstimateSize returns an estimate of the disk space in bytes needed by an
+// image with the given packages installed: the sum of their installed sizes
+// plus half of it for filesystem metadata, the rpm database, logs and caches.
+func EstimateSize(packages []rpmmd.PackageSpec) uint64 {
+	var size uint64
+	for _, pkg := range packages {
+		size += pkg.InstallSize
+	}
+	return size + size/2
+}
+
 // A Manifest is an opaque JSON object, which is a valid input to osbuild
 type Manifest []byte
 
@@ -1,6 +1,9 @@
 package distro_test
 
 import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -11,6 +14,7 @@ import (
 	"github.com/osbuild/osbuild-composer/internal/distro/fedora32"
 	"github.com/osbuild/osbuild-composer/internal/distro/fedora33"
 	"github.com/osbuild/osbuild-composer/internal/distro/rhel8"
+	"github.com/osbuild/osbuild-composer/internal/rpmmd"
 )
 
 func TestDistro_Manifest(t *testing.T) {
@@ -22,6 +26,96 @@ func TestDistro_Manifest(t *testing.T) {
 	)
 }
 
+func TestEstimateSize(t *testing.T) {
+	packages := []rpmmd.PackageSpec{
+		{Name: "kernel", InstallSize: 60 * 1024 * 1024},
+		{Name: "bash", InstallSize: 4 * 1024 * 1024},
+		{Name: "filesystem"},
+	}
+	require.Equal(t, uint64(96*1024*1024), distro.EstimateSize(packages))
+	require.Zero(t, distro.EstimateSize(nil))
+}
+
+func TestOSTreeImageOptions_Validate(t *testing.T) {
+	valid := []distro.OSTreeImageOptions{
+		{},
+		{Ref: "test/edge", SignType: "gpg", SignKey: "edge-key"},
+		{Ref: "test/edge", SignType: "ed25519", SignKey: "edge.key_2"},
+		{Ref: "test/edge", Parent: "abc", StaticDeltas: true},
+		{Ref: "test/edge", Parent: "02604b2da6e954bd34b8b82a835e5a77d2b60ffa4d1b2f6a7a4f7b7c4c4c4c4c"},
+		{Ref: "test/edge", URL: "https://example.com/repo", StaticDeltas: true},
+	}
+	for _, options := range valid {
+		require.NoError(t, options.Validate(), options)
+	}
+
+	invalid := []distro.OSTreeImageOptions{
+		{SignKey: "edge-key"},
+		{SignType: "rsa", SignKey: "edge-key"},
+		{SignType: "gpg"},
+		{SignType: "gpg", SignKey: "../edge-key"},
+		{StaticDeltas: true},
+		{Ref: "../../x"},
+		{Ref: "test/edge?x"},
+		{Ref: "test/edge", Parent: "test/edge#x"},
+	}
+	for _, options := range invalid {
+		require.Error(t, options.Validate(), options)
+	}
+}
+
+func TestOSTreeImageOptions_ResolveParent(t *testing.T) {
+	const checksum = "02604b2da6e954bd34b8b82a835e5a77d2b60ffa4d1b2f6a7a4f7b7c4c4c4c4c"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/refs/heads/test/edge" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintln(w, checksum)
+	}))
+	defer server.Close()
+
+	cases := []distro.OSTreeImageOptions{
+		{URL: server.URL, Ref: "test/edge"},
+		{URL: server.URL, Ref: "test/next", Parent: "test/edge"},
+		{URL: server.URL, Parent: checksum},
+		{URL: server.URL},
+	}
+	for _, options := range cases {
+		require.NoError(t, options.ResolveParent("test/edge"), options)
+		require.Equal(t, checksum, options.Parent)
+	}
+
+	options := distro.OSTreeImageOptions{Ref: "test/edge"}
+	require.NoError(t, options.ResolveParent("test/edge"))
+	require.Empty(t, options.Parent)
+
+	options = distro.OSTreeImageOptions{URL: server.URL}
+	require.Error(t, options.ResolveParent(""))
+
+	options = distro.OSTreeImageOptions{URL: server.URL, Ref: "test/missing"}
+	require.Error(t, options.ResolveParent("test/edge"))
+}
+
+func TestOVFImageOptions_Validate(t *testing.T) {
+	valid := []distro.OVFImageOptions{
+		{},
+		{CPUs: 4, Memory: 8192, Network: "VM Network", NICType: "vmxnet3"},
+		{NICType: "e1000"},
+	}
+	for _, options := range valid {
+		require.NoError(t, options.Validate(), options)
+	}
+
+	invalid := []distro.OVFImageOptions{
+		{NICType: "virtio"},
+		{Memory: 64},
+	}
+	for _, options := range invalid {
+		require.Error(t, options.Validate(), options)
+	}
+}
+
 // Test that all distros are registered properly and that Registry.List() works.
 func TestDistro_RegistryList(t *testing.T) {
 	expected := []string{
@@ -3,7 +3,9 @@ package fedora31
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"sort"
+	"strconv"
 
 	"github.com/osbuild/osbuild-composer/internal/distro"
 	"github.com/osbuild/osbuild-composer/internal/osbuild"
@@ -18,6 +20,16 @@ import (
 const name = "fedora-31"
 const modulePlatformID = "platform:f31"
 
+const (
+	swapFilename      = "/swapfile"
+	swapPartitionUUID = "a1f6c6b8-4b0e-4d2c-9d4e-4f6e2e3d7b5a"
+
+	// LVM layouts need a separate /boot partition
+	lvmVolumeGroupName = "rootvg"
+	bootFilesystemUUID = "d9a5c1e4-6f3b-4a8e-b2d7-1c0e9f4a6b38"
+	bootPartitionSize  = 1024 * 1024 * 1024
+)
+
 type Fedora31 struct {
 	arches        map[string]arch
 	buildPackages []string
@@ -34,18 +46,20 @@ type imageType struct {
 	disabledServices []string
 	kernelOptions    string
 	bootable         bool
+	hybridBoot       bool
 	defaultSize      uint64
 	assembler        func(uefi bool, size uint64) *osbuild.Assembler
 }
 
 type arch struct {
-	distro             *Fedora31
-	name               string
-	bootloaderPackages []string
-	buildPackages      []string
-	legacy             string
-	uefi               bool
-	imageTypes         map[string]imageType
+	distro                 *Fedora31
+	name                   string
+	bootloaderPackages     []string
+	uefiBootloaderPackages []string
+	buildPackages          []string
+	legacy                 string
+	uefi                   bool
+	imageTypes             map[string]imageType
 }
 
 func (a *arch) Distro() distro.Distro {
@@ -78,12 +92,13 @@ func (d *Fedora31) setArches(arches ...arch) {
 	d.arches = map[string]arch{}
 	for _, a := range arches {
 		d.arches[a.name] = arch{
-			distro:             d,
-			name:               a.name,
-			bootloaderPackages: a.bootloaderPackages,
-			buildPackages:      a.buildPackages,
-			uefi:               a.uefi,
-			imageTypes:         a.imageTypes,
+			distro:                 d,
+			name:                   a.name,
+			bootloaderPackages:     a.bootloaderPackages,
+			uefiBootloaderPackages: a.uefiBootloaderPackages,
+			buildPackages:          a.buildPackages,
+			uefi:                   a.uefi,
+			imageTypes:             a.imageTypes,
 		}
 	}
 }
@@ -124,6 +139,7 @@ func (a *arch) setImageTypes(imageTypes ...imageType) {
 			disabledServices: it.disabledServices,
 			kernelOptions:    it.kernelOptions,
 			bootable:         it.bootable,
+			hybridBoot:       it.hybridBoot,
 			defaultSize:      it.defaultSize,
 			assembler:        it.assembler,
 		}
@@ -138,6 +154,14 @@ func (t *imageType) Filename() string {
 	return t.filename
 }
 
+func (t *imageType) Filenames() []string {
+	return []string{t.Filename()}
+}
+
+func (t *imageType) OSTreeRef() string {
+	return ""
+}
+
 func (t *imageType) MIMEType() string {
 	return t.mimeType
 }
@@ -160,15 +184,44 @@ func (t *imageType) Packages(bp blueprint.Blueprint) ([]string, []string) {
 	if timezone != nil {
 		packages = append(packages, "chrony")
 	}
+	if bp.Customizations.GetCACerts() != nil {
+		packages = append(packages, "ca-certificates")
+	}
+	if bp.Customizations.GetTuned() != nil {
+		packages = append(packages, "tuned")
+	}
+	if bp.Customizations.GetSudo() != nil {
+		packages = append(packages, "sudo")
+	}
+	if bp.Customizations.GetSSHD() != nil {
+		packages = append(packages, "openssh-server")
+	}
+	if bp.Customizations.GetFilesystems() != nil {
+		packages = append(packages, "lvm2")
+	}
 	if t.bootable {
 		packages = append(packages, t.arch.bootloaderPackages...)
 	}
+	if t.hybrid() {
+		packages = append(packages, t.arch.uefiBootloaderPackages...)
+	}
 
 	return packages, t.excludedPackages
 }
 
-func (t *imageType) BuildPackages() []string {
-	return append(t.arch.distro.buildPackages, t.arch.buildPackages...)
+// hybrid returns whether images of this type boot with both BIOS and UEFI
+// on their architecture, using a GPT partition table with a BIOS boot
+// partition and an EFI system partition.
+func (t *imageType) hybrid() bool {
+	return t.bootable && t.hybridBoot && t.arch.uefiBootloaderPackages != nil
+}
+
+func (t *imageType) BuildPackages(bp blueprint.Blueprint) []string {
+	packages := append(t.arch.distro.buildPackages, t.arch.buildPackages...)
+	if bp.Customizations.GetFilesystems() != nil {
+		packages = append(packages, "lvm2")
+	}
+	return packages
 }
 
 func (t *imageType) Manifest(c *blueprint.Customizations,
@@ -250,6 +303,7 @@ func New() *Fedora31 {
 		},
 		kernelOptions: "ro biosdevname=0 net.ifnames=0",
 		bootable:      true,
+		hybridBoot:    true,
 		defaultSize:   2 * GigaByte,
 		assembler: func(uefi bool, size uint64) *osbuild.Assembler {
 			return qemuAssembler("qcow2", "disk.qcow2", uefi, size)
@@ -283,6 +337,7 @@ func New() *Fedora31 {
 		},
 		kernelOptions: "ro biosdevname=0 net.ifnames=0",
 		bootable:      true,
+		hybridBoot:    true,
 		defaultSize:   2 * GigaByte,
 		assembler: func(uefi bool, size uint64) *osbuild.Assembler {
 			return qemuAssembler("qcow2", "disk.qcow2", uefi, size)
@@ -372,6 +427,11 @@ func New() *Fedora31 {
 		buildPackages: []string{
 			"grub2-pc",
 		},
+		uefiBootloaderPackages: []string{
+			"efibootmgr",
+			"grub2-efi-x64",
+			"shim-x64",
+		},
 		legacy: "i386-pc",
 	}
 	x8664.setImageTypes(
@@ -474,9 +534,27 @@ func (t *imageType) pipeline(c *blueprint.Customizations, repos []rpmmd.RepoConf
 		p.AddStage(osbuild.NewUsersStage(options))
 	}
 
+	var vg *osbuild.QEMUVolumeGroup
+	if filesystems := c.GetFilesystems(); filesystems != nil {
+		if !t.bootable {
+			return nil, fmt.Errorf("image type %s does not support filesystem customizations", t.name)
+		}
+		vg = t.lvmVolumeGroup(filesystems)
+	}
+
+	swap := c.GetSwap()
+	if swap != nil && !t.bootable {
+		return nil, fmt.Errorf("image type %s does not support swap", t.name)
+	}
+
+	if c.GetEncryption() != nil {
+		return nil, fmt.Errorf("image type %s does not support disk encryption", t.name)
+	}
+
+	uefi := t.arch.uefi || t.hybrid()
 	if t.bootable {
-		p.AddStage(osbuild.NewFSTabStage(t.fsTabStageOptions(t.arch.uefi)))
-		p.AddStage(osbuild.NewGRUB2Stage(t.grub2StageOptions(t.kernelOptions, c.GetKernel(), t.arch.uefi)))
+		p.AddStage(osbuild.NewFSTabStage(t.fsTabStageOptions(uefi, swap, vg)))
+		p.AddStage(osbuild.NewGRUB2Stage(t.grub2StageOptions(t.kernelOptions, c.GetKernel(), uefi, vg)))
 	}
 
 	if services := c.GetServices(); services != nil || t.enabledServices != nil {
@@ -487,9 +565,148 @@ func (t *imageType) pipeline(c *blueprint.Customizations, repos []rpmmd.RepoConf
 		p.AddStage(osbuild.NewFirewallStage(t.firewallStageOptions(firewall)))
 	}
 
+	if network := c.GetNetwork(); network != nil {
+		p.AddStage(osbuild.NewNMKeyfileStage(t.networkStageOptions(network)))
+	}
+
+	if caCerts := c.GetCACerts(); caCerts != nil {
+		options, err := t.caTrustStageOptions(caCerts)
+		if err != nil {
+			return nil, err
+		}
+		p.AddStage(osbuild.NewCATrustStage(options))
+	}
+
+	if sysctl := c.GetSysctl(); len(sysctl) > 0 {
+		p.AddStage(osbuild.NewSysctldStage(t.sysctldStageOptions(sysctl)))
+	}
+
+	if modules := c.GetModules(); modules != nil {
+		if len(modules.Load) > 0 {
+			p.AddStage(osbuild.NewModulesLoadStage(&osbuild.ModulesLoadStageOptions{
+				Filename: "osbuild-composer.conf",
+				Modules:  modules.Load,
+			}))
+		}
+		if len(modules.Blacklist) > 0 {
+			p.AddStage(osbuild.NewModprobeStage(t.modprobeStageOptions(modules.Blacklist)))
+		}
+	}
+
+	if tuned := c.GetTuned(); tuned != nil {
+		p.AddStage(osbuild.NewTunedStage(&osbuild.TunedStageOptions{Profiles: []string{tuned.Profile}}))
+	}
+
+	if cloudInit := c.GetCloudInit(); cloudInit != nil {
+		options, err := t.cloudInitStageOptions(cloudInit)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range options {
+			p.AddStage(osbuild.NewCloudInitStage(o))
+		}
+	}
+
+	if sudo := c.GetSudo(); sudo != nil {
+		options := t.sudoersStageOptions(sudo)
+		if err := options.Validate(); err != nil {
+			return nil, err
+		}
+		p.AddStage(osbuild.NewSudoersStage(options))
+	}
+
+	if sshd := c.GetSSHD(); sshd != nil {
+		options := t.sshdConfigStageOptions(sshd)
+		if err := options.Validate(); err != nil {
+			return nil, err
+		}
+		p.AddStage(osbuild.NewSSHDConfigStage(options))
+	}
+
+	if repos := c.GetRepositories(); repos != nil {
+		for _, options := range t.yumReposStageOptions(repos) {
+			p.AddStage(osbuild.NewYumReposStage(options))
+		}
+	}
+
+	if swap != nil && swap.GetType() == blueprint.SwapTypeFile {
+		p.AddStage(osbuild.NewSwapfileStage(&osbuild.SwapfileStageOptions{
+			Filename: swapFilename,
+			Size:     swap.GetSize(),
+		}))
+	}
+
+	if selinux := c.GetSELinux(); selinux != nil {
+		if selinux.Mode != "" {
+			p.AddStage(osbuild.NewSELinuxConfigStage(&osbuild.SELinuxConfigStageOptions{
+				State: selinux.Mode,
+				Type:  "targeted",
+			}))
+		}
+
+		booleans, err := selinux.GetBooleans()
+		if err != nil {
+			return nil, err
+		}
+		if len(booleans) > 0 {
+			p.AddStage(osbuild.NewSELinuxBooleansStage(&osbuild.SELinuxBooleansStageOptions{Booleans: booleans}))
+		}
+	}
+
 	p.AddStage(osbuild.NewSELinuxStage(t.selinuxStageOptions()))
 
-	p.Assembler = t.assembler(t.arch.uefi, size)
+	if firstBoot := c.GetFirstBoot(); firstBoot != nil {
+		p.AddStage(osbuild.NewFirstBootStage(&osbuild.FirstBootStageOptions{
+			Commands:       firstBoot.Commands,
+			WaitForNetwork: firstBoot.WaitForNetwork,
+		}))
+	}
+
+	// separate filesystems are added on top of the requested size, which
+	// is at least the minimum size of the root filesystem
+	for _, fs := range c.GetFilesystems() {
+		if fs.Mountpoint != "/" {
+			size += fs.Size
+		} else if fs.Size > size {
+			size = fs.Size
+		}
+	}
+	if vg != nil {
+		size += bootPartitionSize
+	}
+
+	// the swap space is added on top of the requested size
+	if swap != nil {
+		size += swap.GetSize()
+	}
+
+	p.Assembler = t.assembler(uefi, size)
+	if t.hybrid() {
+		qemuOptions, ok := p.Assembler.Options.(*osbuild.QEMUAssemblerOptions)
+		if !ok {
+			return nil, fmt.Errorf("image type %s does not support hybrid boot", t.name)
+		}
+		qemuOptions.AddBIOSBootPartition(t.arch.legacy)
+	}
+	if vg != nil {
+		qemuOptions, ok := p.Assembler.Options.(*osbuild.QEMUAssemblerOptions)
+		if !ok {
+			return nil, fmt.Errorf("image type %s does not support filesystem customizations", t.name)
+		}
+		boot := &osbuild.QEMUFilesystem{
+			Type:       "ext4",
+			UUID:       bootFilesystemUUID,
+			Mountpoint: "/boot",
+		}
+		qemuOptions.UseLVM(boot, bootPartitionSize/512, *vg)
+	}
+	if swap != nil && swap.GetType() == blueprint.SwapTypePartition {
+		qemuOptions, ok := p.Assembler.Options.(*osbuild.QEMUAssemblerOptions)
+		if !ok {
+			return nil, fmt.Errorf("image type %s does not support swap partitions", t.name)
+		}
+		qemuOptions.AddSwapPartition(swap.GetSize(), swapPartitionUUID)
+	}
 
 	return p, nil
 }
@@ -584,9 +801,221 @@ func (r *imageType) firewallStageOptions(firewall *blueprint.FirewallCustomizati
 		options.DisabledServices = firewall.Services.Disabled
 	}
 
+	options.DefaultZone = firewall.DefaultZone
+	for _, zone := range firewall.Zones {
+		options.Zones = append(options.Zones, osbuild.FirewallZone{
+			Name:     zone.Name,
+			Sources:  zone.Sources,
+			Services: zone.Services,
+		})
+	}
+
+	return &options
+}
+
+func (r *imageType) networkStageOptions(network *blueprint.NetworkCustomization) *osbuild.NMKeyfileStageOptions {
+	options := osbuild.NMKeyfileStageOptions{}
+
+	for _, c := range network.Connections {
+		connection := osbuild.NewNMKeyfileConnection(c.Name, c.GetType())
+		if c.Interface != "" {
+			connection.Set("connection", "interface-name", c.Interface)
+		}
+		if c.MAC != "" {
+			connection.Set("ethernet", "mac-address", c.MAC)
+		}
+		if c.Master != "" {
+			connection.Set("connection", "master", c.Master)
+			connection.Set("connection", "slave-type", "bond")
+		}
+		if c.Bond != nil && c.Bond.Mode != "" {
+			connection.Set("bond", "mode", c.Bond.Mode)
+		}
+		if c.VLAN != nil {
+			connection.Set("vlan", "id", strconv.Itoa(c.VLAN.ID))
+			connection.Set("vlan", "parent", c.VLAN.Parent)
+		}
+		if c.IPv4 != nil {
+			connection.SetIP("ipv4", c.IPv4.GetMethod(), c.IPv4.Addresses, c.IPv4.Gateway, c.IPv4.DNS)
+		}
+		if c.IPv6 != nil {
+			connection.SetIP("ipv6", c.IPv6.GetMethod(), c.IPv6.Addresses, c.IPv6.Gateway, c.IPv6.DNS)
+		}
+		options.Connections = append(options.Connections, connection)
+	}
+
+	return &options
+}
+
+func (r *imageType) caTrustStageOptions(caCerts *blueprint.CACertsCustomization) (*osbuild.CATrustStageOptions, error) {
+	certs, err := caCerts.GetCertificates()
+	if err != nil {
+		return nil, err
+	}
+
+	options := osbuild.CATrustStageOptions{}
+	for i, cert := range certs {
+		options.Anchors = append(options.Anchors, osbuild.CATrustAnchor{
+			Filename: fmt.Sprintf("osbuild-composer-%d.pem", i),
+			PEM:      cert,
+		})
+	}
+
+	return &options, nil
+}
+
+func (r *imageType) sysctldStageOptions(sysctl map[string]string) *osbuild.SysctldStageOptions {
+	keys := make([]string, 0, len(sysctl))
+	for key := range sysctl {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	options := osbuild.SysctldStageOptions{
+		Filename: "osbuild-composer.conf",
+	}
+	for _, key := range keys {
+		options.Config = append(options.Config, osbuild.SysctldStageConfigEntry{
+			Key:   key,
+			Value: sysctl[key],
+		})
+	}
+
+	return &options
+}
+
+func (r *imageType) modprobeStageOptions(blacklist []string) *osbuild.ModprobeStageOptions {
+	options := osbuild.ModprobeStageOptions{
+		Filename: "osbuild-composer.conf",
+	}
+	for _, module := range blacklist {
+		options.Commands = append(options.Commands, osbuild.ModprobeStageCommand{
+			Command:    "blacklist",
+			ModuleName: module,
+		})
+	}
+
+	return &options
+}
+
+func (r *imageType) cloudInitStageOptions(cloudInit *blueprint.CloudInitCustomization) ([]*osbuild.CloudInitStageOptions, error) {
+	var options []*osbuild.CloudInitStageOptions
+
+	if config := cloudInit.GetConfig(); config != nil {
+		options = append(options, &osbuild.CloudInitStageOptions{
+			Filename: blueprint.CloudInitConfigFilename,
+			Config:   config,
+		})
+	}
+
+	for _, snippet := range cloudInit.Snippets {
+		config, err := snippet.GetConfig()
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, &osbuild.CloudInitStageOptions{
+			Filename: snippet.Filename,
+			Config:   config,
+		})
+	}
+
+	return options, nil
+}
+
+func (r *imageType) sudoersStageOptions(sudo []blueprint.SudoCustomization) *osbuild.SudoersStageOptions {
+	options := osbuild.SudoersStageOptions{
+		Filename: "osbuild-composer",
+	}
+
+	for _, rule := range sudo {
+		options.Rules = append(options.Rules, osbuild.SudoersRule{
+			User:       rule.User,
+			Group:      rule.Group,
+			RunAs:      rule.RunAs,
+			Commands:   rule.Commands,
+			NoPassword: rule.NoPassword,
+		})
+	}
+
 	return &options
 }
 
+func (r *imageType) sshdConfigStageOptions(sshd *blueprint.SSHDCustomization) *osbuild.SSHDConfigStageOptions {
+	return &osbuild.SSHDConfigStageOptions{
+		Config: osbuild.SSHDConfig{
+			PermitRootLogin:                 sshd.PermitRootLogin,
+			PasswordAuthentication:          sshd.PasswordAuthentication,
+			ChallengeResponseAuthentication: sshd.ChallengeResponseAuthentication,
+			Ciphers:                         sshd.Ciphers,
+			MACs:                            sshd.MACs,
+			KexAlgorithms:                   sshd.KexAlgorithms,
+		},
+	}
+}
+
+// yumReposStageOptions returns the options for one stage per .repo file, in
+// the order the files first appear in the blueprint
+func (r *imageType) yumReposStageOptions(repos []blueprint.RepositoryCustomization) []*osbuild.YumReposStageOptions {
+	var options []*osbuild.YumReposStageOptions
+	files := make(map[string]*osbuild.YumReposStageOptions)
+
+	for _, repo := range repos {
+		filename := repo.GetFilename()
+		file, exists := files[filename]
+		if !exists {
+			file = &osbuild.YumReposStageOptions{Filename: filename}
+			files[filename] = file
+			options = append(options, file)
+		}
+
+		gpgKeyURLs, gpgKeys := repo.GetGPGKeys()
+		for i, key := range gpgKeys {
+			keyFilename := fmt.Sprintf("RPM-GPG-KEY-%s-%d", repo.ID, i+1)
+			file.GPGKeys = append(file.GPGKeys, osbuild.YumReposGPGKey{
+				Filename: keyFilename,
+				Key:      key,
+			})
+			gpgKeyURLs = append(gpgKeyURLs, "file:///etc/pki/rpm-gpg/"+keyFilename)
+		}
+
+		file.Repos = append(file.Repos, osbuild.YumRepository{
+			ID:           repo.ID,
+			Name:         repo.Name,
+			BaseURL:      repo.BaseURLs,
+			Metalink:     repo.Metalink,
+			Mirrorlist:   repo.Mirrorlist,
+			Enabled:      repo.Enabled,
+			GPGCheck:     repo.GPGCheck,
+			RepoGPGCheck: repo.RepoGPGCheck,
+			GPGKey:       gpgKeyURLs,
+		})
+	}
+
+	return options
+}
+
+// lvmVolumeGroup returns the volume group of the LVM layout, with one logical
+// volume per filesystem customization besides the root filesystem. The
+// filesystem UUIDs are derived from the mountpoints.
+func (r *imageType) lvmVolumeGroup(filesystems []blueprint.FilesystemCustomization) *osbuild.QEMUVolumeGroup {
+	vg := osbuild.QEMUVolumeGroup{Name: lvmVolumeGroupName}
+	for _, fs := range filesystems {
+		if fs.Mountpoint == "/" {
+			continue
+		}
+		vg.Volumes = append(vg.Volumes, osbuild.QEMULogicalVolume{
+			Name: fs.GetLogicalVolumeName(),
+			Size: fs.Size / 512,
+			Filesystem: &osbuild.QEMUFilesystem{
+				Type:       "ext4",
+				UUID:       uuid.NewSHA1(uuid.NameSpaceURL, []byte("osbuild-composer:"+fs.Mountpoint)).String(),
+				Mountpoint: fs.Mountpoint,
+			},
+		})
+	}
+	return &vg
+}
+
 func (r *imageType) systemdStageOptions(enabledServices, disabledServices []string, s *blueprint.ServicesCustomization) *osbuild.SystemdStageOptions {
 	if s != nil {
 		enabledServices = append(enabledServices, s.Enabled...)
@@ -598,18 +1027,50 @@ func (r *imageType) systemdStageOptions(enabledServices, disabledServices []stri
 	}
 }
 
-func (r *imageType) fsTabStageOptions(uefi bool) *osbuild.FSTabStageOptions {
+func (r *imageType) fsTabStageOptions(uefi bool, swap *blueprint.SwapCustomization, vg *osbuild.QEMUVolumeGroup) *osbuild.FSTabStageOptions {
 	options := osbuild.FSTabStageOptions{}
 	options.AddFilesystem("76a22bf4-f153-4541-b6c7-0332c0dfaeac", "ext4", "/", "defaults", 1, 1)
+	if vg != nil {
+		options.AddFilesystem(bootFilesystemUUID, "ext4", "/boot", "defaults", 1, 2)
+		for _, lv := range vg.Volumes {
+			options.AddFilesystem(lv.Filesystem.UUID, lv.Filesystem.Type, lv.Filesystem.Mountpoint, "defaults", 1, 2)
+		}
+	}
 	if uefi {
 		options.AddFilesystem("46BB-8120", "vfat", "/boot/efi", "umask=0077,shortname=winnt", 0, 2)
 	}
+	if swap != nil {
+		if swap.GetType() == blueprint.SwapTypePartition {
+			options.AddFilesystem(swapPartitionUUID, "swap", "none", "defaults", 0, 0)
+		} else {
+			options.FileSystems = append(options.FileSystems, &osbuild.FSTabEntry{
+				Device:  swapFilename,
+				VFSType: "swap",
+				Path:    "none",
+				Options: "defaults",
+			})
+		}
+	}
 	return &options
 }
 
-func (r *imageType) grub2StageOptions(kernelOptions string, kernel *blueprint.KernelCustomization, uefi bool) *osbuild.GRUB2StageOptions {
+func (r *imageType) grub2StageOptions(kernelOptions string, kernel *blueprint.KernelCustomization, uefi bool, vg *osbuild.QEMUVolumeGroup) *osbuild.GRUB2StageOptions {
 	id := uuid.MustParse("76a22bf4-f153-4541-b6c7-0332c0dfaeac")
 
+	// the logical volumes needed to mount the root filesystem are
+	// activated by dracut
+	var bootID *uuid.UUID
+	if vg != nil {
+		kernelOptions += fmt.Sprintf(" rd.lvm.lv=%s/root", vg.Name)
+		for _, lv := range vg.Volumes {
+			if lv.Filesystem.Mountpoint == "/usr" {
+				kernelOptions += fmt.Sprintf(" rd.lvm.lv=%s/%s", vg.Name, lv.Name)
+			}
+		}
+		id := uuid.MustParse(bootFilesystemUUID)
+		bootID = &id
+	}
+
 	if kernel != nil {
 		kernelOptions += " " + kernel.Append
 	}
@@ -622,12 +1083,13 @@ func (r *imageType) grub2StageOptions(kernelOptions string, kernel *blueprint.Ke
 	}
 
 	var legacy string
-	if !uefi {
+	if !r.arch.uefi {
 		legacy = r.arch.legacy
 	}
 
 	return &osbuild.GRUB2StageOptions{
 		RootFilesystemUUID: id,
+		BootFilesystemUUID: bootID,
 		KernelOptions:      kernelOptions,
 		Legacy:             legacy,
 		UEFI:               uefiOptions,
@@ -693,5 +1155,9 @@ func qemuAssembler(format string, filename string, uefi bool, size uint64) *osbu
 			},
 		}
 	}
+	// vSphere only imports vmdk images in the streamOptimized subformat
+	if format == "vmdk" {
+		options.Subformat = "streamOptimized"
+	}
 	return osbuild.NewQEMUAssembler(&options)
 }
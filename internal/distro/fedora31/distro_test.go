@@ -118,7 +118,7 @@ func TestImageType_BuildPackages(t *testing.T) {
 				t.Errorf("d.GetArch(%v) returned err = %v; expected nil", archLabel, err)
 				continue
 			}
-			assert.ElementsMatch(t, buildPackages[archLabel], itStruct.BuildPackages())
+			assert.ElementsMatch(t, buildPackages[archLabel], itStruct.BuildPackages(blueprint.Blueprint{}))
 		}
 	}
 }
@@ -214,6 +214,9 @@ func TestImageType_BasePackages(t *testing.T) {
 			bootloaderPackages: []string{
 				"dracut-config-generic",
 				"grub2-pc",
+				"efibootmgr",
+				"grub2-efi-x64",
+				"shim-x64",
 			},
 			excludedPackages: []string{
 				"dracut-config-rescue",
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"strconv"
 
 	"github.com/osbuild/osbuild-composer/internal/distro"
 	"github.com/osbuild/osbuild-composer/internal/osbuild"
@@ -19,6 +20,16 @@ import (
 const name = "fedora-32"
 const modulePlatformID = "platform:f32"
 
+const (
+	swapFilename      = "/swapfile"
+	swapPartitionUUID = "a1f6c6b8-4b0e-4d2c-9d4e-4f6e2e3d7b5a"
+
+	// LVM layouts need a separate /boot partition
+	lvmVolumeGroupName = "rootvg"
+	bootFilesystemUUID = "d9a5c1e4-6f3b-4a8e-b2d7-1c0e9f4a6b38"
+	bootPartitionSize  = 1024 * 1024 * 1024
+)
+
 type distribution struct {
 	arches        map[string]architecture
 	imageTypes    map[string]imageType
@@ -26,13 +37,14 @@ type distribution struct {
 }
 
 type architecture struct {
-	distro             *distribution
-	name               string
-	bootloaderPackages []string
-	buildPackages      []string
-	legacy             string
-	uefi               bool
-	imageTypes         map[string]imageType
+	distro                 *distribution
+	name                   string
+	bootloaderPackages     []string
+	uefiBootloaderPackages []string
+	buildPackages          []string
+	legacy                 string
+	uefi                   bool
+	imageTypes             map[string]imageType
 }
 
 type imageType struct {
@@ -46,7 +58,10 @@ type imageType struct {
 	disabledServices []string
 	kernelOptions    string
 	bootable         bool
+	hybridBoot       bool
 	rpmOstree        bool
+	container        bool
+	liveISO          bool
 	defaultSize      uint64
 	assembler        func(uefi bool, options distro.ImageOptions, arch distro.Arch) *osbuild.Assembler
 }
@@ -81,12 +96,13 @@ func (d *distribution) setArches(arches ...architecture) {
 	d.arches = map[string]architecture{}
 	for _, a := range arches {
 		d.arches[a.name] = architecture{
-			distro:             d,
-			name:               a.name,
-			bootloaderPackages: a.bootloaderPackages,
-			buildPackages:      a.buildPackages,
-			uefi:               a.uefi,
-			imageTypes:         a.imageTypes,
+			distro:                 d,
+			name:                   a.name,
+			bootloaderPackages:     a.bootloaderPackages,
+			uefiBootloaderPackages: a.uefiBootloaderPackages,
+			buildPackages:          a.buildPackages,
+			uefi:                   a.uefi,
+			imageTypes:             a.imageTypes,
 		}
 	}
 }
@@ -127,7 +143,10 @@ func (a *architecture) setImageTypes(imageTypes ...imageType) {
 			disabledServices: it.disabledServices,
 			kernelOptions:    it.kernelOptions,
 			bootable:         it.bootable,
+			hybridBoot:       it.hybridBoot,
 			rpmOstree:        it.rpmOstree,
+			container:        it.container,
+			liveISO:          it.liveISO,
 			defaultSize:      it.defaultSize,
 			assembler:        it.assembler,
 		}
@@ -142,16 +161,32 @@ func (t *imageType) Filename() string {
 	return t.filename
 }
 
+func (t *imageType) Filenames() []string {
+	return []string{t.Filename()}
+}
+
+func (t *imageType) OSTreeRef() string {
+	if t.rpmOstree {
+		return ostreeRef(distro.ImageOptions{}, t.arch)
+	}
+	return ""
+}
+
 func (t *imageType) MIMEType() string {
 	return t.mimeType
 }
 
 func (t *imageType) Size(size uint64) uint64 {
 	const MegaByte = 1024 * 1024
+	const GigaByte = 1024 * MegaByte
 	// Microsoft Azure requires vhd images to be rounded up to the nearest MB
 	if t.name == "vhd" && size%MegaByte != 0 {
 		size = (size/MegaByte + 1) * MegaByte
 	}
+	// Google Compute Engine requires raw disks to be a multiple of 1 GiB
+	if t.name == "gce" && size%GigaByte != 0 {
+		size = (size/GigaByte + 1) * GigaByte
+	}
 	if size == 0 {
 		size = t.defaultSize
 	}
@@ -164,18 +199,49 @@ func (t *imageType) Packages(bp blueprint.Blueprint) ([]string, []string) {
 	if timezone != nil {
 		packages = append(packages, "chrony")
 	}
+	if bp.Customizations.GetCACerts() != nil {
+		packages = append(packages, "ca-certificates")
+	}
+	if bp.Customizations.GetTuned() != nil {
+		packages = append(packages, "tuned")
+	}
+	if bp.Customizations.GetSudo() != nil {
+		packages = append(packages, "sudo")
+	}
+	if bp.Customizations.GetSSHD() != nil {
+		packages = append(packages, "openssh-server")
+	}
+	if bp.Customizations.GetFilesystems() != nil {
+		packages = append(packages, "lvm2")
+	}
 	if t.bootable {
 		packages = append(packages, t.arch.bootloaderPackages...)
 	}
+	if t.hybrid() {
+		packages = append(packages, t.arch.uefiBootloaderPackages...)
+	}
 
 	return packages, t.excludedPackages
 }
 
-func (t *imageType) BuildPackages() []string {
+// hybrid returns whether images of this type boot with both BIOS and UEFI
+// on their architecture, using a GPT partition table with a BIOS boot
+// partition and an EFI system partition.
+func (t *imageType) hybrid() bool {
+	return t.bootable && t.hybridBoot && t.arch.uefiBootloaderPackages != nil
+}
+
+func (t *imageType) BuildPackages(bp blueprint.Blueprint) []string {
 	packages := append(t.arch.distro.buildPackages, t.arch.buildPackages...)
+	if bp.Customizations.GetFilesystems() != nil {
+		packages = append(packages, "lvm2")
+	}
 	if t.rpmOstree {
 		packages = append(packages, "rpm-ostree")
 	}
+	if t.liveISO {
+		packages = append(packages, "squashfs-tools", "xorriso")
+	}
 	return packages
 }
 
@@ -191,7 +257,7 @@ func (t *imageType) Manifest(c *blueprint.Customizations,
 
 	return json.Marshal(
 		osbuild.Manifest{
-			Sources:  *sources(append(packageSpecs, buildPackageSpecs...)),
+			Sources:  *sources(append(packageSpecs, buildPackageSpecs...), options.OSTree),
 			Pipeline: *pipeline,
 		},
 	)
@@ -205,7 +271,7 @@ func (d *distribution) ModulePlatformID() string {
 	return modulePlatformID
 }
 
-func sources(packages []rpmmd.PackageSpec) *osbuild.Sources {
+func sources(packages []rpmmd.PackageSpec, ostree distro.OSTreeImageOptions) *osbuild.Sources {
 	files := &osbuild.FilesSource{
 		URLs: make(map[string]osbuild.FileSource),
 	}
@@ -215,9 +281,21 @@ func sources(packages []rpmmd.PackageSpec) *osbuild.Sources {
 		}
 		files.URLs[pkg.Checksum] = fileSource
 	}
-	return &osbuild.Sources{
+	sources := osbuild.Sources{
 		"org.osbuild.files": files,
 	}
+	if ostree.Parent != "" && ostree.URL != "" {
+		sources["org.osbuild.ostree"] = &osbuild.OSTreeSource{
+			Items: map[string]osbuild.OSTreeSourceItem{
+				ostree.Parent: {
+					Remote: osbuild.OSTreeRemote{
+						URL: ostree.URL,
+					},
+				},
+			},
+		}
+	}
+	return &sources
 }
 
 func (t *imageType) pipeline(c *blueprint.Customizations, options distro.ImageOptions, repos []rpmmd.RepoConfig, packageSpecs, buildPackageSpecs []rpmmd.PackageSpec) (*osbuild.Pipeline, error) {
@@ -227,6 +305,11 @@ func (t *imageType) pipeline(c *blueprint.Customizations, options distro.ImageOp
 	p.AddStage(osbuild.NewRPMStage(t.rpmStageOptions(*t.arch, repos, packageSpecs)))
 	p.AddStage(osbuild.NewFixBLSStage())
 
+	if t.liveISO {
+		p.AddStage(osbuild.NewDracutConfStage(t.liveDracutConfStageOptions()))
+		p.AddStage(osbuild.NewDracutStage(&osbuild.DracutStageOptions{}))
+	}
+
 	// TODO support setting all languages and install corresponding langpack-* package
 	language, keyboard := c.GetPrimaryLocale()
 
@@ -266,9 +349,27 @@ func (t *imageType) pipeline(c *blueprint.Customizations, options distro.ImageOp
 		p.AddStage(osbuild.NewUsersStage(options))
 	}
 
+	var vg *osbuild.QEMUVolumeGroup
+	if filesystems := c.GetFilesystems(); filesystems != nil {
+		if !t.bootable {
+			return nil, fmt.Errorf("image type %s does not support filesystem customizations", t.name)
+		}
+		vg = t.lvmVolumeGroup(filesystems)
+	}
+
+	swap := c.GetSwap()
+	if swap != nil && !t.bootable {
+		return nil, fmt.Errorf("image type %s does not support swap", t.name)
+	}
+
+	if c.GetEncryption() != nil {
+		return nil, fmt.Errorf("image type %s does not support disk encryption", t.name)
+	}
+
+	uefi := t.arch.uefi || t.hybrid()
 	if t.bootable {
-		p.AddStage(osbuild.NewFSTabStage(t.fsTabStageOptions(t.arch.uefi)))
-		p.AddStage(osbuild.NewGRUB2Stage(t.grub2StageOptions(t.kernelOptions, c.GetKernel(), t.arch.uefi)))
+		p.AddStage(osbuild.NewFSTabStage(t.fsTabStageOptions(uefi, swap, vg)))
+		p.AddStage(osbuild.NewGRUB2Stage(t.grub2StageOptions(t.kernelOptions, c.GetKernel(), uefi, vg)))
 	}
 
 	if services := c.GetServices(); services != nil || t.enabledServices != nil {
@@ -279,7 +380,98 @@ func (t *imageType) pipeline(c *blueprint.Customizations, options distro.ImageOp
 		p.AddStage(osbuild.NewFirewallStage(t.firewallStageOptions(firewall)))
 	}
 
-	p.AddStage(osbuild.NewSELinuxStage(t.selinuxStageOptions()))
+	if network := c.GetNetwork(); network != nil {
+		p.AddStage(osbuild.NewNMKeyfileStage(t.networkStageOptions(network)))
+	}
+
+	if caCerts := c.GetCACerts(); caCerts != nil {
+		options, err := t.caTrustStageOptions(caCerts)
+		if err != nil {
+			return nil, err
+		}
+		p.AddStage(osbuild.NewCATrustStage(options))
+	}
+
+	if sysctl := c.GetSysctl(); len(sysctl) > 0 {
+		p.AddStage(osbuild.NewSysctldStage(t.sysctldStageOptions(sysctl)))
+	}
+
+	if modules := c.GetModules(); modules != nil {
+		if len(modules.Load) > 0 {
+			p.AddStage(osbuild.NewModulesLoadStage(&osbuild.ModulesLoadStageOptions{
+				Filename: "osbuild-composer.conf",
+				Modules:  modules.Load,
+			}))
+		}
+		if len(modules.Blacklist) > 0 {
+			p.AddStage(osbuild.NewModprobeStage(t.modprobeStageOptions(modules.Blacklist)))
+		}
+	}
+
+	if tuned := c.GetTuned(); tuned != nil {
+		p.AddStage(osbuild.NewTunedStage(&osbuild.TunedStageOptions{Profiles: []string{tuned.Profile}}))
+	}
+
+	if cloudInit := c.GetCloudInit(); cloudInit != nil {
+		options, err := t.cloudInitStageOptions(cloudInit)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range options {
+			p.AddStage(osbuild.NewCloudInitStage(o))
+		}
+	}
+
+	if sudo := c.GetSudo(); sudo != nil {
+		options := t.sudoersStageOptions(sudo)
+		if err := options.Validate(); err != nil {
+			return nil, err
+		}
+		p.AddStage(osbuild.NewSudoersStage(options))
+	}
+
+	if sshd := c.GetSSHD(); sshd != nil {
+		options := t.sshdConfigStageOptions(sshd)
+		if err := options.Validate(); err != nil {
+			return nil, err
+		}
+		p.AddStage(osbuild.NewSSHDConfigStage(options))
+	}
+
+	if repos := c.GetRepositories(); repos != nil {
+		for _, options := range t.yumReposStageOptions(repos) {
+			p.AddStage(osbuild.NewYumReposStage(options))
+		}
+	}
+
+	if swap != nil && swap.GetType() == blueprint.SwapTypeFile {
+		p.AddStage(osbuild.NewSwapfileStage(&osbuild.SwapfileStageOptions{
+			Filename: swapFilename,
+			Size:     swap.GetSize(),
+		}))
+	}
+
+	if selinux := c.GetSELinux(); selinux != nil {
+		if selinux.Mode != "" {
+			p.AddStage(osbuild.NewSELinuxConfigStage(&osbuild.SELinuxConfigStageOptions{
+				State: selinux.Mode,
+				Type:  "targeted",
+			}))
+		}
+
+		booleans, err := selinux.GetBooleans()
+		if err != nil {
+			return nil, err
+		}
+		if len(booleans) > 0 {
+			p.AddStage(osbuild.NewSELinuxBooleansStage(&osbuild.SELinuxBooleansStageOptions{Booleans: booleans}))
+		}
+	}
+
+	// Container runtimes label the files of containers themselves
+	if !t.container {
+		p.AddStage(osbuild.NewSELinuxStage(t.selinuxStageOptions()))
+	}
 
 	if t.rpmOstree {
 		p.AddStage(osbuild.NewRPMOSTreeStage(&osbuild.RPMOSTreeStageOptions{
@@ -290,7 +482,80 @@ func (t *imageType) pipeline(c *blueprint.Customizations, options distro.ImageOp
 		}))
 	}
 
-	p.Assembler = t.assembler(t.arch.uefi, options, t.arch)
+	if firstBoot := c.GetFirstBoot(); firstBoot != nil {
+		p.AddStage(osbuild.NewFirstBootStage(&osbuild.FirstBootStageOptions{
+			Commands:       firstBoot.Commands,
+			WaitForNetwork: firstBoot.WaitForNetwork,
+		}))
+	}
+
+	// separate filesystems are added on top of the requested size, which
+	// is at least the minimum size of the root filesystem
+	for _, fs := range c.GetFilesystems() {
+		if fs.Mountpoint != "/" {
+			options.Size += fs.Size
+		} else if fs.Size > options.Size {
+			options.Size = fs.Size
+		}
+	}
+	if vg != nil {
+		options.Size += bootPartitionSize
+	}
+
+	// the swap space is added on top of the requested size
+	if swap != nil {
+		options.Size += swap.GetSize()
+	}
+
+	p.Assembler = t.assembler(uefi, options, t.arch)
+	if t.hybrid() {
+		qemuOptions, ok := p.Assembler.Options.(*osbuild.QEMUAssemblerOptions)
+		if !ok {
+			return nil, fmt.Errorf("image type %s does not support hybrid boot", t.name)
+		}
+		qemuOptions.AddBIOSBootPartition(t.arch.legacy)
+	}
+	if t.liveISO {
+		liveOptions, ok := p.Assembler.Options.(*osbuild.LiveISOAssemblerOptions)
+		if !ok {
+			return nil, fmt.Errorf("image type %s does not support live boot", t.name)
+		}
+		liveOptions.KernelOptions = t.kernelOptions
+		if kernel := c.GetKernel(); kernel != nil && kernel.Append != "" {
+			liveOptions.KernelOptions += " " + kernel.Append
+		}
+	}
+	if container := c.GetContainer(); container != nil {
+		ociOptions, ok := p.Assembler.Options.(*osbuild.OCIArchiveAssemblerOptions)
+		if !ok {
+			return nil, fmt.Errorf("image type %s does not support container customizations", t.name)
+		}
+		ociOptions.Config = &osbuild.OCIArchiveConfig{
+			Entrypoint: container.Entrypoint,
+			Cmd:        container.Cmd,
+			Env:        container.Env,
+			Labels:     container.Labels,
+		}
+	}
+	if vg != nil {
+		qemuOptions, ok := p.Assembler.Options.(*osbuild.QEMUAssemblerOptions)
+		if !ok {
+			return nil, fmt.Errorf("image type %s does not support filesystem customizations", t.name)
+		}
+		boot := &osbuild.QEMUFilesystem{
+			Type:       "ext4",
+			UUID:       bootFilesystemUUID,
+			Mountpoint: "/boot",
+		}
+		qemuOptions.UseLVM(boot, bootPartitionSize/512, *vg)
+	}
+	if swap != nil && swap.GetType() == blueprint.SwapTypePartition {
+		qemuOptions, ok := p.Assembler.Options.(*osbuild.QEMUAssemblerOptions)
+		if !ok {
+			return nil, fmt.Errorf("image type %s does not support swap partitions", t.name)
+		}
+		qemuOptions.AddSwapPartition(swap.GetSize(), swapPartitionUUID)
+	}
 
 	return p, nil
 }
@@ -394,9 +659,232 @@ func (t *imageType) firewallStageOptions(firewall *blueprint.FirewallCustomizati
 		options.DisabledServices = firewall.Services.Disabled
 	}
 
+	options.DefaultZone = firewall.DefaultZone
+	for _, zone := range firewall.Zones {
+		options.Zones = append(options.Zones, osbuild.FirewallZone{
+			Name:     zone.Name,
+			Sources:  zone.Sources,
+			Services: zone.Services,
+		})
+	}
+
 	return &options
 }
 
+func (t *imageType) networkStageOptions(network *blueprint.NetworkCustomization) *osbuild.NMKeyfileStageOptions {
+	options := osbuild.NMKeyfileStageOptions{}
+
+	for _, c := range network.Connections {
+		connection := osbuild.NewNMKeyfileConnection(c.Name, c.GetType())
+		if c.Interface != "" {
+			connection.Set("connection", "interface-name", c.Interface)
+		}
+		if c.MAC != "" {
+			connection.Set("ethernet", "mac-address", c.MAC)
+		}
+		if c.Master != "" {
+			connection.Set("connection", "master", c.Master)
+			connection.Set("connection", "slave-type", "bond")
+		}
+		if c.Bond != nil && c.Bond.Mode != "" {
+			connection.Set("bond", "mode", c.Bond.Mode)
+		}
+		if c.VLAN != nil {
+			connection.Set("vlan", "id", strconv.Itoa(c.VLAN.ID))
+			connection.Set("vlan", "parent", c.VLAN.Parent)
+		}
+		if c.IPv4 != nil {
+			connection.SetIP("ipv4", c.IPv4.GetMethod(), c.IPv4.Addresses, c.IPv4.Gateway, c.IPv4.DNS)
+		}
+		if c.IPv6 != nil {
+			connection.SetIP("ipv6", c.IPv6.GetMethod(), c.IPv6.Addresses, c.IPv6.Gateway, c.IPv6.DNS)
+		}
+		options.Connections = append(options.Connections, connection)
+	}
+
+	return &options
+}
+
+func (t *imageType) caTrustStageOptions(caCerts *blueprint.CACertsCustomization) (*osbuild.CATrustStageOptions, error) {
+	certs, err := caCerts.GetCertificates()
+	if err != nil {
+		return nil, err
+	}
+
+	options := osbuild.CATrustStageOptions{}
+	for i, cert := range certs {
+		options.Anchors = append(options.Anchors, osbuild.CATrustAnchor{
+			Filename: fmt.Sprintf("osbuild-composer-%d.pem", i),
+			PEM:      cert,
+		})
+	}
+
+	return &options, nil
+}
+
+func (t *imageType) sysctldStageOptions(sysctl map[string]string) *osbuild.SysctldStageOptions {
+	keys := make([]string, 0, len(sysctl))
+	for key := range sysctl {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	options := osbuild.SysctldStageOptions{
+		Filename: "osbuild-composer.conf",
+	}
+	for _, key := range keys {
+		options.Config = append(options.Config, osbuild.SysctldStageConfigEntry{
+			Key:   key,
+			Value: sysctl[key],
+		})
+	}
+
+	return &options
+}
+
+func (t *imageType) modprobeStageOptions(blacklist []string) *osbuild.ModprobeStageOptions {
+	options := osbuild.ModprobeStageOptions{
+		Filename: "osbuild-composer.conf",
+	}
+	for _, module := range blacklist {
+		options.Commands = append(options.Commands, osbuild.ModprobeStageCommand{
+			Command:    "blacklist",
+			ModuleName: module,
+		})
+	}
+
+	return &options
+}
+
+func (t *imageType) cloudInitStageOptions(cloudInit *blueprint.CloudInitCustomization) ([]*osbuild.CloudInitStageOptions, error) {
+	var options []*osbuild.CloudInitStageOptions
+
+	if config := cloudInit.GetConfig(); config != nil {
+		options = append(options, &osbuild.CloudInitStageOptions{
+			Filename: blueprint.CloudInitConfigFilename,
+			Config:   config,
+		})
+	}
+
+	for _, snippet := range cloudInit.Snippets {
+		config, err := snippet.GetConfig()
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, &osbuild.CloudInitStageOptions{
+			Filename: snippet.Filename,
+			Config:   config,
+		})
+	}
+
+	return options, nil
+}
+
+func (t *imageType) sudoersStageOptions(sudo []blueprint.SudoCustomization) *osbuild.SudoersStageOptions {
+	options := osbuild.SudoersStageOptions{
+		Filename: "osbuild-composer",
+	}
+
+	for _, rule := range sudo {
+		options.Rules = append(options.Rules, osbuild.SudoersRule{
+			User:       rule.User,
+			Group:      rule.Group,
+			RunAs:      rule.RunAs,
+			Commands:   rule.Commands,
+			NoPassword: rule.NoPassword,
+		})
+	}
+
+	return &options
+}
+
+func (t *imageType) sshdConfigStageOptions(sshd *blueprint.SSHDCustomization) *osbuild.SSHDConfigStageOptions {
+	return &osbuild.SSHDConfigStageOptions{
+		Config: osbuild.SSHDConfig{
+			PermitRootLogin:                 sshd.PermitRootLogin,
+			PasswordAuthentication:          sshd.PasswordAuthentication,
+			ChallengeResponseAuthentication: sshd.ChallengeResponseAuthentication,
+			Ciphers:                         sshd.Ciphers,
+			MACs:                            sshd.MACs,
+			KexAlgorithms:                   sshd.KexAlgorithms,
+		},
+	}
+}
+
+// yumReposStageOptions returns the options for one stage per .repo file, in
+// the order the files first appear in the blueprint
+func (t *imageType) yumReposStageOptions(repos []blueprint.RepositoryCustomization) []*osbuild.YumReposStageOptions {
+	var options []*osbuild.YumReposStageOptions
+	files := make(map[string]*osbuild.YumReposStageOptions)
+
+	for _, repo := range repos {
+		filename := repo.GetFilename()
+		file, exists := files[filename]
+		if !exists {
+			file = &osbuild.YumReposStageOptions{Filename: filename}
+			files[filename] = file
+			options = append(options, file)
+		}
+
+		gpgKeyURLs, gpgKeys := repo.GetGPGKeys()
+		for i, key := range gpgKeys {
+			keyFilename := fmt.Sprintf("RPM-GPG-KEY-%s-%d", repo.ID, i+1)
+			file.GPGKeys = append(file.GPGKeys, osbuild.YumReposGPGKey{
+				Filename: keyFilename,
+				Key:      key,
+			})
+			gpgKeyURLs = append(gpgKeyURLs, "file:///etc/pki/rpm-gpg/"+keyFilename)
+		}
+
+		file.Repos = append(file.Repos, osbuild.YumRepository{
+			ID:           repo.ID,
+			Name:         repo.Name,
+			BaseURL:      repo.BaseURLs,
+			Metalink:     repo.Metalink,
+			Mirrorlist:   repo.Mirrorlist,
+			Enabled:      repo.Enabled,
+			GPGCheck:     repo.GPGCheck,
+			RepoGPGCheck: repo.RepoGPGCheck,
+			GPGKey:       gpgKeyURLs,
+		})
+	}
+
+	return options
+}
+
+// lvmVolumeGroup returns the volume group of the LVM layout, with one logical
+// volume per filesystem customization besides the root filesystem. The
+// filesystem UUIDs are derived from the mountpoints.
+func (t *imageType) lvmVolumeGroup(filesystems []blueprint.FilesystemCustomization) *osbuild.QEMUVolumeGroup {
+	vg := osbuild.QEMUVolumeGroup{Name: lvmVolumeGroupName}
+	for _, fs := range filesystems {
+		if fs.Mountpoint == "/" {
+			continue
+		}
+		vg.Volumes = append(vg.Volumes, osbuild.QEMULogicalVolume{
+			Name: fs.GetLogicalVolumeName(),
+			Size: fs.Size / 512,
+			Filesystem: &osbuild.QEMUFilesystem{
+				Type:       "ext4",
+				UUID:       uuid.NewSHA1(uuid.NameSpaceURL, []byte("osbuild-composer:"+fs.Mountpoint)).String(),
+				Mountpoint: fs.Mountpoint,
+			},
+		})
+	}
+	return &vg
+}
+
+// liveDracutConfStageOptions adds the dracut module, which mounts the
+// squashfs root filesystem of live ISOs.
+func (t *imageType) liveDracutConfStageOptions() *osbuild.DracutConfStageOptions {
+	return &osbuild.DracutConfStageOptions{
+		Filename: "40-live.conf",
+		Config: osbuild.DracutConfig{
+			AddModules: []string{"dmsquash-live"},
+		},
+	}
+}
+
 func (t *imageType) systemdStageOptions(enabledServices, disabledServices []string, s *blueprint.ServicesCustomization) *osbuild.SystemdStageOptions {
 	if s != nil {
 		enabledServices = append(enabledServices, s.Enabled...)
@@ -408,18 +896,50 @@ func (t *imageType) systemdStageOptions(enabledServices, disabledServices []stri
 	}
 }
 
-func (t *imageType) fsTabStageOptions(uefi bool) *osbuild.FSTabStageOptions {
+func (t *imageType) fsTabStageOptions(uefi bool, swap *blueprint.SwapCustomization, vg *osbuild.QEMUVolumeGroup) *osbuild.FSTabStageOptions {
 	options := osbuild.FSTabStageOptions{}
 	options.AddFilesystem("76a22bf4-f153-4541-b6c7-0332c0dfaeac", "ext4", "/", "defaults", 1, 1)
+	if vg != nil {
+		options.AddFilesystem(bootFilesystemUUID, "ext4", "/boot", "defaults", 1, 2)
+		for _, lv := range vg.Volumes {
+			options.AddFilesystem(lv.Filesystem.UUID, lv.Filesystem.Type, lv.Filesystem.Mountpoint, "defaults", 1, 2)
+		}
+	}
 	if uefi {
 		options.AddFilesystem("46BB-8120", "vfat", "/boot/efi", "umask=0077,shortname=winnt", 0, 2)
 	}
+	if swap != nil {
+		if swap.GetType() == blueprint.SwapTypePartition {
+			options.AddFilesystem(swapPartitionUUID, "swap", "none", "defaults", 0, 0)
+		} else {
+			options.FileSystems = append(options.FileSystems, &osbuild.FSTabEntry{
+				Device:  swapFilename,
+				VFSType: "swap",
+				Path:    "none",
+				Options: "defaults",
+			})
+		}
+	}
 	return &options
 }
 
-func (t *imageType) grub2StageOptions(kernelOptions string, kernel *blueprint.KernelCustomization, uefi bool) *osbuild.GRUB2StageOptions {
+func (t *imageType) grub2StageOptions(kernelOptions string, kernel *blueprint.KernelCustomization, uefi bool, vg *osbuild.QEMUVolumeGroup) *osbuild.GRUB2StageOptions {
 	id := uuid.MustParse("76a22bf4-f153-4541-b6c7-0332c0dfaeac")
 
+	// the logical volumes needed to mount the root filesystem are
+	// activated by dracut
+	var bootID *uuid.UUID
+	if vg != nil {
+		kernelOptions += fmt.Sprintf(" rd.lvm.lv=%s/root", vg.Name)
+		for _, lv := range vg.Volumes {
+			if lv.Filesystem.Mountpoint == "/usr" {
+				kernelOptions += fmt.Sprintf(" rd.lvm.lv=%s/%s", vg.Name, lv.Name)
+			}
+		}
+		id := uuid.MustParse(bootFilesystemUUID)
+		bootID = &id
+	}
+
 	if kernel != nil {
 		kernelOptions += " " + kernel.Append
 	}
@@ -432,12 +952,13 @@ func (t *imageType) grub2StageOptions(kernelOptions string, kernel *blueprint.Ke
 	}
 
 	var legacy string
-	if !uefi {
+	if !t.arch.uefi {
 		legacy = t.arch.legacy
 	}
 
 	return &osbuild.GRUB2StageOptions{
 		RootFilesystemUUID: id,
+		BootFilesystemUUID: bootID,
 		KernelOptions:      kernelOptions,
 		Legacy:             legacy,
 		UEFI:               uefiOptions,
@@ -503,18 +1024,61 @@ func qemuAssembler(format string, filename string, uefi bool, imageOptions distr
 			},
 		}
 	}
+	// vSphere only imports vmdk images in the streamOptimized subformat
+	if format == "vmdk" {
+		options.Subformat = "streamOptimized"
+	}
 	return osbuild.NewQEMUAssembler(&options)
 }
 
-func ostreeCommitAssembler(options distro.ImageOptions, arch distro.Arch) *osbuild.Assembler {
-	ref := options.OSTree.Ref
-	if ref == "" {
-		ref = fmt.Sprintf("fedora/32/%s/iot", arch.Name())
+// gceAssembler creates a raw disk image in a gzip compressed tarball, which
+// Google Compute Engine can import.
+func gceAssembler(uefi bool, options distro.ImageOptions) *osbuild.Assembler {
+	assembler := qemuAssembler("raw", "disk.raw", uefi, options)
+	assembler.Options.(*osbuild.QEMUAssemblerOptions).Tar = &osbuild.QEMUTarOptions{
+		Filename:    "image.tar.gz",
+		Compression: "gzip",
 	}
+	return assembler
+}
+
+func ociArchiveAssembler(filename string, arch distro.Arch) *osbuild.Assembler {
+	return osbuild.NewOCIArchiveAssembler(
+		&osbuild.OCIArchiveAssemblerOptions{
+			Architecture: ociArchitecture(arch),
+			Filename:     filename,
+		})
+}
+
+// ociArchitecture returns the name of arch in the OCI image specification,
+// which uses the names of GOARCH.
+func ociArchitecture(arch distro.Arch) string {
+	switch arch.Name() {
+	case "x86_64":
+		return "amd64"
+	case "aarch64":
+		return "arm64"
+	default:
+		return arch.Name()
+	}
+}
+
+// ostreeRef returns the ref of the commit in options, or the default ref of
+// the architecture.
+func ostreeRef(options distro.ImageOptions, arch distro.Arch) string {
+	if options.OSTree.Ref != "" {
+		return options.OSTree.Ref
+	}
+	return fmt.Sprintf("fedora/32/%s/iot", arch.Name())
+}
+
+func ostreeCommitAssembler(options distro.ImageOptions, arch distro.Arch) *osbuild.Assembler {
 	return osbuild.NewOSTreeCommitAssembler(
 		&osbuild.OSTreeCommitAssemblerOptions{
-			Ref:    ref,
-			Parent: options.OSTree.Parent,
+			Ref:          ostreeRef(options, arch),
+			Parent:       options.OSTree.Parent,
+			Sign:         ostreeSignOptions(options.OSTree),
+			StaticDeltas: options.OSTree.StaticDeltas,
 			Tar: osbuild.OSTreeCommitAssemblerTarOptions{
 				Filename: "commit.tar",
 			},
@@ -522,6 +1086,16 @@ func ostreeCommitAssembler(options distro.ImageOptions, arch distro.Arch) *osbui
 	)
 }
 
+func ostreeSignOptions(options distro.OSTreeImageOptions) *osbuild.OSTreeCommitAssemblerSignOptions {
+	if options.SignKey == "" {
+		return nil
+	}
+	return &osbuild.OSTreeCommitAssemblerSignOptions{
+		Type: options.SignType,
+		Key:  options.SignKey,
+	}
+}
+
 // New creates a new distro object, defining the supported architectures and image types
 func New() distro.Distro {
 	const GigaByte = 1024 * 1024 * 1024
@@ -643,6 +1217,7 @@ func New() distro.Distro {
 		},
 		kernelOptions: "ro biosdevname=0 net.ifnames=0",
 		bootable:      true,
+		hybridBoot:    true,
 		defaultSize:   2 * GigaByte,
 		assembler: func(uefi bool, options distro.ImageOptions, arch distro.Arch) *osbuild.Assembler {
 			return qemuAssembler("qcow2", "disk.qcow2", uefi, options)
@@ -676,12 +1251,107 @@ func New() distro.Distro {
 		},
 		kernelOptions: "ro biosdevname=0 net.ifnames=0",
 		bootable:      true,
+		hybridBoot:    true,
 		defaultSize:   2 * GigaByte,
 		assembler: func(uefi bool, options distro.ImageOptions, arch distro.Arch) *osbuild.Assembler {
 			return qemuAssembler("qcow2", "disk.qcow2", uefi, options)
 		},
 	}
 
+	containerImgType := imageType{
+		name:     "container",
+		filename: "container.tar",
+		mimeType: "application/x-tar",
+		packages: []string{
+			"fedora-release-container",
+			"bash",
+			"coreutils",
+			"glibc-minimal-langpack",
+			"dnf",
+			"findutils",
+			"gzip",
+			"rootfiles",
+			"shadow-utils",
+			"tar",
+			"vim-minimal",
+		},
+		excludedPackages: []string{
+			"dracut",
+			"grub2-common",
+			"grubby",
+			"kernel",
+			"linux-firmware",
+			"os-prober",
+			"systemd-udev",
+		},
+		bootable:  false,
+		container: true,
+		assembler: func(uefi bool, options distro.ImageOptions, arch distro.Arch) *osbuild.Assembler {
+			return ociArchiveAssembler("container.tar", arch)
+		},
+	}
+
+	liveISOImgType := imageType{
+		name:     "live-iso",
+		filename: "live.iso",
+		mimeType: "application/x-iso9660-image",
+		packages: []string{
+			"@Core",
+			"kernel",
+			"dracut-config-generic",
+			"dracut-live",
+			"selinux-policy-targeted",
+			"langpacks-en",
+			"syslinux",
+			"grub2-efi-x64-cdboot",
+			"shim-x64",
+		},
+		excludedPackages: []string{
+			"dracut-config-rescue",
+		},
+		kernelOptions: "rd.live.image",
+		liveISO:       true,
+		assembler: func(uefi bool, options distro.ImageOptions, arch distro.Arch) *osbuild.Assembler {
+			return osbuild.NewLiveISOAssembler(&osbuild.LiveISOAssemblerOptions{
+				Filename: "live.iso",
+				Product: osbuild.BootISOProduct{
+					Name:    "Fedora",
+					Version: "32",
+				},
+				ISOLabel: "Fedora-32-Live-" + arch.Name(),
+			})
+		},
+	}
+
+	gceImgType := imageType{
+		name:     "gce",
+		filename: "image.tar.gz",
+		mimeType: "application/gzip",
+		packages: []string{
+			"@Core",
+			"chrony",
+			"kernel",
+			"selinux-policy-targeted",
+			"langpacks-en",
+			"google-compute-engine-guest-configs",
+			"google-guest-agent",
+		},
+		excludedPackages: []string{
+			"dracut-config-rescue",
+		},
+		enabledServices: []string{
+			"sshd",
+		},
+		// These kernel parameters are recommended by the Compute Engine
+		// documentation
+		kernelOptions: "ro net.ifnames=0 biosdevname=0 console=ttyS0,38400n8d",
+		bootable:      true,
+		defaultSize:   10 * GigaByte,
+		assembler: func(uefi bool, options distro.ImageOptions, arch distro.Arch) *osbuild.Assembler {
+			return gceAssembler(uefi, options)
+		},
+	}
+
 	vhdImgType := imageType{
 		name:     "vhd",
 		filename: "disk.vhd",
@@ -767,11 +1437,19 @@ func New() distro.Distro {
 		buildPackages: []string{
 			"grub2-pc",
 		},
+		uefiBootloaderPackages: []string{
+			"efibootmgr",
+			"grub2-efi-x64",
+			"shim-x64",
+		},
 		legacy: "i386-pc",
 	}
 	x8664.setImageTypes(
 		iotImgType,
 		amiImgType,
+		containerImgType,
+		gceImgType,
+		liveISOImgType,
 		qcow2ImageType,
 		openstackImgType,
 		vhdImgType,
@@ -792,6 +1470,7 @@ func New() distro.Distro {
 	}
 	aarch64.setImageTypes(
 		amiImgType,
+		containerImgType,
 		qcow2ImageType,
 		openstackImgType,
 	)
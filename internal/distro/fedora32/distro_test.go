@@ -1,12 +1,16 @@
 package fedora32_test
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/osbuild/osbuild-composer/internal/blueprint"
+	"github.com/osbuild/osbuild-composer/internal/distro"
 	"github.com/osbuild/osbuild-composer/internal/distro/distro_test_common"
 	"github.com/osbuild/osbuild-composer/internal/distro/fedora32"
+	"github.com/osbuild/osbuild-composer/internal/osbuild"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestFilenameFromType(t *testing.T) {
@@ -26,6 +30,24 @@ func TestFilenameFromType(t *testing.T) {
 			want:  "image.raw",
 			want1: "application/octet-stream",
 		},
+		{
+			name:  "container",
+			args:  args{"container"},
+			want:  "container.tar",
+			want1: "application/x-tar",
+		},
+		{
+			name:  "gce",
+			args:  args{"gce"},
+			want:  "image.tar.gz",
+			want1: "application/gzip",
+		},
+		{
+			name:  "live-iso",
+			args:  args{"live-iso"},
+			want:  "live.iso",
+			want1: "application/x-iso9660-image",
+		},
 		{
 			name:  "openstack",
 			args:  args{"openstack"},
@@ -124,9 +146,11 @@ func TestImageType_BuildPackages(t *testing.T) {
 				// For now we only include rpm-ostree when building fedora-iot-commit image types, this we may want
 				// to reconsider. The only reason to specia-case it is that it might pull in a lot of dependencies
 				// for a niche usecase.
-				assert.ElementsMatch(t, append(buildPackages[archLabel], "rpm-ostree"), itStruct.BuildPackages())
+				assert.ElementsMatch(t, append(buildPackages[archLabel], "rpm-ostree"), itStruct.BuildPackages(blueprint.Blueprint{}))
+			} else if itLabel == "live-iso" {
+				assert.ElementsMatch(t, append(buildPackages[archLabel], "squashfs-tools", "xorriso"), itStruct.BuildPackages(blueprint.Blueprint{}))
 			} else {
-				assert.ElementsMatch(t, buildPackages[archLabel], itStruct.BuildPackages())
+				assert.ElementsMatch(t, buildPackages[archLabel], itStruct.BuildPackages(blueprint.Blueprint{}))
 			}
 		}
 	}
@@ -142,6 +166,9 @@ func TestImageType_Name(t *testing.T) {
 			arch: "x86_64",
 			imgNames: []string{
 				"ami",
+				"container",
+				"gce",
+				"live-iso",
 				"qcow2",
 				"openstack",
 				"vhd",
@@ -152,6 +179,7 @@ func TestImageType_Name(t *testing.T) {
 			arch: "aarch64",
 			imgNames: []string{
 				"ami",
+				"container",
 				"qcow2",
 				"openstack",
 			},
@@ -187,6 +215,11 @@ func TestImageType_Size(t *testing.T) {
 			inputSize:  0,
 			outputSize: 6 * gigaByte,
 		},
+		{
+			name:       "gce",
+			inputSize:  10*gigaByte + 1,
+			outputSize: 11 * gigaByte,
+		},
 		{
 			name:       "vhd",
 			inputSize:  10 * gigaByte,
@@ -213,6 +246,48 @@ func TestImageType_Size(t *testing.T) {
 	}
 }
 
+func TestImageType_LiveISO(t *testing.T) {
+	arch, err := fedora32.New().GetArch("x86_64")
+	require.NoError(t, err)
+
+	liveISO, err := arch.GetImageType("live-iso")
+	require.NoError(t, err)
+
+	c := &blueprint.Customizations{
+		Kernel: &blueprint.KernelCustomization{Append: "quiet"},
+		User:   []blueprint.UserCustomization{{Name: "liveuser"}},
+	}
+	m, err := liveISO.Manifest(c, distro.ImageOptions{}, nil, nil, nil)
+	require.NoError(t, err)
+
+	var manifest osbuild.Manifest
+	require.NoError(t, json.Unmarshal(m, &manifest))
+	options, ok := manifest.Pipeline.Assembler.Options.(*osbuild.LiveISOAssemblerOptions)
+	require.True(t, ok)
+	assert.Equal(t, "live.iso", options.Filename)
+	assert.Equal(t, "Fedora-32-Live-x86_64", options.ISOLabel)
+	assert.Equal(t, "rd.live.image quiet", options.KernelOptions)
+
+	var dracutConf *osbuild.DracutConfStageOptions
+	var dracut, users bool
+	for _, stage := range manifest.Pipeline.Stages {
+		switch options := stage.Options.(type) {
+		case *osbuild.DracutConfStageOptions:
+			dracutConf = options
+		case *osbuild.DracutStageOptions:
+			dracut = true
+		case *osbuild.UsersStageOptions:
+			users = true
+		case *osbuild.FSTabStageOptions, *osbuild.GRUB2StageOptions:
+			t.Errorf("unexpected stage in live ISO: %s", stage.Name)
+		}
+	}
+	require.NotNil(t, dracutConf)
+	assert.Equal(t, []string{"dmsquash-live"}, dracutConf.Config.AddModules)
+	assert.True(t, dracut)
+	assert.True(t, users)
+}
+
 func TestImageType_BasePackages(t *testing.T) {
 	pkgMaps := []struct {
 		name               string
@@ -261,6 +336,9 @@ func TestImageType_BasePackages(t *testing.T) {
 			bootloaderPackages: []string{
 				"dracut-config-generic",
 				"grub2-pc",
+				"efibootmgr",
+				"grub2-efi-x64",
+				"shim-x64",
 			},
 			excludedPackages: []string{
 				"dracut-config-rescue",
@@ -75,6 +75,16 @@ func (t *imageType) Filename() string {
 	return "test.img"
 }
 
+func (t *imageType) Filenames() []string {
+	return []string{t.Filename()}
+}
+
+// OSTreeRef returns a ref, so that the image type can stand in for ones
+// which build commits.
+func (t *imageType) OSTreeRef() string {
+	return "test/edge"
+}
+
 func (t *imageType) MIMEType() string {
 	return "application/x-test"
 }
@@ -87,7 +97,7 @@ func (t *imageType) Packages(bp blueprint.Blueprint) ([]string, []string) {
 	return nil, nil
 }
 
-func (t *imageType) BuildPackages() []string {
+func (t *imageType) BuildPackages(bp blueprint.Blueprint) []string {
 	return nil
 }
 
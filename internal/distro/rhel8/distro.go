@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"strconv"
 
 	"github.com/osbuild/osbuild-composer/internal/distro"
 	"github.com/osbuild/osbuild-composer/internal/osbuild"
@@ -19,6 +20,17 @@ import (
 const name = "rhel-8"
 const modulePlatformID = "platform:el8"
 
+const (
+	swapFilename      = "/swapfile"
+	swapPartitionUUID = "a1f6c6b8-4b0e-4d2c-9d4e-4f6e2e3d7b5a"
+
+	// LVM and LUKS layouts need a separate /boot partition
+	lvmVolumeGroupName = "rootvg"
+	luksUUID           = "5c7e0f3a-8b1d-4e26-a9f4-3d6b2c8e1f07"
+	bootFilesystemUUID = "d9a5c1e4-6f3b-4a8e-b2d7-1c0e9f4a6b38"
+	bootPartitionSize  = 1024 * 1024 * 1024
+)
+
 type distribution struct {
 	arches        map[string]architecture
 	imageTypes    map[string]imageType
@@ -26,19 +38,21 @@ type distribution struct {
 }
 
 type architecture struct {
-	distro             *distribution
-	name               string
-	bootloaderPackages []string
-	buildPackages      []string
-	legacy             string
-	uefi               bool
-	imageTypes         map[string]imageType
+	distro                 *distribution
+	name                   string
+	bootloaderPackages     []string
+	uefiBootloaderPackages []string
+	buildPackages          []string
+	legacy                 string
+	uefi                   bool
+	imageTypes             map[string]imageType
 }
 
 type imageType struct {
 	arch             *architecture
 	name             string
 	filename         string
+	filenames        []string
 	mimeType         string
 	packages         []string
 	excludedPackages []string
@@ -47,7 +61,11 @@ type imageType struct {
 	defaultTarget    string
 	kernelOptions    string
 	bootable         bool
+	hybridBoot       bool
 	rpmOstree        bool
+	installer        bool
+	container        bool
+	pxe              bool
 	defaultSize      uint64
 	assembler        func(uefi bool, options distro.ImageOptions, arch distro.Arch) *osbuild.Assembler
 }
@@ -82,12 +100,13 @@ func (d *distribution) setArches(arches ...architecture) {
 	d.arches = map[string]architecture{}
 	for _, a := range arches {
 		d.arches[a.name] = architecture{
-			distro:             d,
-			name:               a.name,
-			bootloaderPackages: a.bootloaderPackages,
-			buildPackages:      a.buildPackages,
-			uefi:               a.uefi,
-			imageTypes:         a.imageTypes,
+			distro:                 d,
+			name:                   a.name,
+			bootloaderPackages:     a.bootloaderPackages,
+			uefiBootloaderPackages: a.uefiBootloaderPackages,
+			buildPackages:          a.buildPackages,
+			uefi:                   a.uefi,
+			imageTypes:             a.imageTypes,
 		}
 	}
 }
@@ -121,6 +140,7 @@ func (a *architecture) setImageTypes(imageTypes ...imageType) {
 			arch:             a,
 			name:             it.name,
 			filename:         it.filename,
+			filenames:        it.filenames,
 			mimeType:         it.mimeType,
 			packages:         it.packages,
 			excludedPackages: it.excludedPackages,
@@ -129,7 +149,11 @@ func (a *architecture) setImageTypes(imageTypes ...imageType) {
 			defaultTarget:    it.defaultTarget,
 			kernelOptions:    it.kernelOptions,
 			bootable:         it.bootable,
+			hybridBoot:       it.hybridBoot,
 			rpmOstree:        it.rpmOstree,
+			installer:        it.installer,
+			container:        it.container,
+			pxe:              it.pxe,
 			defaultSize:      it.defaultSize,
 			assembler:        it.assembler,
 		}
@@ -144,6 +168,20 @@ func (t *imageType) Filename() string {
 	return t.filename
 }
 
+func (t *imageType) Filenames() []string {
+	if t.filenames != nil {
+		return t.filenames
+	}
+	return []string{t.filename}
+}
+
+func (t *imageType) OSTreeRef() string {
+	if t.rpmOstree {
+		return ostreeRef(distro.ImageOptions{}, t.arch)
+	}
+	return ""
+}
+
 func (t *imageType) MIMEType() string {
 	return t.mimeType
 }
@@ -161,23 +199,69 @@ func (t *imageType) Size(size uint64) uint64 {
 }
 
 func (t *imageType) Packages(bp blueprint.Blueprint) ([]string, []string) {
+	// The installer only deploys a commit, which was composed from the
+	// blueprint earlier.
+	if t.installer {
+		return t.packages, t.excludedPackages
+	}
+
 	packages := append(t.packages, bp.GetPackages()...)
 	timezone, _ := bp.Customizations.GetTimezoneSettings()
 	if timezone != nil {
 		packages = append(packages, "chrony")
 	}
+	if bp.Customizations.GetCACerts() != nil {
+		packages = append(packages, "ca-certificates")
+	}
+	if bp.Customizations.GetTuned() != nil {
+		packages = append(packages, "tuned")
+	}
+	if bp.Customizations.GetSudo() != nil {
+		packages = append(packages, "sudo")
+	}
+	if bp.Customizations.GetSSHD() != nil {
+		packages = append(packages, "openssh-server")
+	}
+	if bp.Customizations.GetFilesystems() != nil {
+		packages = append(packages, "lvm2")
+	}
+	if bp.Customizations.GetEncryption() != nil {
+		packages = append(packages, "clevis", "clevis-dracut", "clevis-luks", "cryptsetup")
+	}
 	if t.bootable {
 		packages = append(packages, t.arch.bootloaderPackages...)
 	}
+	if t.hybrid() {
+		packages = append(packages, t.arch.uefiBootloaderPackages...)
+	}
 
 	return packages, t.excludedPackages
 }
 
-func (t *imageType) BuildPackages() []string {
+// hybrid returns whether images of this type boot with both BIOS and UEFI
+// on their architecture, using a GPT partition table with a BIOS boot
+// partition and an EFI system partition.
+func (t *imageType) hybrid() bool {
+	return t.bootable && t.hybridBoot && t.arch.uefiBootloaderPackages != nil
+}
+
+func (t *imageType) BuildPackages(bp blueprint.Blueprint) []string {
 	packages := append(t.arch.distro.buildPackages, t.arch.buildPackages...)
+	if bp.Customizations.GetFilesystems() != nil {
+		packages = append(packages, "lvm2")
+	}
+	if bp.Customizations.GetEncryption() != nil {
+		packages = append(packages, "clevis-luks", "cryptsetup")
+	}
 	if t.rpmOstree {
 		packages = append(packages, "rpm-ostree")
 	}
+	if t.installer {
+		packages = append(packages, "lorax", "squashfs-tools", "xorriso")
+	}
+	if t.pxe {
+		packages = append(packages, "squashfs-tools")
+	}
 	return packages
 }
 
@@ -186,14 +270,20 @@ func (t *imageType) Manifest(c *blueprint.Customizations,
 	repos []rpmmd.RepoConfig,
 	packageSpecs,
 	buildPackageSpecs []rpmmd.PackageSpec) (distro.Manifest, error) {
-	pipeline, err := t.pipeline(c, options, repos, packageSpecs, buildPackageSpecs)
+	var pipeline *osbuild.Pipeline
+	var err error
+	if t.installer {
+		pipeline = t.installerPipeline(options, repos, packageSpecs, buildPackageSpecs)
+	} else {
+		pipeline, err = t.pipeline(c, options, repos, packageSpecs, buildPackageSpecs)
+	}
 	if err != nil {
 		return distro.Manifest{}, err
 	}
 
 	return json.Marshal(
 		osbuild.Manifest{
-			Sources:  *sources(append(packageSpecs, buildPackageSpecs...)),
+			Sources:  *sources(append(packageSpecs, buildPackageSpecs...), options.OSTree),
 			Pipeline: *pipeline,
 		},
 	)
@@ -207,7 +297,7 @@ func (d *distribution) ModulePlatformID() string {
 	return modulePlatformID
 }
 
-func sources(packages []rpmmd.PackageSpec) *osbuild.Sources {
+func sources(packages []rpmmd.PackageSpec, ostree distro.OSTreeImageOptions) *osbuild.Sources {
 	files := &osbuild.FilesSource{
 		URLs: make(map[string]osbuild.FileSource),
 	}
@@ -222,16 +312,28 @@ func sources(packages []rpmmd.PackageSpec) *osbuild.Sources {
 		}
 		files.URLs[pkg.Checksum] = fileSource
 	}
-	return &osbuild.Sources{
+	sources := osbuild.Sources{
 		"org.osbuild.files": files,
 	}
+	if ostree.Parent != "" && ostree.URL != "" {
+		sources["org.osbuild.ostree"] = &osbuild.OSTreeSource{
+			Items: map[string]osbuild.OSTreeSourceItem{
+				ostree.Parent: {
+					Remote: osbuild.OSTreeRemote{
+						URL: ostree.URL,
+					},
+				},
+			},
+		}
+	}
+	return &sources
 }
 
 func (t *imageType) pipeline(c *blueprint.Customizations, options distro.ImageOptions, repos []rpmmd.RepoConfig, packageSpecs, buildPackageSpecs []rpmmd.PackageSpec) (*osbuild.Pipeline, error) {
 	p := &osbuild.Pipeline{}
 	p.SetBuild(t.buildPipeline(repos, *t.arch, buildPackageSpecs), "org.osbuild.rhel82")
 
-	if t.arch.Name() == "s390x" {
+	if t.arch.Name() == "s390x" && !t.container {
 		p.AddStage(osbuild.NewKernelCmdlineStage(&osbuild.KernelCmdlineStageOptions{
 			RootFsUUID: "0bd700f8-090f-4556-b797-b340297ea1bd",
 			KernelOpts: "net.ifnames=0 crashkernel=auto",
@@ -241,10 +343,48 @@ func (t *imageType) pipeline(c *blueprint.Customizations, options distro.ImageOp
 	p.AddStage(osbuild.NewRPMStage(t.rpmStageOptions(*t.arch, repos, packageSpecs)))
 	p.AddStage(osbuild.NewFixBLSStage())
 
+	if t.pxe {
+		p.AddStage(osbuild.NewDracutConfStage(t.pxeDracutConfStageOptions()))
+		p.AddStage(osbuild.NewDracutStage(&osbuild.DracutStageOptions{}))
+	}
+
+	var vg *osbuild.QEMUVolumeGroup
+	if filesystems := c.GetFilesystems(); filesystems != nil {
+		if !t.bootable {
+			return nil, fmt.Errorf("image type %s does not support filesystem customizations", t.name)
+		}
+		vg = t.lvmVolumeGroup(filesystems)
+	}
+
+	swap := c.GetSwap()
+	if swap != nil && !t.bootable {
+		return nil, fmt.Errorf("image type %s does not support swap", t.name)
+	}
+
+	// The root partition is unlocked with the null pin until first boot,
+	// which binds it to the policy of the blueprint and removes the
+	// temporary passphrase. The assembler generates the passphrase, so that
+	// it is neither part of the manifest nor of the job.
+	encryption := c.GetEncryption()
+	var luks *osbuild.QEMULUKS
+	if encryption != nil {
+		if !t.bootable || t.arch.Name() == "s390x" {
+			return nil, fmt.Errorf("image type %s does not support disk encryption", t.name)
+		}
+		luks = &osbuild.QEMULUKS{
+			UUID: luksUUID,
+			Clevis: &osbuild.QEMUClevis{
+				Pin:    "null",
+				Policy: "{}",
+			},
+		}
+	}
+
+	uefi := t.arch.uefi || t.hybrid()
 	if t.bootable {
-		p.AddStage(osbuild.NewFSTabStage(t.fsTabStageOptions(t.arch.uefi)))
+		p.AddStage(osbuild.NewFSTabStage(t.fsTabStageOptions(uefi, swap, vg, encryption)))
 		if t.arch.Name() != "s390x" {
-			p.AddStage(osbuild.NewGRUB2Stage(t.grub2StageOptions(t.kernelOptions, c.GetKernel(), t.arch.uefi)))
+			p.AddStage(osbuild.NewGRUB2Stage(t.grub2StageOptions(t.kernelOptions, c.GetKernel(), uefi, vg, encryption)))
 		}
 	}
 
@@ -295,11 +435,112 @@ func (t *imageType) pipeline(c *blueprint.Customizations, options distro.ImageOp
 		p.AddStage(osbuild.NewFirewallStage(t.firewallStageOptions(firewall)))
 	}
 
-	if t.arch.Name() == "s390x" {
+	if network := c.GetNetwork(); network != nil {
+		p.AddStage(osbuild.NewNMKeyfileStage(t.networkStageOptions(network)))
+	}
+
+	if caCerts := c.GetCACerts(); caCerts != nil {
+		options, err := t.caTrustStageOptions(caCerts)
+		if err != nil {
+			return nil, err
+		}
+		p.AddStage(osbuild.NewCATrustStage(options))
+	}
+
+	if sysctl := c.GetSysctl(); len(sysctl) > 0 {
+		p.AddStage(osbuild.NewSysctldStage(t.sysctldStageOptions(sysctl)))
+	}
+
+	if modules := c.GetModules(); modules != nil {
+		if len(modules.Load) > 0 {
+			p.AddStage(osbuild.NewModulesLoadStage(&osbuild.ModulesLoadStageOptions{
+				Filename: "osbuild-composer.conf",
+				Modules:  modules.Load,
+			}))
+		}
+		if len(modules.Blacklist) > 0 {
+			p.AddStage(osbuild.NewModprobeStage(t.modprobeStageOptions(modules.Blacklist)))
+		}
+	}
+
+	if tuned := c.GetTuned(); tuned != nil {
+		p.AddStage(osbuild.NewTunedStage(&osbuild.TunedStageOptions{Profiles: []string{tuned.Profile}}))
+	}
+
+	if cloudInit := c.GetCloudInit(); cloudInit != nil {
+		options, err := t.cloudInitStageOptions(cloudInit)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range options {
+			p.AddStage(osbuild.NewCloudInitStage(o))
+		}
+	}
+
+	if sudo := c.GetSudo(); sudo != nil {
+		options := t.sudoersStageOptions(sudo)
+		if err := options.Validate(); err != nil {
+			return nil, err
+		}
+		p.AddStage(osbuild.NewSudoersStage(options))
+	}
+
+	if sshd := c.GetSSHD(); sshd != nil {
+		options := t.sshdConfigStageOptions(sshd)
+		if err := options.Validate(); err != nil {
+			return nil, err
+		}
+		p.AddStage(osbuild.NewSSHDConfigStage(options))
+	}
+
+	if repos := c.GetRepositories(); repos != nil {
+		for _, options := range t.yumReposStageOptions(repos) {
+			p.AddStage(osbuild.NewYumReposStage(options))
+		}
+	}
+
+	if swap != nil && swap.GetType() == blueprint.SwapTypeFile {
+		p.AddStage(osbuild.NewSwapfileStage(&osbuild.SwapfileStageOptions{
+			Filename: swapFilename,
+			Size:     swap.GetSize(),
+		}))
+	}
+
+	if luks != nil {
+		p.AddStage(osbuild.NewClevisLUKSStage(&osbuild.ClevisLUKSStageOptions{
+			UUID:   luks.UUID,
+			Pin:    "sss",
+			Policy: encryption.GetClevisPolicy(),
+		}))
+		p.AddStage(osbuild.NewDracutConfStage(t.dracutConfStageOptions(encryption)))
+		p.AddStage(osbuild.NewDracutStage(&osbuild.DracutStageOptions{}))
+	}
+
+	if t.arch.Name() == "s390x" && !t.container {
 		p.AddStage(osbuild.NewZiplStage(&osbuild.ZiplStageOptions{}))
 	}
 
-	p.AddStage(osbuild.NewSELinuxStage(t.selinuxStageOptions()))
+	if selinux := c.GetSELinux(); selinux != nil {
+		if selinux.Mode != "" {
+			p.AddStage(osbuild.NewSELinuxConfigStage(&osbuild.SELinuxConfigStageOptions{
+				State: selinux.Mode,
+				Type:  "targeted",
+			}))
+		}
+
+		booleans, err := selinux.GetBooleans()
+		if err != nil {
+			return nil, err
+		}
+		if len(booleans) > 0 {
+			p.AddStage(osbuild.NewSELinuxBooleansStage(&osbuild.SELinuxBooleansStageOptions{Booleans: booleans}))
+		}
+	}
+
+	// Container runtimes label the files of containers themselves
+	if !t.container {
+		p.AddStage(osbuild.NewSELinuxStage(t.selinuxStageOptions()))
+	}
 
 	if t.rpmOstree {
 		p.AddStage(osbuild.NewRPMOSTreeStage(&osbuild.RPMOSTreeStageOptions{
@@ -310,26 +551,112 @@ func (t *imageType) pipeline(c *blueprint.Customizations, options distro.ImageOp
 		}))
 	}
 
-	if options.Subscription != nil {
-		commands := []string{
-			fmt.Sprintf("/usr/sbin/subscription-manager register --org=%d --activationkey=%s --serverurl %s --baseurl %s", options.Subscription.Organization, options.Subscription.ActivationKey, options.Subscription.ServerUrl, options.Subscription.BaseUrl),
-		}
-		if options.Subscription.Insights {
-			commands = append(commands, "/usr/bin/insights-client --register")
+	if options.Subscription != nil || c.GetFirstBoot() != nil {
+		p.AddStage(osbuild.NewFirstBootStage(t.firstBootStageOptions(options.Subscription, c.GetFirstBoot())))
+	}
+
+	// separate filesystems are added on top of the requested size, which
+	// is at least the minimum size of the root filesystem
+	for _, fs := range c.GetFilesystems() {
+		if fs.Mountpoint != "/" {
+			options.Size += fs.Size
+		} else if fs.Size > options.Size {
+			options.Size = fs.Size
 		}
+	}
+	if vg != nil || luks != nil {
+		options.Size += bootPartitionSize
+	}
 
-		p.AddStage(osbuild.NewFirstBootStage(&osbuild.FirstBootStageOptions{
-			Commands:       commands,
-			WaitForNetwork: true,
-		},
-		))
+	// the swap space is added on top of the requested size
+	if swap != nil {
+		options.Size += swap.GetSize()
+	}
+
+	p.Assembler = t.assembler(uefi, options, t.arch)
+	if t.hybrid() {
+		qemuOptions, ok := qemuAssemblerOptions(p.Assembler)
+		if !ok {
+			return nil, fmt.Errorf("image type %s does not support hybrid boot", t.name)
+		}
+		qemuOptions.AddBIOSBootPartition(t.arch.legacy)
+	}
+	if t.pxe {
+		pxeOptions, ok := p.Assembler.Options.(*osbuild.PXEAssemblerOptions)
+		if !ok {
+			return nil, fmt.Errorf("image type %s does not support network boot", t.name)
+		}
+		pxeOptions.KernelOptions = t.kernelOptions
+		if kernel := c.GetKernel(); kernel != nil && kernel.Append != "" {
+			pxeOptions.KernelOptions += " " + kernel.Append
+		}
+	}
+	if container := c.GetContainer(); container != nil {
+		ociOptions, ok := p.Assembler.Options.(*osbuild.OCIArchiveAssemblerOptions)
+		if !ok {
+			return nil, fmt.Errorf("image type %s does not support container customizations", t.name)
+		}
+		ociOptions.Config = &osbuild.OCIArchiveConfig{
+			Entrypoint: container.Entrypoint,
+			Cmd:        container.Cmd,
+			Env:        container.Env,
+			Labels:     container.Labels,
+		}
 	}
+	if vg != nil || luks != nil || (swap != nil && swap.GetType() == blueprint.SwapTypePartition) {
+		qemuOptions, ok := qemuAssemblerOptions(p.Assembler)
+		if !ok {
+			return nil, fmt.Errorf("image type %s does not support changing its partition layout", t.name)
+		}
 
-	p.Assembler = t.assembler(t.arch.uefi, options, t.arch)
+		boot := &osbuild.QEMUFilesystem{
+			Type:       "xfs",
+			UUID:       bootFilesystemUUID,
+			Mountpoint: "/boot",
+		}
+		if vg != nil {
+			qemuOptions.UseLVM(boot, bootPartitionSize/512, *vg)
+		} else if luks != nil {
+			qemuOptions.AddBootPartition(boot, bootPartitionSize/512)
+		}
+
+		if luks != nil {
+			qemuOptions.EncryptLastPartition(*luks)
+		}
+
+		if swap != nil && swap.GetType() == blueprint.SwapTypePartition {
+			qemuOptions.AddSwapPartition(swap.GetSize(), swapPartitionUUID)
+		}
+	}
 
 	return p, nil
 }
 
+// installerPipeline builds an installer ISO, which deploys the commit of an
+// earlier rhel-edge-commit compose. The commit is an input of the compose,
+// see osbuild.InputChecksum().
+func (t *imageType) installerPipeline(options distro.ImageOptions, repos []rpmmd.RepoConfig, packageSpecs, buildPackageSpecs []rpmmd.PackageSpec) *osbuild.Pipeline {
+	p := &osbuild.Pipeline{}
+	p.SetBuild(t.buildPipeline(repos, *t.arch, buildPackageSpecs), "org.osbuild.rhel82")
+	p.AddStage(osbuild.NewRPMStage(t.rpmStageOptions(*t.arch, repos, packageSpecs)))
+	p.AddStage(osbuild.NewTarExtractStage(&osbuild.TarExtractStageOptions{
+		Source: osbuild.InputChecksum("commit.tar"),
+		Path:   "/ostree",
+	}))
+	p.AddStage(osbuild.NewKickstartStage(&osbuild.KickstartStageOptions{
+		Path: "/osbuild.ks",
+		OSTree: &osbuild.KickstartOSTree{
+			OSName: "rhel-edge",
+			URL:    "file:///ostree/repo",
+			Ref:    ostreeRef(options, t.arch),
+			GPG:    false,
+		},
+	}))
+	p.Assembler = t.assembler(false, options, t.arch)
+
+	return p
+}
+
 func (t *imageType) buildPipeline(repos []rpmmd.RepoConfig, arch architecture, buildPackageSpecs []rpmmd.PackageSpec) *osbuild.Pipeline {
 	p := &osbuild.Pipeline{}
 	p.AddStage(osbuild.NewRPMStage(t.rpmStageOptions(arch, repos, buildPackageSpecs)))
@@ -421,9 +748,248 @@ func (t *imageType) firewallStageOptions(firewall *blueprint.FirewallCustomizati
 		options.DisabledServices = firewall.Services.Disabled
 	}
 
+	options.DefaultZone = firewall.DefaultZone
+	for _, zone := range firewall.Zones {
+		options.Zones = append(options.Zones, osbuild.FirewallZone{
+			Name:     zone.Name,
+			Sources:  zone.Sources,
+			Services: zone.Services,
+		})
+	}
+
 	return &options
 }
 
+func (t *imageType) networkStageOptions(network *blueprint.NetworkCustomization) *osbuild.NMKeyfileStageOptions {
+	options := osbuild.NMKeyfileStageOptions{}
+
+	for _, c := range network.Connections {
+		connection := osbuild.NewNMKeyfileConnection(c.Name, c.GetType())
+		if c.Interface != "" {
+			connection.Set("connection", "interface-name", c.Interface)
+		}
+		if c.MAC != "" {
+			connection.Set("ethernet", "mac-address", c.MAC)
+		}
+		if c.Master != "" {
+			connection.Set("connection", "master", c.Master)
+			connection.Set("connection", "slave-type", "bond")
+		}
+		if c.Bond != nil && c.Bond.Mode != "" {
+			connection.Set("bond", "mode", c.Bond.Mode)
+		}
+		if c.VLAN != nil {
+			connection.Set("vlan", "id", strconv.Itoa(c.VLAN.ID))
+			connection.Set("vlan", "parent", c.VLAN.Parent)
+		}
+		if c.IPv4 != nil {
+			connection.SetIP("ipv4", c.IPv4.GetMethod(), c.IPv4.Addresses, c.IPv4.Gateway, c.IPv4.DNS)
+		}
+		if c.IPv6 != nil {
+			connection.SetIP("ipv6", c.IPv6.GetMethod(), c.IPv6.Addresses, c.IPv6.Gateway, c.IPv6.DNS)
+		}
+		options.Connections = append(options.Connections, connection)
+	}
+
+	return &options
+}
+
+func (t *imageType) caTrustStageOptions(caCerts *blueprint.CACertsCustomization) (*osbuild.CATrustStageOptions, error) {
+	certs, err := caCerts.GetCertificates()
+	if err != nil {
+		return nil, err
+	}
+
+	options := osbuild.CATrustStageOptions{}
+	for i, cert := range certs {
+		options.Anchors = append(options.Anchors, osbuild.CATrustAnchor{
+			Filename: fmt.Sprintf("osbuild-composer-%d.pem", i),
+			PEM:      cert,
+		})
+	}
+
+	return &options, nil
+}
+
+func (t *imageType) sysctldStageOptions(sysctl map[string]string) *osbuild.SysctldStageOptions {
+	keys := make([]string, 0, len(sysctl))
+	for key := range sysctl {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	options := osbuild.SysctldStageOptions{
+		Filename: "osbuild-composer.conf",
+	}
+	for _, key := range keys {
+		options.Config = append(options.Config, osbuild.SysctldStageConfigEntry{
+			Key:   key,
+			Value: sysctl[key],
+		})
+	}
+
+	return &options
+}
+
+func (t *imageType) modprobeStageOptions(blacklist []string) *osbuild.ModprobeStageOptions {
+	options := osbuild.ModprobeStageOptions{
+		Filename: "osbuild-composer.conf",
+	}
+	for _, module := range blacklist {
+		options.Commands = append(options.Commands, osbuild.ModprobeStageCommand{
+			Command:    "blacklist",
+			ModuleName: module,
+		})
+	}
+
+	return &options
+}
+
+func (t *imageType) cloudInitStageOptions(cloudInit *blueprint.CloudInitCustomization) ([]*osbuild.CloudInitStageOptions, error) {
+	var options []*osbuild.CloudInitStageOptions
+
+	if config := cloudInit.GetConfig(); config != nil {
+		options = append(options, &osbuild.CloudInitStageOptions{
+			Filename: blueprint.CloudInitConfigFilename,
+			Config:   config,
+		})
+	}
+
+	for _, snippet := range cloudInit.Snippets {
+		config, err := snippet.GetConfig()
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, &osbuild.CloudInitStageOptions{
+			Filename: snippet.Filename,
+			Config:   config,
+		})
+	}
+
+	return options, nil
+}
+
+func (t *imageType) sudoersStageOptions(sudo []blueprint.SudoCustomization) *osbuild.SudoersStageOptions {
+	options := osbuild.SudoersStageOptions{
+		Filename: "osbuild-composer",
+	}
+
+	for _, rule := range sudo {
+		options.Rules = append(options.Rules, osbuild.SudoersRule{
+			User:       rule.User,
+			Group:      rule.Group,
+			RunAs:      rule.RunAs,
+			Commands:   rule.Commands,
+			NoPassword: rule.NoPassword,
+		})
+	}
+
+	return &options
+}
+
+func (t *imageType) sshdConfigStageOptions(sshd *blueprint.SSHDCustomization) *osbuild.SSHDConfigStageOptions {
+	return &osbuild.SSHDConfigStageOptions{
+		Config: osbuild.SSHDConfig{
+			PermitRootLogin:                 sshd.PermitRootLogin,
+			PasswordAuthentication:          sshd.PasswordAuthentication,
+			ChallengeResponseAuthentication: sshd.ChallengeResponseAuthentication,
+			Ciphers:                         sshd.Ciphers,
+			MACs:                            sshd.MACs,
+			KexAlgorithms:                   sshd.KexAlgorithms,
+		},
+	}
+}
+
+// yumReposStageOptions returns the options for one stage per .repo file, in
+// the order the files first appear in the blueprint
+func (t *imageType) yumReposStageOptions(repos []blueprint.RepositoryCustomization) []*osbuild.YumReposStageOptions {
+	var options []*osbuild.YumReposStageOptions
+	files := make(map[string]*osbuild.YumReposStageOptions)
+
+	for _, repo := range repos {
+		filename := repo.GetFilename()
+		file, exists := files[filename]
+		if !exists {
+			file = &osbuild.YumReposStageOptions{Filename: filename}
+			files[filename] = file
+			options = append(options, file)
+		}
+
+		gpgKeyURLs, gpgKeys := repo.GetGPGKeys()
+		for i, key := range gpgKeys {
+			keyFilename := fmt.Sprintf("RPM-GPG-KEY-%s-%d", repo.ID, i+1)
+			file.GPGKeys = append(file.GPGKeys, osbuild.YumReposGPGKey{
+				Filename: keyFilename,
+				Key:      key,
+			})
+			gpgKeyURLs = append(gpgKeyURLs, "file:///etc/pki/rpm-gpg/"+keyFilename)
+		}
+
+		file.Repos = append(file.Repos, osbuild.YumRepository{
+			ID:           repo.ID,
+			Name:         repo.Name,
+			BaseURL:      repo.BaseURLs,
+			Metalink:     repo.Metalink,
+			Mirrorlist:   repo.Mirrorlist,
+			Enabled:      repo.Enabled,
+			GPGCheck:     repo.GPGCheck,
+			RepoGPGCheck: repo.RepoGPGCheck,
+			GPGKey:       gpgKeyURLs,
+		})
+	}
+
+	return options
+}
+
+// dracutConfStageOptions adds the dracut modules to unlock the root
+// partition with clevis to the initramfs
+func (t *imageType) dracutConfStageOptions(encryption *blueprint.EncryptionCustomization) *osbuild.DracutConfStageOptions {
+	modules := []string{"crypt", "clevis"}
+	if len(encryption.Tang) > 0 {
+		modules = append(modules, "network")
+	}
+
+	return &osbuild.DracutConfStageOptions{
+		Filename: "osbuild-composer.conf",
+		Config: osbuild.DracutConfig{
+			AddModules: modules,
+		},
+	}
+}
+
+// pxeDracutConfStageOptions adds the dracut modules, which fetch the squashfs
+// root filesystem of network booted images and mount it.
+func (t *imageType) pxeDracutConfStageOptions() *osbuild.DracutConfStageOptions {
+	return &osbuild.DracutConfStageOptions{
+		Filename: "40-pxe.conf",
+		Config: osbuild.DracutConfig{
+			AddModules: []string{"dmsquash-live", "livenet"},
+		},
+	}
+}
+
+// lvmVolumeGroup returns the volume group of the LVM layout, with one logical
+// volume per filesystem customization besides the root filesystem. The
+// filesystem UUIDs are derived from the mountpoints.
+func (t *imageType) lvmVolumeGroup(filesystems []blueprint.FilesystemCustomization) *osbuild.QEMUVolumeGroup {
+	vg := osbuild.QEMUVolumeGroup{Name: lvmVolumeGroupName}
+	for _, fs := range filesystems {
+		if fs.Mountpoint == "/" {
+			continue
+		}
+		vg.Volumes = append(vg.Volumes, osbuild.QEMULogicalVolume{
+			Name: fs.GetLogicalVolumeName(),
+			Size: fs.Size / 512,
+			Filesystem: &osbuild.QEMUFilesystem{
+				Type:       "xfs",
+				UUID:       uuid.NewSHA1(uuid.NameSpaceURL, []byte("osbuild-composer:"+fs.Mountpoint)).String(),
+				Mountpoint: fs.Mountpoint,
+			},
+		})
+	}
+	return &vg
+}
+
 func (t *imageType) systemdStageOptions(enabledServices, disabledServices []string, s *blueprint.ServicesCustomization, target string) *osbuild.SystemdStageOptions {
 	if s != nil {
 		enabledServices = append(enabledServices, s.Enabled...)
@@ -436,18 +1002,88 @@ func (t *imageType) systemdStageOptions(enabledServices, disabledServices []stri
 	}
 }
 
-func (t *imageType) fsTabStageOptions(uefi bool) *osbuild.FSTabStageOptions {
+// firstBootStageOptions combines the subscription registration commands with
+// the ones from the blueprint, as an image can only have one first-boot stage.
+func (t *imageType) firstBootStageOptions(subscription *distro.SubscriptionImageOptions, firstBoot *blueprint.FirstBootCustomization) *osbuild.FirstBootStageOptions {
+	options := osbuild.FirstBootStageOptions{
+		Commands: []string{},
+	}
+
+	if subscription != nil {
+		options.Commands = append(options.Commands,
+			fmt.Sprintf("/usr/sbin/subscription-manager register --org=%d --activationkey=%s --serverurl %s --baseurl %s", subscription.Organization, subscription.ActivationKey, subscription.ServerUrl, subscription.BaseUrl),
+		)
+		if subscription.Insights {
+			options.Commands = append(options.Commands, "/usr/bin/insights-client --register")
+		}
+		options.WaitForNetwork = true
+	}
+
+	if firstBoot != nil {
+		options.Commands = append(options.Commands, firstBoot.Commands...)
+		options.WaitForNetwork = options.WaitForNetwork || firstBoot.WaitForNetwork
+	}
+
+	return &options
+}
+
+func (t *imageType) fsTabStageOptions(uefi bool, swap *blueprint.SwapCustomization, vg *osbuild.QEMUVolumeGroup, encryption *blueprint.EncryptionCustomization) *osbuild.FSTabStageOptions {
 	options := osbuild.FSTabStageOptions{}
 	options.AddFilesystem("0bd700f8-090f-4556-b797-b340297ea1bd", "xfs", "/", "defaults", 0, 0)
+	if vg != nil || encryption != nil {
+		options.AddFilesystem(bootFilesystemUUID, "xfs", "/boot", "defaults", 0, 0)
+	}
+	if vg != nil {
+		for _, lv := range vg.Volumes {
+			options.AddFilesystem(lv.Filesystem.UUID, lv.Filesystem.Type, lv.Filesystem.Mountpoint, "defaults", 0, 0)
+		}
+	}
 	if uefi {
 		options.AddFilesystem("46BB-8120", "vfat", "/boot/efi", "umask=0077,shortname=winnt", 0, 2)
 	}
+	if swap != nil {
+		if swap.GetType() == blueprint.SwapTypePartition {
+			options.AddFilesystem(swapPartitionUUID, "swap", "none", "defaults", 0, 0)
+		} else {
+			options.FileSystems = append(options.FileSystems, &osbuild.FSTabEntry{
+				Device:  swapFilename,
+				VFSType: "swap",
+				Path:    "none",
+				Options: "defaults",
+			})
+		}
+	}
 	return &options
 }
 
-func (t *imageType) grub2StageOptions(kernelOptions string, kernel *blueprint.KernelCustomization, uefi bool) *osbuild.GRUB2StageOptions {
+func (t *imageType) grub2StageOptions(kernelOptions string, kernel *blueprint.KernelCustomization, uefi bool, vg *osbuild.QEMUVolumeGroup, encryption *blueprint.EncryptionCustomization) *osbuild.GRUB2StageOptions {
 	id := uuid.MustParse("0bd700f8-090f-4556-b797-b340297ea1bd")
 
+	// the logical volumes needed to mount the root filesystem are
+	// activated by dracut
+	var bootID *uuid.UUID
+	if vg != nil {
+		kernelOptions += fmt.Sprintf(" rd.lvm.lv=%s/root", vg.Name)
+		for _, lv := range vg.Volumes {
+			if lv.Filesystem.Mountpoint == "/usr" {
+				kernelOptions += fmt.Sprintf(" rd.lvm.lv=%s/%s", vg.Name, lv.Name)
+			}
+		}
+	}
+
+	// tang servers need the network in the initramfs
+	if encryption != nil {
+		kernelOptions += " rd.luks.uuid=" + luksUUID
+		if len(encryption.Tang) > 0 {
+			kernelOptions += " rd.neednet=1"
+		}
+	}
+
+	if vg != nil || encryption != nil {
+		id := uuid.MustParse(bootFilesystemUUID)
+		bootID = &id
+	}
+
 	if kernel != nil {
 		kernelOptions += " " + kernel.Append
 	}
@@ -460,12 +1096,13 @@ func (t *imageType) grub2StageOptions(kernelOptions string, kernel *blueprint.Ke
 	}
 
 	var legacy string
-	if !uefi {
+	if !t.arch.uefi {
 		legacy = t.arch.legacy
 	}
 
 	return &osbuild.GRUB2StageOptions{
 		RootFilesystemUUID: id,
+		BootFilesystemUUID: bootID,
 		KernelOptions:      kernelOptions,
 		Legacy:             legacy,
 		UEFI:               uefiOptions,
@@ -580,9 +1217,56 @@ func qemuAssembler(format string, filename string, uefi bool, imageOptions distr
 			}
 		}
 	}
+	// vSphere only imports vmdk images in the streamOptimized subformat
+	if format == "vmdk" {
+		options.Subformat = "streamOptimized"
+	}
 	return osbuild.NewQEMUAssembler(&options)
 }
 
+func ovaAssembler(filename string, uefi bool, imageOptions distro.ImageOptions, arch distro.Arch) *osbuild.Assembler {
+	disk := qemuAssembler("vmdk", "disk.vmdk", uefi, imageOptions, arch).Options.(*osbuild.QEMUAssemblerOptions)
+	descriptor := osbuild.OVFDescriptor{
+		Name:    "Red Hat Enterprise Linux 8",
+		OSType:  "rhel8_64Guest",
+		CPUs:    imageOptions.OVF.CPUs,
+		Memory:  imageOptions.OVF.Memory,
+		Network: imageOptions.OVF.Network,
+		NICType: imageOptions.OVF.NICType,
+	}
+	if descriptor.CPUs == 0 {
+		descriptor.CPUs = 2
+	}
+	if descriptor.Memory == 0 {
+		descriptor.Memory = 4096
+	}
+	if descriptor.Network == "" {
+		descriptor.Network = "VM Network"
+	}
+	if descriptor.NICType == "" {
+		descriptor.NICType = "vmxnet3"
+	}
+	return osbuild.NewOVAAssembler(
+		&osbuild.OVAAssemblerOptions{
+			Filename:   filename,
+			Disk:       disk,
+			Descriptor: descriptor,
+		})
+}
+
+// qemuAssemblerOptions returns the options of the disk image, which the
+// assembler creates, if it creates one with qemu.
+func qemuAssemblerOptions(assembler *osbuild.Assembler) (*osbuild.QEMUAssemblerOptions, bool) {
+	switch options := assembler.Options.(type) {
+	case *osbuild.QEMUAssemblerOptions:
+		return options, true
+	case *osbuild.OVAAssemblerOptions:
+		return options.Disk, true
+	default:
+		return nil, false
+	}
+}
+
 func tarAssembler(filename, compression string) *osbuild.Assembler {
 	return osbuild.NewTarAssembler(
 		&osbuild.TarAssemblerOptions{
@@ -591,15 +1275,43 @@ func tarAssembler(filename, compression string) *osbuild.Assembler {
 		})
 }
 
-func ostreeCommitAssembler(options distro.ImageOptions, arch distro.Arch) *osbuild.Assembler {
-	ref := options.OSTree.Ref
-	if ref == "" {
-		ref = fmt.Sprintf("rhel/8/%s/edge", arch.Name())
+func ociArchiveAssembler(filename string, arch distro.Arch) *osbuild.Assembler {
+	return osbuild.NewOCIArchiveAssembler(
+		&osbuild.OCIArchiveAssemblerOptions{
+			Architecture: ociArchitecture(arch),
+			Filename:     filename,
+		})
+}
+
+// ociArchitecture returns the name of arch in the OCI image specification,
+// which uses the names of GOARCH.
+func ociArchitecture(arch distro.Arch) string {
+	switch arch.Name() {
+	case "x86_64":
+		return "amd64"
+	case "aarch64":
+		return "arm64"
+	default:
+		return arch.Name()
 	}
+}
+
+// ostreeRef returns the ref of the commit in options, or the default ref of
+// the architecture.
+func ostreeRef(options distro.ImageOptions, arch distro.Arch) string {
+	if options.OSTree.Ref != "" {
+		return options.OSTree.Ref
+	}
+	return fmt.Sprintf("rhel/8/%s/edge", arch.Name())
+}
+
+func ostreeCommitAssembler(options distro.ImageOptions, arch distro.Arch) *osbuild.Assembler {
 	return osbuild.NewOSTreeCommitAssembler(
 		&osbuild.OSTreeCommitAssemblerOptions{
-			Ref:    ref,
-			Parent: options.OSTree.Parent,
+			Ref:          ostreeRef(options, arch),
+			Parent:       options.OSTree.Parent,
+			Sign:         ostreeSignOptions(options.OSTree),
+			StaticDeltas: options.OSTree.StaticDeltas,
 			Tar: osbuild.OSTreeCommitAssemblerTarOptions{
 				Filename: "commit.tar",
 			},
@@ -607,6 +1319,16 @@ func ostreeCommitAssembler(options distro.ImageOptions, arch distro.Arch) *osbui
 	)
 }
 
+func ostreeSignOptions(options distro.OSTreeImageOptions) *osbuild.OSTreeCommitAssemblerSignOptions {
+	if options.SignKey == "" {
+		return nil
+	}
+	return &osbuild.OSTreeCommitAssemblerSignOptions{
+		Type: options.SignType,
+		Key:  options.SignKey,
+	}
+}
+
 // New creates a new distro object, defining the supported architectures and image types
 func New() distro.Distro {
 	const GigaByte = 1024 * 1024 * 1024
@@ -667,6 +1389,42 @@ func New() distro.Distro {
 			return ostreeCommitAssembler(options, arch)
 		},
 	}
+	edgeInstallerImgTypeX86_64 := imageType{
+		name:     "rhel-edge-installer",
+		filename: "installer.iso",
+		mimeType: "application/x-iso9660-image",
+		packages: []string{
+			"anaconda",
+			"anaconda-dracut",
+			"anaconda-install-env-deps",
+			"anaconda-widgets",
+			"dracut-config-generic",
+			"dracut-network",
+			"kernel",
+			"lorax-templates-rhel",
+			"redhat-release",
+			"rpm-ostree",
+			"ostree",
+			"plymouth",
+			// x86 specific
+			"grub2-efi-x64", "grub2-efi-x64-cdboot", "grub2-pc", "shim-x64", "syslinux",
+			"efibootmgr", "microcode_ctl",
+		},
+		installer: true,
+		assembler: func(uefi bool, options distro.ImageOptions, arch distro.Arch) *osbuild.Assembler {
+			return osbuild.NewBootISOAssembler(
+				&osbuild.BootISOAssemblerOptions{
+					Filename: "installer.iso",
+					Product: osbuild.BootISOProduct{
+						Name:    "Red Hat Enterprise Linux",
+						Version: "8",
+					},
+					ISOLabel:  "RHEL-8-BaseOS-" + arch.Name(),
+					Kickstart: "/osbuild.ks",
+				},
+			)
+		},
+	}
 	edgeImgTypeAarch64 := imageType{
 		name:     "rhel-edge-commit",
 		filename: "commit.tar",
@@ -876,6 +1634,7 @@ func New() distro.Distro {
 		},
 		kernelOptions: "console=ttyS0 console=ttyS0,115200n8 no_timer_check crashkernel=auto net.ifnames=0",
 		bootable:      true,
+		hybridBoot:    true,
 		defaultSize:   4 * GigaByte,
 		assembler: func(uefi bool, options distro.ImageOptions, arch distro.Arch) *osbuild.Assembler {
 			return qemuAssembler("qcow2", "disk.qcow2", uefi, options, arch)
@@ -903,6 +1662,7 @@ func New() distro.Distro {
 		},
 		kernelOptions: "ro net.ifnames=0",
 		bootable:      true,
+		hybridBoot:    true,
 		defaultSize:   4 * GigaByte,
 		assembler: func(uefi bool, options distro.ImageOptions, arch distro.Arch) *osbuild.Assembler {
 			return qemuAssembler("qcow2", "disk.qcow2", uefi, options, arch)
@@ -924,6 +1684,73 @@ func New() distro.Distro {
 		},
 	}
 
+	containerImgType := imageType{
+		name:     "container",
+		filename: "container.tar",
+		mimeType: "application/x-tar",
+		packages: []string{
+			"redhat-release",
+			"bash",
+			"coreutils-single",
+			"glibc-minimal-langpack",
+			"crypto-policies-scripts",
+			"findutils",
+			"gzip",
+			"rootfiles",
+			"shadow-utils",
+			"tar",
+			"vim-minimal",
+			"yum",
+		},
+		excludedPackages: []string{
+			"dracut",
+			"grub2-common",
+			"grubby",
+			"kernel",
+			"linux-firmware",
+			"os-prober",
+			"systemd-udev",
+		},
+		bootable:  false,
+		container: true,
+		assembler: func(uefi bool, options distro.ImageOptions, arch distro.Arch) *osbuild.Assembler {
+			return ociArchiveAssembler("container.tar", arch)
+		},
+	}
+
+	pxeImgType := imageType{
+		name:      "pxe",
+		filename:  "pxeboot.ipxe",
+		filenames: []string{"pxeboot.ipxe", "vmlinuz", "initrd.img", "rootfs.squashfs"},
+		mimeType:  "application/x-tar",
+		packages: []string{
+			"@core",
+			"kernel",
+			"dracut-config-generic",
+			"dracut-live",
+			"dracut-network",
+			"selinux-policy-targeted",
+			"langpacks-en",
+		},
+		excludedPackages: []string{
+			"dracut-config-rescue",
+
+			// TODO setfiles failes because of usr/sbin/timedatex. Exlude until
+			// https://errata.devel.redhat.com/advisory/47339 lands
+			"timedatex",
+		},
+		kernelOptions: "rd.live.image",
+		pxe:           true,
+		assembler: func(uefi bool, options distro.ImageOptions, arch distro.Arch) *osbuild.Assembler {
+			return osbuild.NewPXEAssembler(&osbuild.PXEAssemblerOptions{
+				Kernel:     "vmlinuz",
+				Initramfs:  "initrd.img",
+				RootFS:     "rootfs.squashfs",
+				IPXEScript: "pxeboot.ipxe",
+			})
+		},
+	}
+
 	vhdImgType := imageType{
 		name:     "vhd",
 		filename: "disk.vhd",
@@ -992,6 +1819,34 @@ func New() distro.Distro {
 		},
 	}
 
+	ovaImgType := imageType{
+		name:     "ova",
+		filename: "image.ova",
+		mimeType: "application/ovf",
+		packages: []string{
+			"@core",
+			"chrony",
+			"firewalld",
+			"kernel",
+			"langpacks-en",
+			"open-vm-tools",
+			"selinux-policy-targeted",
+		},
+		excludedPackages: []string{
+			"dracut-config-rescue",
+
+			// TODO setfiles failes because of usr/sbin/timedatex. Exlude until
+			// https://errata.devel.redhat.com/advisory/47339 lands
+			"timedatex",
+		},
+		kernelOptions: "ro net.ifnames=0",
+		bootable:      true,
+		defaultSize:   4 * GigaByte,
+		assembler: func(uefi bool, options distro.ImageOptions, arch distro.Arch) *osbuild.Assembler {
+			return ovaAssembler("image.ova", uefi, options, arch)
+		},
+	}
+
 	r := distribution{
 		imageTypes: map[string]imageType{},
 		buildPackages: []string{
@@ -1019,13 +1874,22 @@ func New() distro.Distro {
 		buildPackages: []string{
 			"grub2-pc",
 		},
+		uefiBootloaderPackages: []string{
+			"efibootmgr",
+			"grub2-efi-x64",
+			"shim-x64",
+		},
 		legacy: "i386-pc",
 	}
 	x8664.setImageTypes(
 		amiImgType,
+		containerImgType,
 		edgeImgTypeX86_64,
+		edgeInstallerImgTypeX86_64,
 		qcow2ImageType,
 		openstackImgType,
+		ovaImgType,
+		pxeImgType,
 		tarImgType,
 		vhdImgType,
 		vmdkImgType,
@@ -1045,9 +1909,11 @@ func New() distro.Distro {
 	}
 	aarch64.setImageTypes(
 		amiImgType,
+		containerImgType,
 		edgeImgTypeAarch64,
 		qcow2ImageType,
 		openstackImgType,
+		pxeImgType,
 		tarImgType,
 	)
 
@@ -1068,6 +1934,7 @@ func New() distro.Distro {
 		uefi:   false,
 	}
 	ppc64le.setImageTypes(
+		containerImgType,
 		qcow2ImageType,
 		tarImgType,
 	)
@@ -1082,6 +1949,7 @@ func New() distro.Distro {
 		uefi: false,
 	}
 	s390x.setImageTypes(
+		containerImgType,
 		tarImgType,
 		qcow2ImageType,
 	)
@@ -1,12 +1,16 @@
 package rhel8_test
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/osbuild/osbuild-composer/internal/blueprint"
+	"github.com/osbuild/osbuild-composer/internal/distro"
 	"github.com/osbuild/osbuild-composer/internal/distro/distro_test_common"
 	"github.com/osbuild/osbuild-composer/internal/distro/rhel8"
+	"github.com/osbuild/osbuild-composer/internal/osbuild"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestFilenameFromType(t *testing.T) {
@@ -26,18 +30,42 @@ func TestFilenameFromType(t *testing.T) {
 			want:  "image.raw",
 			want1: "application/octet-stream",
 		},
+		{
+			name:  "container",
+			args:  args{"container"},
+			want:  "container.tar",
+			want1: "application/x-tar",
+		},
 		{
 			name:  "openstack",
 			args:  args{"openstack"},
 			want:  "disk.qcow2",
 			want1: "application/x-qemu-disk",
 		},
+		{
+			name:  "ova",
+			args:  args{"ova"},
+			want:  "image.ova",
+			want1: "application/ovf",
+		},
+		{
+			name:  "pxe",
+			args:  args{"pxe"},
+			want:  "pxeboot.ipxe",
+			want1: "application/x-tar",
+		},
 		{
 			name:  "qcow2",
 			args:  args{"qcow2"},
 			want:  "disk.qcow2",
 			want1: "application/x-qemu-disk",
 		},
+		{
+			name:  "rhel-edge-installer",
+			args:  args{"rhel-edge-installer"},
+			want:  "installer.iso",
+			want1: "application/x-iso9660-image",
+		},
 		{
 			name:  "tar",
 			args:  args{"tar"},
@@ -125,7 +153,7 @@ func TestImageType_BuildPackages(t *testing.T) {
 			if assert.NoErrorf(t, err, "d.GetArch(%v) returned err = %v; expected nil", archLabel, err) {
 				continue
 			}
-			assert.ElementsMatch(t, buildPackages[archLabel], itStruct.BuildPackages())
+			assert.ElementsMatch(t, buildPackages[archLabel], itStruct.BuildPackages(blueprint.Blueprint{}))
 		}
 	}
 }
@@ -140,8 +168,12 @@ func TestImageType_Name(t *testing.T) {
 			arch: "x86_64",
 			imgNames: []string{
 				"ami",
+				"container",
 				"qcow2",
 				"openstack",
+				"ova",
+				"pxe",
+				"rhel-edge-installer",
 				"tar",
 				"vhd",
 				"vmdk",
@@ -151,14 +183,17 @@ func TestImageType_Name(t *testing.T) {
 			arch: "aarch64",
 			imgNames: []string{
 				"ami",
+				"container",
 				"qcow2",
 				"openstack",
+				"pxe",
 				"tar",
 			},
 		},
 		{
 			arch: "ppc64le",
 			imgNames: []string{
+				"container",
 				"qcow2",
 				"tar",
 			},
@@ -166,6 +201,7 @@ func TestImageType_Name(t *testing.T) {
 		{
 			arch: "s390x",
 			imgNames: []string{
+				"container",
 				"tar",
 			},
 		},
@@ -226,6 +262,443 @@ func TestImageType_Size(t *testing.T) {
 	}
 }
 
+func TestImageType_HybridBoot(t *testing.T) {
+	arch, err := rhel8.New().GetArch("x86_64")
+	require.NoError(t, err)
+
+	for _, name := range []string{"qcow2", "openstack"} {
+		imageType, err := arch.GetImageType(name)
+		require.NoError(t, err)
+		m, err := imageType.Manifest(&blueprint.Customizations{}, distro.ImageOptions{Size: imageType.Size(0)}, nil, nil, nil)
+		require.NoError(t, err)
+
+		var manifest osbuild.Manifest
+		require.NoError(t, json.Unmarshal(m, &manifest))
+		options := manifest.Pipeline.Assembler.Options.(*osbuild.QEMUAssemblerOptions)
+		assert.Equal(t, "gpt", options.PTType, name)
+		assert.Equal(t, &osbuild.QEMUBootloader{Type: "grub2", Platform: "i386-pc"}, options.Bootloader, name)
+		require.Len(t, options.Partitions, 3, name)
+		assert.Equal(t, "21686148-6449-6E6F-744E-656564454649", options.Partitions[0].Type, name)
+		assert.Equal(t, "/boot/efi", options.Partitions[1].Filesystem.Mountpoint, name)
+		assert.Equal(t, "/", options.Partitions[2].Filesystem.Mountpoint, name)
+
+		for _, stage := range manifest.Pipeline.Stages {
+			if options, ok := stage.Options.(*osbuild.GRUB2StageOptions); ok {
+				assert.Equal(t, "i386-pc", options.Legacy, name)
+				assert.Equal(t, &osbuild.GRUB2UEFI{Vendor: "redhat"}, options.UEFI, name)
+			}
+		}
+	}
+
+	// other image types keep booting with BIOS only
+	vhd, err := arch.GetImageType("vhd")
+	require.NoError(t, err)
+	m, err := vhd.Manifest(&blueprint.Customizations{}, distro.ImageOptions{Size: vhd.Size(0)}, nil, nil, nil)
+	require.NoError(t, err)
+	var manifest osbuild.Manifest
+	require.NoError(t, json.Unmarshal(m, &manifest))
+	assert.Equal(t, "mbr", manifest.Pipeline.Assembler.Options.(*osbuild.QEMUAssemblerOptions).PTType)
+}
+
+func TestImageType_Installer(t *testing.T) {
+	arch, err := rhel8.New().GetArch("x86_64")
+	require.NoError(t, err)
+	imageType, err := arch.GetImageType("rhel-edge-installer")
+	require.NoError(t, err)
+
+	// blueprint packages belong into the commit, not into the installer
+	bp := blueprint.Blueprint{Packages: []blueprint.Package{{Name: "tmux"}}}
+	packages, _ := imageType.Packages(bp)
+	assert.NotContains(t, packages, "tmux")
+	assert.Contains(t, packages, "anaconda")
+
+	options := distro.ImageOptions{
+		OSTree: distro.OSTreeImageOptions{
+			Ref: "test/edge",
+		},
+	}
+	m, err := imageType.Manifest(nil, options, nil, nil, nil)
+	require.NoError(t, err)
+
+	var manifest osbuild.Manifest
+	require.NoError(t, json.Unmarshal(m, &manifest))
+	require.Len(t, manifest.Pipeline.Stages, 3)
+	assert.Equal(t, &osbuild.TarExtractStageOptions{
+		Source: osbuild.InputChecksum("commit.tar"),
+		Path:   "/ostree",
+	}, manifest.Pipeline.Stages[1].Options)
+	assert.Equal(t, &osbuild.KickstartStageOptions{
+		Path: "/osbuild.ks",
+		OSTree: &osbuild.KickstartOSTree{
+			OSName: "rhel-edge",
+			URL:    "file:///ostree/repo",
+			Ref:    "test/edge",
+		},
+	}, manifest.Pipeline.Stages[2].Options)
+
+	assembler := manifest.Pipeline.Assembler.Options.(*osbuild.BootISOAssemblerOptions)
+	assert.Equal(t, "installer.iso", assembler.Filename)
+	assert.Equal(t, "/osbuild.ks", assembler.Kickstart)
+}
+
+func TestImageType_OSTreeRef(t *testing.T) {
+	arch, err := rhel8.New().GetArch("x86_64")
+	require.NoError(t, err)
+
+	commit, err := arch.GetImageType("rhel-edge-commit")
+	require.NoError(t, err)
+	assert.Equal(t, "rhel/8/x86_64/edge", commit.OSTreeRef())
+
+	qcow2, err := arch.GetImageType("qcow2")
+	require.NoError(t, err)
+	assert.Empty(t, qcow2.OSTreeRef())
+}
+
+func TestImageType_OSTreeParent(t *testing.T) {
+	arch, err := rhel8.New().GetArch("x86_64")
+	require.NoError(t, err)
+	imageType, err := arch.GetImageType("rhel-edge-commit")
+	require.NoError(t, err)
+
+	const parent = "02604b2da6e954bd34b8b82a835e5a77d2b60ffa4d1b2f6a7a4f7b7c4c4c4c4c"
+	options := distro.ImageOptions{
+		OSTree: distro.OSTreeImageOptions{
+			Ref:    "test/edge",
+			Parent: parent,
+			URL:    "http://example.com/repo",
+		},
+	}
+	m, err := imageType.Manifest(nil, options, nil, nil, nil)
+	require.NoError(t, err)
+
+	var manifest osbuild.Manifest
+	require.NoError(t, json.Unmarshal(m, &manifest))
+	assert.Equal(t, &osbuild.OSTreeSource{
+		Items: map[string]osbuild.OSTreeSourceItem{
+			parent: {Remote: osbuild.OSTreeRemote{URL: "http://example.com/repo"}},
+		},
+	}, manifest.Sources["org.osbuild.ostree"])
+	assert.Equal(t, parent, manifest.Pipeline.Assembler.Options.(*osbuild.OSTreeCommitAssemblerOptions).Parent)
+	assert.Nil(t, manifest.Pipeline.Assembler.Options.(*osbuild.OSTreeCommitAssemblerOptions).Sign)
+
+	// the key is referenced by its name only
+	options.OSTree.SignType = "ed25519"
+	options.OSTree.SignKey = "edge"
+	options.OSTree.StaticDeltas = true
+	m, err = imageType.Manifest(nil, options, nil, nil, nil)
+	require.NoError(t, err)
+	manifest = osbuild.Manifest{}
+	require.NoError(t, json.Unmarshal(m, &manifest))
+	assembler := manifest.Pipeline.Assembler.Options.(*osbuild.OSTreeCommitAssemblerOptions)
+	assert.Equal(t, &osbuild.OSTreeCommitAssemblerSignOptions{Type: "ed25519", Key: "edge"}, assembler.Sign)
+	assert.True(t, assembler.StaticDeltas)
+
+	// without a repository, the parent has to be in the local repository
+	options.OSTree.URL = ""
+	m, err = imageType.Manifest(nil, options, nil, nil, nil)
+	require.NoError(t, err)
+	manifest = osbuild.Manifest{}
+	require.NoError(t, json.Unmarshal(m, &manifest))
+	assert.NotContains(t, manifest.Sources, "org.osbuild.ostree")
+}
+
+func TestImageType_Swap(t *testing.T) {
+	const gigaByte = 1024 * 1024 * 1024
+
+	arch, err := rhel8.New().GetArch("x86_64")
+	require.NoError(t, err)
+
+	qcow2, err := arch.GetImageType("qcow2")
+	require.NoError(t, err)
+	c := &blueprint.Customizations{
+		Swap: &blueprint.SwapCustomization{Size: 2 * gigaByte, Type: blueprint.SwapTypePartition},
+	}
+	m, err := qcow2.Manifest(c, distro.ImageOptions{Size: qcow2.Size(0)}, nil, nil, nil)
+	require.NoError(t, err)
+
+	var manifest osbuild.Manifest
+	require.NoError(t, json.Unmarshal(m, &manifest))
+	options := manifest.Pipeline.Assembler.Options.(*osbuild.QEMUAssemblerOptions)
+	assert.Equal(t, qcow2.Size(0)+2*gigaByte, options.Size)
+	require.Len(t, options.Partitions, 4)
+	assert.Equal(t, "swap", options.Partitions[2].Filesystem.Type)
+	assert.Equal(t, uint64(2*gigaByte/512), options.Partitions[2].Size)
+	assert.Equal(t, "/", options.Partitions[3].Filesystem.Mountpoint)
+
+	// a swap file is rounded up to whole MiB, as is the image
+	c = &blueprint.Customizations{
+		Swap: &blueprint.SwapCustomization{Size: gigaByte + 1},
+	}
+	m, err = qcow2.Manifest(c, distro.ImageOptions{Size: qcow2.Size(0)}, nil, nil, nil)
+	require.NoError(t, err)
+	manifest = osbuild.Manifest{}
+	require.NoError(t, json.Unmarshal(m, &manifest))
+	options = manifest.Pipeline.Assembler.Options.(*osbuild.QEMUAssemblerOptions)
+	assert.Equal(t, qcow2.Size(0)+gigaByte+1024*1024, options.Size)
+	for _, stage := range manifest.Pipeline.Stages {
+		if swapfile, ok := stage.Options.(*osbuild.SwapfileStageOptions); ok {
+			assert.Equal(t, uint64(gigaByte+1024*1024), swapfile.Size)
+		}
+	}
+
+	tar, err := arch.GetImageType("tar")
+	require.NoError(t, err)
+	_, err = tar.Manifest(c, distro.ImageOptions{Size: tar.Size(0)}, nil, nil, nil)
+	assert.Error(t, err)
+}
+
+func TestImageType_Filesystems(t *testing.T) {
+	const gigaByte = 1024 * 1024 * 1024
+
+	arch, err := rhel8.New().GetArch("x86_64")
+	require.NoError(t, err)
+
+	qcow2, err := arch.GetImageType("qcow2")
+	require.NoError(t, err)
+	c := &blueprint.Customizations{
+		Filesystem: []blueprint.FilesystemCustomization{
+			{Mountpoint: "/var", Size: 2 * gigaByte},
+		},
+	}
+	assert.NotContains(t, qcow2.BuildPackages(blueprint.Blueprint{}), "lvm2")
+	assert.Contains(t, qcow2.BuildPackages(blueprint.Blueprint{Customizations: c}), "lvm2")
+	m, err := qcow2.Manifest(c, distro.ImageOptions{Size: qcow2.Size(0)}, nil, nil, nil)
+	require.NoError(t, err)
+
+	var manifest osbuild.Manifest
+	require.NoError(t, json.Unmarshal(m, &manifest))
+	options := manifest.Pipeline.Assembler.Options.(*osbuild.QEMUAssemblerOptions)
+	assert.Equal(t, qcow2.Size(0)+2*gigaByte+1*gigaByte, options.Size)
+	require.Len(t, options.Partitions, 4)
+	assert.Equal(t, "/boot", options.Partitions[2].Filesystem.Mountpoint)
+	vg := options.Partitions[3].LVM
+	require.NotNil(t, vg)
+	require.Len(t, vg.Volumes, 2)
+	assert.Equal(t, "var", vg.Volumes[0].Name)
+	assert.Equal(t, uint64(2*gigaByte/512), vg.Volumes[0].Size)
+	assert.Equal(t, "/", vg.Volumes[1].Filesystem.Mountpoint)
+
+	for _, stage := range manifest.Pipeline.Stages {
+		switch options := stage.Options.(type) {
+		case *osbuild.FSTabStageOptions:
+			assert.Len(t, options.FileSystems, 4)
+			assert.Equal(t, vg.Volumes[0].Filesystem.UUID, options.FileSystems[2].UUID)
+		case *osbuild.GRUB2StageOptions:
+			assert.Contains(t, options.KernelOptions, "rd.lvm.lv=rootvg/root")
+			assert.NotNil(t, options.BootFilesystemUUID)
+		}
+	}
+}
+
+func TestImageType_Encryption(t *testing.T) {
+	arch, err := rhel8.New().GetArch("x86_64")
+	require.NoError(t, err)
+
+	qcow2, err := arch.GetImageType("qcow2")
+	require.NoError(t, err)
+	c := &blueprint.Customizations{
+		Encryption: &blueprint.EncryptionCustomization{
+			Tang: []blueprint.TangCustomization{
+				{URL: "http://tang.example.com", Thumbprint: "x8KH2NdlYVpDVzWIxFWmXYtM6ss"},
+			},
+		},
+	}
+	assert.NotContains(t, qcow2.BuildPackages(blueprint.Blueprint{}), "cryptsetup")
+	assert.Subset(t, qcow2.BuildPackages(blueprint.Blueprint{Customizations: c}), []string{"clevis-luks", "cryptsetup"})
+
+	m, err := qcow2.Manifest(c, distro.ImageOptions{Size: qcow2.Size(0)}, nil, nil, nil)
+	require.NoError(t, err)
+
+	var manifest osbuild.Manifest
+	require.NoError(t, json.Unmarshal(m, &manifest))
+	options := manifest.Pipeline.Assembler.Options.(*osbuild.QEMUAssemblerOptions)
+	require.Len(t, options.Partitions, 4)
+	assert.Equal(t, "/boot", options.Partitions[2].Filesystem.Mountpoint)
+	luks := options.Partitions[3].LUKS
+	require.NotNil(t, luks)
+	require.NotNil(t, luks.Clevis)
+	assert.Equal(t, "null", luks.Clevis.Pin)
+
+	var clevis *osbuild.ClevisLUKSStageOptions
+	for _, stage := range manifest.Pipeline.Stages {
+		switch options := stage.Options.(type) {
+		case *osbuild.ClevisLUKSStageOptions:
+			clevis = options
+		case *osbuild.DracutConfStageOptions:
+			assert.Equal(t, []string{"crypt", "clevis", "network"}, options.Config.AddModules)
+		case *osbuild.GRUB2StageOptions:
+			assert.Contains(t, options.KernelOptions, "rd.luks.uuid="+luks.UUID)
+			assert.Contains(t, options.KernelOptions, "rd.neednet=1")
+		}
+	}
+	require.NotNil(t, clevis)
+	assert.Equal(t, luks.UUID, clevis.UUID)
+	assert.Equal(t, "sss", clevis.Pin)
+
+	// the manifest is reproducible and contains no passphrase
+	assert.Empty(t, luks.Passphrase)
+	m2, err := qcow2.Manifest(c, distro.ImageOptions{Size: qcow2.Size(0)}, nil, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, m, m2)
+
+	tar, err := arch.GetImageType("tar")
+	require.NoError(t, err)
+	_, err = tar.Manifest(c, distro.ImageOptions{Size: tar.Size(0)}, nil, nil, nil)
+	assert.Error(t, err)
+}
+
+func TestImageType_Container(t *testing.T) {
+	arch, err := rhel8.New().GetArch("aarch64")
+	require.NoError(t, err)
+
+	container, err := arch.GetImageType("container")
+	require.NoError(t, err)
+
+	packages, excludedPackages := container.Packages(blueprint.Blueprint{})
+	assert.NotContains(t, packages, "kernel")
+	assert.Contains(t, excludedPackages, "kernel")
+
+	c := &blueprint.Customizations{
+		Container: &blueprint.ContainerCustomization{
+			Entrypoint: []string{"/usr/bin/httpd"},
+			Cmd:        []string{"-DFOREGROUND"},
+			Env:        []string{"LANG=C.UTF-8"},
+			Labels:     map[string]string{"vendor": "Example"},
+		},
+	}
+	m, err := container.Manifest(c, distro.ImageOptions{}, nil, nil, nil)
+	require.NoError(t, err)
+
+	var manifest osbuild.Manifest
+	require.NoError(t, json.Unmarshal(m, &manifest))
+	assert.Equal(t, "org.osbuild.oci-archive", manifest.Pipeline.Assembler.Name)
+	options := manifest.Pipeline.Assembler.Options.(*osbuild.OCIArchiveAssemblerOptions)
+	assert.Equal(t, "arm64", options.Architecture)
+	assert.Equal(t, "container.tar", options.Filename)
+	require.NotNil(t, options.Config)
+	assert.Equal(t, c.Container.Entrypoint, options.Config.Entrypoint)
+	assert.Equal(t, c.Container.Cmd, options.Config.Cmd)
+	assert.Equal(t, c.Container.Env, options.Config.Env)
+	assert.Equal(t, c.Container.Labels, options.Config.Labels)
+
+	for _, stage := range manifest.Pipeline.Stages {
+		assert.NotEqual(t, "org.osbuild.selinux", stage.Name)
+	}
+
+	tar, err := arch.GetImageType("tar")
+	require.NoError(t, err)
+	_, err = tar.Manifest(c, distro.ImageOptions{}, nil, nil, nil)
+	assert.Error(t, err)
+}
+
+func TestImageType_OVA(t *testing.T) {
+	const gigaByte = 1024 * 1024 * 1024
+
+	arch, err := rhel8.New().GetArch("x86_64")
+	require.NoError(t, err)
+
+	ova, err := arch.GetImageType("ova")
+	require.NoError(t, err)
+
+	m, err := ova.Manifest(nil, distro.ImageOptions{Size: ova.Size(0)}, nil, nil, nil)
+	require.NoError(t, err)
+
+	var manifest osbuild.Manifest
+	require.NoError(t, json.Unmarshal(m, &manifest))
+	assert.Equal(t, "org.osbuild.ova", manifest.Pipeline.Assembler.Name)
+	options := manifest.Pipeline.Assembler.Options.(*osbuild.OVAAssemblerOptions)
+	assert.Equal(t, "image.ova", options.Filename)
+	require.NotNil(t, options.Disk)
+	assert.Equal(t, "vmdk", options.Disk.Format)
+	assert.Equal(t, "streamOptimized", options.Disk.Subformat)
+	assert.Equal(t, osbuild.OVFDescriptor{
+		Name:    "Red Hat Enterprise Linux 8",
+		OSType:  "rhel8_64Guest",
+		CPUs:    2,
+		Memory:  4096,
+		Network: "VM Network",
+		NICType: "vmxnet3",
+	}, options.Descriptor)
+
+	// the descriptor can be changed and the disk customized like the
+	// disks of other image types
+	c := &blueprint.Customizations{
+		Filesystem: []blueprint.FilesystemCustomization{
+			{Mountpoint: "/var", Size: 2 * gigaByte},
+		},
+	}
+	imageOptions := distro.ImageOptions{
+		Size: ova.Size(0),
+		OVF: distro.OVFImageOptions{
+			CPUs:    4,
+			Memory:  8192,
+			Network: "Provisioning",
+			NICType: "e1000e",
+		},
+	}
+	m, err = ova.Manifest(c, imageOptions, nil, nil, nil)
+	require.NoError(t, err)
+
+	manifest = osbuild.Manifest{}
+	require.NoError(t, json.Unmarshal(m, &manifest))
+	options = manifest.Pipeline.Assembler.Options.(*osbuild.OVAAssemblerOptions)
+	assert.Equal(t, uint(4), options.Descriptor.CPUs)
+	assert.Equal(t, uint64(8192), options.Descriptor.Memory)
+	assert.Equal(t, "Provisioning", options.Descriptor.Network)
+	assert.Equal(t, "e1000e", options.Descriptor.NICType)
+	require.Len(t, options.Disk.Partitions, 2)
+	assert.NotNil(t, options.Disk.Partitions[1].LVM)
+
+	vmdk, err := arch.GetImageType("vmdk")
+	require.NoError(t, err)
+	m, err = vmdk.Manifest(nil, distro.ImageOptions{Size: vmdk.Size(0)}, nil, nil, nil)
+	require.NoError(t, err)
+
+	manifest = osbuild.Manifest{}
+	require.NoError(t, json.Unmarshal(m, &manifest))
+	assert.Equal(t, "streamOptimized", manifest.Pipeline.Assembler.Options.(*osbuild.QEMUAssemblerOptions).Subformat)
+}
+
+func TestImageType_PXE(t *testing.T) {
+	arch, err := rhel8.New().GetArch("x86_64")
+	require.NoError(t, err)
+
+	pxe, err := arch.GetImageType("pxe")
+	require.NoError(t, err)
+	assert.Equal(t, "pxeboot.ipxe", pxe.Filename())
+	assert.Equal(t, []string{"pxeboot.ipxe", "vmlinuz", "initrd.img", "rootfs.squashfs"}, pxe.Filenames())
+	assert.Contains(t, pxe.BuildPackages(blueprint.Blueprint{}), "squashfs-tools")
+
+	c := &blueprint.Customizations{
+		Kernel: &blueprint.KernelCustomization{Append: "console=ttyS0"},
+	}
+	m, err := pxe.Manifest(c, distro.ImageOptions{}, nil, nil, nil)
+	require.NoError(t, err)
+
+	var manifest osbuild.Manifest
+	require.NoError(t, json.Unmarshal(m, &manifest))
+	assert.Equal(t, "org.osbuild.pxe", manifest.Pipeline.Assembler.Name)
+	options := manifest.Pipeline.Assembler.Options.(*osbuild.PXEAssemblerOptions)
+	assert.Equal(t, "vmlinuz", options.Kernel)
+	assert.Equal(t, "initrd.img", options.Initramfs)
+	assert.Equal(t, "rootfs.squashfs", options.RootFS)
+	assert.Equal(t, "pxeboot.ipxe", options.IPXEScript)
+	assert.Equal(t, "rd.live.image console=ttyS0", options.KernelOptions)
+
+	var dracutConf *osbuild.DracutConfStageOptions
+	for _, stage := range manifest.Pipeline.Stages {
+		if stage.Name == "org.osbuild.dracut.conf" {
+			dracutConf = stage.Options.(*osbuild.DracutConfStageOptions)
+		}
+	}
+	require.NotNil(t, dracutConf)
+	assert.Equal(t, []string{"dmsquash-live", "livenet"}, dracutConf.Config.AddModules)
+
+	qcow2, err := arch.GetImageType("qcow2")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"disk.qcow2"}, qcow2.Filenames())
+}
+
 func TestImageType_BasePackages(t *testing.T) {
 	pkgMaps := []struct {
 		name               string
@@ -320,6 +793,9 @@ func TestImageType_BasePackages(t *testing.T) {
 			bootloaderPackages: []string{
 				"dracut-config-generic",
 				"grub2-pc",
+				"efibootmgr",
+				"grub2-efi-x64",
+				"shim-x64",
 			},
 			excludedPackages: []string{
 				"dracut-config-rescue",
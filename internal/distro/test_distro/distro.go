@@ -59,6 +59,14 @@ func (t *TestImageType) Filename() string {
 	return "test.img"
 }
 
+func (t *TestImageType) Filenames() []string {
+	return []string{t.Filename()}
+}
+
+func (t *TestImageType) OSTreeRef() string {
+	return ""
+}
+
 func (t *TestImageType) MIMEType() string {
 	return "application/x-test"
 }
@@ -71,7 +79,7 @@ func (t *TestImageType) Packages(bp blueprint.Blueprint) ([]string, []string) {
 	return nil, nil
 }
 
-func (t *TestImageType) BuildPackages() []string {
+func (t *TestImageType) BuildPackages(bp blueprint.Blueprint) []string {
 	return nil
 }
 
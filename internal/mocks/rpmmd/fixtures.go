@@ -59,25 +59,28 @@ func createBaseWorkersFixture() *worker.Server {
 func createBaseDepsolveFixture() []rpmmd.PackageSpec {
 	return []rpmmd.PackageSpec{
 		{
-			Name:    "dep-package3",
-			Epoch:   7,
-			Version: "3.0.3",
-			Release: "1.fc30",
-			Arch:    "x86_64",
+			Name:        "dep-package3",
+			Epoch:       7,
+			Version:     "3.0.3",
+			Release:     "1.fc30",
+			Arch:        "x86_64",
+			InstallSize: 3 * 1024 * 1024,
 		},
 		{
-			Name:    "dep-package1",
-			Epoch:   0,
-			Version: "1.33",
-			Release: "2.fc30",
-			Arch:    "x86_64",
+			Name:        "dep-package1",
+			Epoch:       0,
+			Version:     "1.33",
+			Release:     "2.fc30",
+			Arch:        "x86_64",
+			InstallSize: 1024 * 1024,
 		},
 		{
-			Name:    "dep-package2",
-			Epoch:   0,
-			Version: "2.9",
-			Release: "1.fc30",
-			Arch:    "x86_64",
+			Name:        "dep-package2",
+			Epoch:       0,
+			Version:     "2.9",
+			Release:     "1.fc30",
+			Arch:        "x86_64",
+			InstallSize: 2 * 1024 * 1024,
 		},
 	}
 }
@@ -32,8 +32,18 @@ func (assembler *Assembler) UnmarshalJSON(data []byte) error {
 	}
 	var options AssemblerOptions
 	switch rawAssembler.Name {
+	case "org.osbuild.bootiso":
+		options = new(BootISOAssemblerOptions)
+	case "org.osbuild.live-iso":
+		options = new(LiveISOAssemblerOptions)
+	case "org.osbuild.oci-archive":
+		options = new(OCIArchiveAssemblerOptions)
+	case "org.osbuild.ova":
+		options = new(OVAAssemblerOptions)
 	case "org.osbuild.ostree.commit":
 		options = new(OSTreeCommitAssemblerOptions)
+	case "org.osbuild.pxe":
+		options = new(PXEAssemblerOptions)
 	case "org.osbuild.qemu":
 		options = new(QEMUAssemblerOptions)
 	case "org.osbuild.rawfs":
@@ -37,6 +37,144 @@ func TestAssembler_UnmarshalJSON(t *testing.T) {
 			data:          []byte(`{"options":{"bar":null}}`),
 			errorExpected: true,
 		},
+		{
+			name: "bootiso assembler empty",
+			assembler: Assembler{
+				Name:    "org.osbuild.bootiso",
+				Options: &BootISOAssemblerOptions{},
+			},
+			data: []byte(`{"name":"org.osbuild.bootiso","options":{"filename":"","product":{"name":"","version":""},"isolabel":""}}`),
+		},
+		{
+			name: "bootiso assembler full",
+			assembler: Assembler{
+				Name: "org.osbuild.bootiso",
+				Options: &BootISOAssemblerOptions{
+					Filename: "installer.iso",
+					Product: BootISOProduct{
+						Name:    "Red Hat Enterprise Linux",
+						Version: "8.3",
+					},
+					ISOLabel:  "RHEL-8-3-0-BaseOS-x86_64",
+					Kickstart: "/osbuild.ks",
+				},
+			},
+			data: []byte(`{"name":"org.osbuild.bootiso","options":{"filename":"installer.iso","product":{"name":"Red Hat Enterprise Linux","version":"8.3"},"isolabel":"RHEL-8-3-0-BaseOS-x86_64","kickstart":"/osbuild.ks"}}`),
+		},
+		{
+			name: "live-iso assembler empty",
+			assembler: Assembler{
+				Name:    "org.osbuild.live-iso",
+				Options: &LiveISOAssemblerOptions{},
+			},
+			data: []byte(`{"name":"org.osbuild.live-iso","options":{"filename":"","product":{"name":"","version":""},"isolabel":""}}`),
+		},
+		{
+			name: "live-iso assembler full",
+			assembler: Assembler{
+				Name: "org.osbuild.live-iso",
+				Options: &LiveISOAssemblerOptions{
+					Filename: "live.iso",
+					Product: BootISOProduct{
+						Name:    "Fedora",
+						Version: "33",
+					},
+					ISOLabel:      "Fedora-33-Live-x86_64",
+					KernelOptions: "rd.live.image quiet",
+				},
+			},
+			data: []byte(`{"name":"org.osbuild.live-iso","options":{"filename":"live.iso","product":{"name":"Fedora","version":"33"},"isolabel":"Fedora-33-Live-x86_64","kernel_opts":"rd.live.image quiet"}}`),
+		},
+		{
+			name: "oci-archive assembler empty",
+			assembler: Assembler{
+				Name:    "org.osbuild.oci-archive",
+				Options: &OCIArchiveAssemblerOptions{},
+			},
+			data: []byte(`{"name":"org.osbuild.oci-archive","options":{"architecture":"","filename":""}}`),
+		},
+		{
+			name: "oci-archive assembler full",
+			assembler: Assembler{
+				Name: "org.osbuild.oci-archive",
+				Options: &OCIArchiveAssemblerOptions{
+					Architecture: "amd64",
+					Filename:     "container.tar",
+					Config: &OCIArchiveConfig{
+						Entrypoint: []string{"/usr/bin/httpd"},
+						Cmd:        []string{"-DFOREGROUND"},
+						Env:        []string{"LANG=C.UTF-8"},
+						Labels:     map[string]string{"vendor": "Example"},
+					},
+				},
+			},
+			data: []byte(`{"name":"org.osbuild.oci-archive","options":{"architecture":"amd64","filename":"container.tar","config":{"Entrypoint":["/usr/bin/httpd"],"Cmd":["-DFOREGROUND"],"Env":["LANG=C.UTF-8"],"Labels":{"vendor":"Example"}}}}`),
+		},
+		{
+			name: "ova assembler empty",
+			assembler: Assembler{
+				Name:    "org.osbuild.ova",
+				Options: &OVAAssemblerOptions{},
+			},
+			data: []byte(`{"name":"org.osbuild.ova","options":{"filename":"","disk":null,"descriptor":{"name":"","cpus":0,"memory":0,"network":"","nic_type":""}}}`),
+		},
+		{
+			name: "ova assembler full",
+			assembler: Assembler{
+				Name: "org.osbuild.ova",
+				Options: &OVAAssemblerOptions{
+					Filename: "image.ova",
+					Disk: &QEMUAssemblerOptions{
+						Format:    "vmdk",
+						Subformat: "streamOptimized",
+						Filename:  "disk.vmdk",
+						Size:      2147483648,
+						PTUUID:    "0x14fc63d2",
+						PTType:    "mbr",
+						Partitions: []QEMUPartition{{
+							Start:    2048,
+							Bootable: true,
+							Filesystem: &QEMUFilesystem{
+								Type:       "xfs",
+								UUID:       "76a22bf4-f153-4541-b6c7-0332c0dfaeac",
+								Mountpoint: "/",
+							},
+						}},
+					},
+					Descriptor: OVFDescriptor{
+						Name:    "Red Hat Enterprise Linux 8",
+						OSType:  "rhel8_64Guest",
+						CPUs:    2,
+						Memory:  4096,
+						Network: "VM Network",
+						NICType: "vmxnet3",
+					},
+				},
+			},
+			data: []byte(`{"name":"org.osbuild.ova","options":{"filename":"image.ova","disk":{"format":"vmdk","subformat":"streamOptimized","filename":"disk.vmdk","size":2147483648,"ptuuid":"0x14fc63d2","pttype":"mbr","partitions":[{"start":2048,"bootable":true,"filesystem":{"type":"xfs","uuid":"76a22bf4-f153-4541-b6c7-0332c0dfaeac","mountpoint":"/"}}]},"descriptor":{"name":"Red Hat Enterprise Linux 8","os_type":"rhel8_64Guest","cpus":2,"memory":4096,"network":"VM Network","nic_type":"vmxnet3"}}}`),
+		},
+		{
+			name: "pxe assembler empty",
+			assembler: Assembler{
+				Name:    "org.osbuild.pxe",
+				Options: &PXEAssemblerOptions{},
+			},
+			data: []byte(`{"name":"org.osbuild.pxe","options":{"kernel":"","initramfs":"","rootfs":""}}`),
+		},
+		{
+			name: "pxe assembler full",
+			assembler: Assembler{
+				Name: "org.osbuild.pxe",
+				Options: &PXEAssemblerOptions{
+					Kernel:        "vmlinuz",
+					Initramfs:     "initrd.img",
+					RootFS:        "rootfs.squashfs",
+					IPXEScript:    "pxeboot.ipxe",
+					KernelOptions: "rd.live.image quiet",
+				},
+			},
+			data: []byte(`{"name":"org.osbuild.pxe","options":{"kernel":"vmlinuz","initramfs":"initrd.img","rootfs":"rootfs.squashfs","ipxe_script":"pxeboot.ipxe","kernel_opts":"rd.live.image quiet"}}`),
+		},
 		{
 			name: "qemu assembler empty",
 			assembler: Assembler{
@@ -69,6 +207,33 @@ func TestAssembler_UnmarshalJSON(t *testing.T) {
 			},
 			data: []byte(`{"name":"org.osbuild.qemu","options":{"format":"qcow2","filename":"disk.qcow2","size":2147483648,"ptuuid":"0x14fc63d2","pttype":"mbr","partitions":[{"start":2048,"bootable":true,"filesystem":{"type":"ext4","uuid":"76a22bf4-f153-4541-b6c7-0332c0dfaeac","label":"root","mountpoint":"/"}}]}}`),
 		},
+		{
+			name: "qemu assembler in a tarball",
+			assembler: Assembler{
+				Name: "org.osbuild.qemu",
+				Options: &QEMUAssemblerOptions{
+					Format:   "raw",
+					Filename: "disk.raw",
+					Size:     2147483648,
+					PTUUID:   "0x14fc63d2",
+					PTType:   "mbr",
+					Partitions: []QEMUPartition{{
+						Start:    2048,
+						Bootable: true,
+						Filesystem: &QEMUFilesystem{
+							Type:       "xfs",
+							UUID:       "76a22bf4-f153-4541-b6c7-0332c0dfaeac",
+							Mountpoint: "/",
+						},
+					}},
+					Tar: &QEMUTarOptions{
+						Filename:    "image.tar.gz",
+						Compression: "gzip",
+					},
+				},
+			},
+			data: []byte(`{"name":"org.osbuild.qemu","options":{"format":"raw","filename":"disk.raw","size":2147483648,"ptuuid":"0x14fc63d2","pttype":"mbr","partitions":[{"start":2048,"bootable":true,"filesystem":{"type":"xfs","uuid":"76a22bf4-f153-4541-b6c7-0332c0dfaeac","mountpoint":"/"}}],"tar":{"filename":"image.tar.gz","compression":"gzip"}}}`),
+		},
 		{
 			name: "tar assembler empty",
 			assembler: Assembler{
@@ -121,6 +286,25 @@ func TestAssembler_UnmarshalJSON(t *testing.T) {
 			},
 			data: []byte(`{"name":"org.osbuild.ostree.commit","options":{"ref":"foo","tar":{"filename":"foo.tar"}}}`),
 		},
+		{
+			name: "signed ostree commit assembler with static deltas",
+			assembler: Assembler{
+				Name: "org.osbuild.ostree.commit",
+				Options: &OSTreeCommitAssemblerOptions{
+					Ref:    "foo",
+					Parent: "bar",
+					Sign: &OSTreeCommitAssemblerSignOptions{
+						Type: "ed25519",
+						Key:  "edge",
+					},
+					StaticDeltas: true,
+					Tar: OSTreeCommitAssemblerTarOptions{
+						Filename: "foo.tar",
+					},
+				},
+			},
+			data: []byte(`{"name":"org.osbuild.ostree.commit","options":{"ref":"foo","parent":"bar","sign":{"type":"ed25519","key":"edge"},"static_deltas":true,"tar":{"filename":"foo.tar"}}}`),
+		},
 	}
 
 	assert := assert.New(t)
@@ -143,6 +327,51 @@ func TestAssembler_UnmarshalJSON(t *testing.T) {
 	}
 }
 
+func TestNewBootISOAssembler(t *testing.T) {
+	options := &BootISOAssemblerOptions{}
+	expectedAssembler := &Assembler{
+		Name:    "org.osbuild.bootiso",
+		Options: &BootISOAssemblerOptions{},
+	}
+	assert.Equal(t, expectedAssembler, NewBootISOAssembler(options))
+}
+
+func TestNewLiveISOAssembler(t *testing.T) {
+	options := &LiveISOAssemblerOptions{}
+	expectedAssembler := &Assembler{
+		Name:    "org.osbuild.live-iso",
+		Options: &LiveISOAssemblerOptions{},
+	}
+	assert.Equal(t, expectedAssembler, NewLiveISOAssembler(options))
+}
+
+func TestNewOCIArchiveAssembler(t *testing.T) {
+	options := &OCIArchiveAssemblerOptions{}
+	expectedAssembler := &Assembler{
+		Name:    "org.osbuild.oci-archive",
+		Options: &OCIArchiveAssemblerOptions{},
+	}
+	assert.Equal(t, expectedAssembler, NewOCIArchiveAssembler(options))
+}
+
+func TestNewOVAAssembler(t *testing.T) {
+	options := &OVAAssemblerOptions{}
+	expectedAssembler := &Assembler{
+		Name:    "org.osbuild.ova",
+		Options: &OVAAssemblerOptions{},
+	}
+	assert.Equal(t, expectedAssembler, NewOVAAssembler(options))
+}
+
+func TestNewPXEAssembler(t *testing.T) {
+	options := &PXEAssemblerOptions{}
+	expectedAssembler := &Assembler{
+		Name:    "org.osbuild.pxe",
+		Options: &PXEAssemblerOptions{},
+	}
+	assert.Equal(t, expectedAssembler, NewPXEAssembler(options))
+}
+
 func TestNewQEMUAssembler(t *testing.T) {
 	options := &QEMUAssemblerOptions{}
 	expectedAssembler := &Assembler{
@@ -152,6 +381,164 @@ func TestNewQEMUAssembler(t *testing.T) {
 	assert.Equal(t, expectedAssembler, NewQEMUAssembler(options))
 }
 
+func TestQEMUAssemblerOptions_AddSwapPartition(t *testing.T) {
+	root := QEMUPartition{
+		Start: 976896,
+		Filesystem: &QEMUFilesystem{
+			Type:       "xfs",
+			UUID:       "0bd700f8-090f-4556-b797-b340297ea1bd",
+			Mountpoint: "/",
+		},
+	}
+	esp := QEMUPartition{
+		Start: 2048,
+		Size:  972800,
+		Type:  "C12A7328-F81F-11D2-BA4B-00A0C93EC93B",
+	}
+	options := &QEMUAssemblerOptions{
+		PTType:     "gpt",
+		Partitions: []QEMUPartition{esp, root},
+	}
+
+	// 1 GiB and one byte are rounded up to the next MiB
+	options.AddSwapPartition(1024*1024*1024+1, "f1c1e9a4-7f3c-4bb4-9c2d-5a4b1e2f0c11")
+
+	movedRoot := root
+	movedRoot.Start = 976896 + 2099200
+	assert.Equal(t, []QEMUPartition{
+		esp,
+		{
+			Start: 976896,
+			Size:  2099200,
+			Type:  "0657FD6D-A4AB-43C4-84E5-0933C84B4F4F",
+			Filesystem: &QEMUFilesystem{
+				Type: "swap",
+				UUID: "f1c1e9a4-7f3c-4bb4-9c2d-5a4b1e2f0c11",
+			},
+		},
+		movedRoot,
+	}, options.Partitions)
+
+	options = &QEMUAssemblerOptions{
+		PTType:     "mbr",
+		Partitions: []QEMUPartition{root},
+	}
+	options.AddSwapPartition(1024*1024, "f1c1e9a4-7f3c-4bb4-9c2d-5a4b1e2f0c11")
+	assert.Equal(t, "82", options.Partitions[0].Type)
+	assert.Equal(t, uint64(2048), options.Partitions[0].Size)
+	assert.Equal(t, uint64(976896+2048), options.Partitions[1].Start)
+}
+
+func TestQEMUAssemblerOptions_UseLVM(t *testing.T) {
+	rootFilesystem := &QEMUFilesystem{
+		Type:       "xfs",
+		UUID:       "0bd700f8-090f-4556-b797-b340297ea1bd",
+		Mountpoint: "/",
+	}
+	bootFilesystem := &QEMUFilesystem{
+		Type:       "xfs",
+		UUID:       "8d2c1bc2-9b2c-4bd7-ae6e-4a3f7b9bd7b1",
+		Mountpoint: "/boot",
+	}
+	varVolume := QEMULogicalVolume{
+		Name: "var",
+		Size: 4194304,
+		Filesystem: &QEMUFilesystem{
+			Type:       "xfs",
+			UUID:       "4d4b4e49-2a6f-5d3f-9d62-7b0c1f4b6b3a",
+			Mountpoint: "/var",
+		},
+	}
+	options := &QEMUAssemblerOptions{
+		PTType: "mbr",
+		Partitions: []QEMUPartition{
+			{Start: 2048, Bootable: true, Filesystem: rootFilesystem},
+		},
+	}
+
+	options.UseLVM(bootFilesystem, 2097152, QEMUVolumeGroup{Name: "rootvg", Volumes: []QEMULogicalVolume{varVolume}})
+
+	assert.Equal(t, []QEMUPartition{
+		{Start: 2048, Size: 2097152, Bootable: true, Filesystem: bootFilesystem},
+		{
+			Start: 2048 + 2097152,
+			Type:  "8e",
+			LVM: &QEMUVolumeGroup{
+				Name: "rootvg",
+				Volumes: []QEMULogicalVolume{
+					varVolume,
+					{Name: "root", Filesystem: rootFilesystem},
+				},
+			},
+		},
+	}, options.Partitions)
+}
+
+func TestQEMUAssemblerOptions_EncryptLastPartition(t *testing.T) {
+	rootFilesystem := &QEMUFilesystem{
+		Type:       "xfs",
+		UUID:       "0bd700f8-090f-4556-b797-b340297ea1bd",
+		Mountpoint: "/",
+	}
+	bootFilesystem := &QEMUFilesystem{
+		Type:       "xfs",
+		UUID:       "8d2c1bc2-9b2c-4bd7-ae6e-4a3f7b9bd7b1",
+		Mountpoint: "/boot",
+	}
+	luks := QEMULUKS{
+		UUID:       "6c1a8f3e-0d2b-4f7a-9e5c-2b8d4a6f1e30",
+		Passphrase: "temporary",
+		Clevis: &QEMUClevis{
+			Pin:    "null",
+			Policy: "{}",
+		},
+	}
+	options := &QEMUAssemblerOptions{
+		PTType: "mbr",
+		Partitions: []QEMUPartition{
+			{Start: 2048, Bootable: true, Filesystem: rootFilesystem},
+		},
+	}
+
+	options.AddBootPartition(bootFilesystem, 2097152)
+	options.EncryptLastPartition(luks)
+
+	assert.Equal(t, []QEMUPartition{
+		{Start: 2048, Size: 2097152, Bootable: true, Filesystem: bootFilesystem},
+		{Start: 2048 + 2097152, Filesystem: rootFilesystem, LUKS: &luks},
+	}, options.Partitions)
+}
+
+func TestQEMUAssemblerOptions_AddBIOSBootPartition(t *testing.T) {
+	espFilesystem := &QEMUFilesystem{
+		Type:       "vfat",
+		UUID:       "46BB-8120",
+		Label:      "EFI System Partition",
+		Mountpoint: "/boot/efi",
+	}
+	rootFilesystem := &QEMUFilesystem{
+		Type:       "xfs",
+		UUID:       "0bd700f8-090f-4556-b797-b340297ea1bd",
+		Mountpoint: "/",
+	}
+	options := &QEMUAssemblerOptions{
+		PTType: "gpt",
+		Partitions: []QEMUPartition{
+			{Start: 2048, Size: 972800, Type: "C12A7328-F81F-11D2-BA4B-00A0C93EC93B", Filesystem: espFilesystem},
+			{Start: 976896, Filesystem: rootFilesystem},
+		},
+	}
+
+	options.AddBIOSBootPartition("i386-pc")
+
+	assert.Equal(t, &QEMUBootloader{Type: "grub2", Platform: "i386-pc"}, options.Bootloader)
+	assert.Equal(t, []QEMUPartition{
+		{Start: 2048, Size: 2048, Type: "21686148-6449-6E6F-744E-656564454649"},
+		{Start: 4096, Size: 972800, Type: "C12A7328-F81F-11D2-BA4B-00A0C93EC93B", Filesystem: espFilesystem},
+		{Start: 978944, Filesystem: rootFilesystem},
+	}, options.Partitions)
+}
+
 func TestNewTarAssembler(t *testing.T) {
 	options := &TarAssemblerOptions{}
 	expectedAssembler := &Assembler{
@@ -0,0 +1,31 @@
+package osbuild
+
+// BootISOAssemblerOptions describe how to assemble a tree into a bootable
+// installer ISO.
+//
+// The tree is expected to contain anaconda and the lorax templates of the
+// product. The assembler creates the installer runtime image from the tree,
+// makes it bootable with BIOS and UEFI and labels the ISO with the given
+// volume id. If Kickstart is set, the kernel command line of the ISO points
+// anaconda to that kickstart file in the tree.
+type BootISOAssemblerOptions struct {
+	Filename  string         `json:"filename"`
+	Product   BootISOProduct `json:"product"`
+	ISOLabel  string         `json:"isolabel"`
+	Kickstart string         `json:"kickstart,omitempty"`
+}
+
+type BootISOProduct struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+func (BootISOAssemblerOptions) isAssemblerOptions() {}
+
+// NewBootISOAssembler creates a new boot ISO assembler object.
+func NewBootISOAssembler(options *BootISOAssemblerOptions) *Assembler {
+	return &Assembler{
+		Name:    "org.osbuild.bootiso",
+		Options: options,
+	}
+}
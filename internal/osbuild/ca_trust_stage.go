@@ -0,0 +1,27 @@
+package osbuild
+
+// The CATrustStageOptions describe certificates to add to the system-wide
+// trust store of the tree.
+//
+// Each anchor is written to /etc/pki/ca-trust/source/anchors, after which
+// the trust store is regenerated by running update-ca-trust in the tree.
+type CATrustStageOptions struct {
+	Anchors []CATrustAnchor `json:"anchors"`
+}
+
+func (CATrustStageOptions) isStageOptions() {}
+
+// A CATrustAnchor is a PEM encoded certificate and the name of the file it
+// is written to.
+type CATrustAnchor struct {
+	Filename string `json:"filename"`
+	PEM      string `json:"pem"`
+}
+
+// NewCATrustStage creates a new CA trust Stage object.
+func NewCATrustStage(options *CATrustStageOptions) *Stage {
+	return &Stage{
+		Name:    "org.osbuild.ca-trust",
+		Options: options,
+	}
+}
@@ -0,0 +1,16 @@
+package osbuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCATrustStage(t *testing.T) {
+	expectedStage := &Stage{
+		Name:    "org.osbuild.ca-trust",
+		Options: &CATrustStageOptions{},
+	}
+	actualStage := NewCATrustStage(&CATrustStageOptions{})
+	assert.Equal(t, expectedStage, actualStage)
+}
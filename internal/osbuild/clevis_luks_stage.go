@@ -0,0 +1,24 @@
+package osbuild
+
+// The ClevisLUKSStageOptions describe how the LUKS2 device with the given
+// UUID is bound to clevis.
+//
+// The stage writes the policy and a systemd unit into the tree, which binds
+// the device to the pin on first boot, using the binding to the null pin to
+// unlock it. Afterwards, it removes the temporary passphrase and the binding
+// to the null pin.
+type ClevisLUKSStageOptions struct {
+	UUID   string `json:"uuid"`
+	Pin    string `json:"pin"`
+	Policy string `json:"policy"`
+}
+
+func (ClevisLUKSStageOptions) isStageOptions() {}
+
+// NewClevisLUKSStage creates a new clevis LUKS Stage object.
+func NewClevisLUKSStage(options *ClevisLUKSStageOptions) *Stage {
+	return &Stage{
+		Name:    "org.osbuild.clevis.luks",
+		Options: options,
+	}
+}
@@ -0,0 +1,16 @@
+package osbuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewClevisLUKSStage(t *testing.T) {
+	expectedStage := &Stage{
+		Name:    "org.osbuild.clevis.luks",
+		Options: &ClevisLUKSStageOptions{},
+	}
+	actualStage := NewClevisLUKSStage(&ClevisLUKSStageOptions{})
+	assert.Equal(t, expectedStage, actualStage)
+}
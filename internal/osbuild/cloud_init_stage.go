@@ -0,0 +1,20 @@
+package osbuild
+
+// The CloudInitStageOptions describe a cloud-init configuration file, which
+// is written as YAML to /etc/cloud/cloud.cfg.d.
+//
+// Config has the structure of cloud.cfg.
+type CloudInitStageOptions struct {
+	Filename string                 `json:"filename"`
+	Config   map[string]interface{} `json:"config"`
+}
+
+func (CloudInitStageOptions) isStageOptions() {}
+
+// NewCloudInitStage creates a new cloud-init Stage object.
+func NewCloudInitStage(options *CloudInitStageOptions) *Stage {
+	return &Stage{
+		Name:    "org.osbuild.cloud-init",
+		Options: options,
+	}
+}
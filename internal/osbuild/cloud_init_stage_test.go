@@ -0,0 +1,16 @@
+package osbuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCloudInitStage(t *testing.T) {
+	expectedStage := &Stage{
+		Name:    "org.osbuild.cloud-init",
+		Options: &CloudInitStageOptions{},
+	}
+	actualStage := NewCloudInitStage(&CloudInitStageOptions{})
+	assert.Equal(t, expectedStage, actualStage)
+}
@@ -0,0 +1,22 @@
+package osbuild
+
+// The DracutConfStageOptions describe a file written to /etc/dracut.conf.d,
+// which configures how initramfs images are created.
+type DracutConfStageOptions struct {
+	Filename string       `json:"filename"`
+	Config   DracutConfig `json:"config"`
+}
+
+func (DracutConfStageOptions) isStageOptions() {}
+
+type DracutConfig struct {
+	AddModules []string `json:"add_dracutmodules,omitempty"`
+}
+
+// NewDracutConfStage creates a new dracut.conf Stage object.
+func NewDracutConfStage(options *DracutConfStageOptions) *Stage {
+	return &Stage{
+		Name:    "org.osbuild.dracut.conf",
+		Options: options,
+	}
+}
@@ -0,0 +1,16 @@
+package osbuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDracutConfStage(t *testing.T) {
+	expectedStage := &Stage{
+		Name:    "org.osbuild.dracut.conf",
+		Options: &DracutConfStageOptions{},
+	}
+	actualStage := NewDracutConfStage(&DracutConfStageOptions{})
+	assert.Equal(t, expectedStage, actualStage)
+}
@@ -0,0 +1,18 @@
+package osbuild
+
+// The DracutStageOptions describe which initramfs images are created again,
+// to pick up changes to the dracut configuration. If no kernel versions are
+// given, the images of all kernels installed in the tree are created again.
+type DracutStageOptions struct {
+	Kernel []string `json:"kernel,omitempty"`
+}
+
+func (DracutStageOptions) isStageOptions() {}
+
+// NewDracutStage creates a new dracut Stage object.
+func NewDracutStage(options *DracutStageOptions) *Stage {
+	return &Stage{
+		Name:    "org.osbuild.dracut",
+		Options: options,
+	}
+}
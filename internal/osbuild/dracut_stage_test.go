@@ -0,0 +1,16 @@
+package osbuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDracutStage(t *testing.T) {
+	expectedStage := &Stage{
+		Name:    "org.osbuild.dracut",
+		Options: &DracutStageOptions{},
+	}
+	actualStage := NewDracutStage(&DracutStageOptions{})
+	assert.Equal(t, expectedStage, actualStage)
+}
@@ -1,9 +1,18 @@
 package osbuild
 
 type FirewallStageOptions struct {
-	Ports            []string `json:"ports,omitempty"`
-	EnabledServices  []string `json:"enabled_services,omitempty"`
-	DisabledServices []string `json:"disabled_services,omitempty"`
+	Ports            []string       `json:"ports,omitempty"`
+	EnabledServices  []string       `json:"enabled_services,omitempty"`
+	DisabledServices []string       `json:"disabled_services,omitempty"`
+	DefaultZone      string         `json:"default_zone,omitempty"`
+	Zones            []FirewallZone `json:"zones,omitempty"`
+}
+
+// A FirewallZone binds sources to a zone and enables services in it.
+type FirewallZone struct {
+	Name     string   `json:"name"`
+	Sources  []string `json:"sources,omitempty"`
+	Services []string `json:"services,omitempty"`
 }
 
 func (FirewallStageOptions) isStageOptions() {}
@@ -3,8 +3,9 @@ package osbuild
 // The FSTabStageOptions describe the content of the /etc/fstab file.
 //
 // The structure of the options follows the format of /etc/fstab, except
-// that filesystem must be identified by their UUID and ommitted fields
-// are set to their defaults (if possible).
+// that filesystem must be identified by their UUID or label, or, for swap
+// files, by their path, and ommitted fields are set to their defaults (if
+// possible).
 type FSTabStageOptions struct {
 	FileSystems []*FSTabEntry `json:"filesystems"`
 }
@@ -20,10 +21,12 @@ func NewFSTabStage(options *FSTabStageOptions) *Stage {
 }
 
 // An FSTabEntry represents one line in /etc/fstab. With the one exception
-// that the the spec field must be represented as an UUID.
+// that the the spec field must be represented as an UUID, a label or the
+// path of a device or file.
 type FSTabEntry struct {
 	UUID    string `json:"uuid,omitempty"`
 	Label   string `json:"label,omitempty"`
+	Device  string `json:"device,omitempty"`
 	VFSType string `json:"vfs_type"`
 	Path    string `json:"path,omitempty"`
 	Options string `json:"options,omitempty"`
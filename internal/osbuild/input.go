@@ -0,0 +1,71 @@
+package osbuild
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Manifests can use artifacts of other jobs as inputs. Their checksums are
+// not known when the manifest is created, so the manifest refers to an input
+// by a placeholder checksum instead, which is resolved by the worker once it
+// has downloaded the artifact.
+
+// InputChecksum returns the placeholder checksum of the input artifact name.
+func InputChecksum(name string) string {
+	return "input:" + name
+}
+
+// ResolveInput replaces the placeholder checksum of the input artifact name
+// in a manifest with the actual checksum and adds the artifact at url to the
+// org.osbuild.files source.
+func ResolveInput(manifest []byte, name, checksum, url string) ([]byte, error) {
+	placeholder, err := json.Marshal(InputChecksum(name))
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := json.Marshal(checksum)
+	if err != nil {
+		return nil, err
+	}
+	manifest = bytes.ReplaceAll(manifest, placeholder, resolved)
+
+	var m map[string]json.RawMessage
+	err = json.Unmarshal(manifest, &m)
+	if err != nil {
+		return nil, err
+	}
+
+	var sources map[string]json.RawMessage
+	if raw, exists := m["sources"]; exists {
+		err = json.Unmarshal(raw, &sources)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if sources == nil {
+		sources = make(map[string]json.RawMessage)
+	}
+
+	var files FilesSource
+	if raw, exists := sources["org.osbuild.files"]; exists {
+		err = json.Unmarshal(raw, &files)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if files.URLs == nil {
+		files.URLs = make(map[string]FileSource)
+	}
+	files.URLs[checksum] = FileSource{URL: url}
+
+	sources["org.osbuild.files"], err = json.Marshal(files)
+	if err != nil {
+		return nil, err
+	}
+	m["sources"], err = json.Marshal(sources)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(m)
+}
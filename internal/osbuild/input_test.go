@@ -0,0 +1,49 @@
+package osbuild
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveInput(t *testing.T) {
+	manifest := Manifest{
+		Sources: Sources{
+			"org.osbuild.files": &FilesSource{
+				URLs: map[string]FileSource{
+					"sha256:c5f8e0b9": {URL: "https://example.com/package.rpm"},
+				},
+			},
+		},
+		Pipeline: Pipeline{
+			Stages: []*Stage{
+				NewTarExtractStage(&TarExtractStageOptions{
+					Source: InputChecksum("commit.tar"),
+					Path:   "/ostree",
+				}),
+			},
+		},
+	}
+	data, err := json.Marshal(manifest)
+	require.NoError(t, err)
+
+	data, err = ResolveInput(data, "commit.tar", "sha256:9f3a7b21", "file:///var/tmp/inputs/commit.tar")
+	require.NoError(t, err)
+
+	var resolved Manifest
+	require.NoError(t, json.Unmarshal(data, &resolved))
+	assert.Equal(t, &FilesSource{
+		URLs: map[string]FileSource{
+			"sha256:c5f8e0b9": {URL: "https://example.com/package.rpm"},
+			"sha256:9f3a7b21": {URL: "file:///var/tmp/inputs/commit.tar"},
+		},
+	}, resolved.Sources["org.osbuild.files"])
+	assert.Equal(t, "sha256:9f3a7b21", resolved.Pipeline.Stages[0].Options.(*TarExtractStageOptions).Source)
+
+	// a manifest without any sources
+	data, err = ResolveInput([]byte(`{"pipeline":{}}`), "commit.tar", "sha256:9f3a7b21", "file:///var/tmp/inputs/commit.tar")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"sources":{"org.osbuild.files":{"urls":{"sha256:9f3a7b21":{"url":"file:///var/tmp/inputs/commit.tar"}}}},"pipeline":{}}`, string(data))
+}
@@ -0,0 +1,28 @@
+package osbuild
+
+// The KickstartStageOptions describe a kickstart file, which is written to
+// the given path in the tree. If OSTree is set, the kickstart installs that
+// OSTree commit.
+type KickstartStageOptions struct {
+	Path   string           `json:"path"`
+	OSTree *KickstartOSTree `json:"ostree,omitempty"`
+}
+
+// A KickstartOSTree is the ostreesetup command of a kickstart file, which
+// deploys the commit ref from the repository at url.
+type KickstartOSTree struct {
+	OSName string `json:"osname"`
+	URL    string `json:"url"`
+	Ref    string `json:"ref"`
+	GPG    bool   `json:"gpg"`
+}
+
+func (KickstartStageOptions) isStageOptions() {}
+
+// NewKickstartStage creates a new kickstart stage object.
+func NewKickstartStage(options *KickstartStageOptions) *Stage {
+	return &Stage{
+		Name:    "org.osbuild.kickstart",
+		Options: options,
+	}
+}
@@ -0,0 +1,16 @@
+package osbuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewKickstartStage(t *testing.T) {
+	expectedStage := &Stage{
+		Name:    "org.osbuild.kickstart",
+		Options: &KickstartStageOptions{},
+	}
+	actualStage := NewKickstartStage(&KickstartStageOptions{})
+	assert.Equal(t, expectedStage, actualStage)
+}
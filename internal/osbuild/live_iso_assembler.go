@@ -0,0 +1,26 @@
+package osbuild
+
+// LiveISOAssemblerOptions describe how to assemble a tree into a live ISO.
+//
+// The assembler compresses the tree into a squashfs root filesystem and
+// makes the ISO bootable with BIOS and UEFI, using the kernel, initramfs,
+// syslinux and grub2 EFI images of the tree. The initramfs must contain the
+// dmsquash-live dracut module, which mounts the root filesystem of the ISO
+// labeled with the given volume id. KernelOptions are appended to the kernel
+// command line of both boot loaders.
+type LiveISOAssemblerOptions struct {
+	Filename      string         `json:"filename"`
+	Product       BootISOProduct `json:"product"`
+	ISOLabel      string         `json:"isolabel"`
+	KernelOptions string         `json:"kernel_opts,omitempty"`
+}
+
+func (LiveISOAssemblerOptions) isAssemblerOptions() {}
+
+// NewLiveISOAssembler creates a new live ISO assembler object.
+func NewLiveISOAssembler(options *LiveISOAssemblerOptions) *Assembler {
+	return &Assembler{
+		Name:    "org.osbuild.live-iso",
+		Options: options,
+	}
+}
@@ -0,0 +1,25 @@
+package osbuild
+
+// The ModprobeStageOptions describe a configuration file written to
+// /etc/modprobe.d.
+type ModprobeStageOptions struct {
+	Filename string                 `json:"filename"`
+	Commands []ModprobeStageCommand `json:"commands"`
+}
+
+func (ModprobeStageOptions) isStageOptions() {}
+
+// A ModprobeStageCommand is one line of the file, e.g. a "blacklist"
+// command followed by the name of the module.
+type ModprobeStageCommand struct {
+	Command    string `json:"command"`
+	ModuleName string `json:"modulename"`
+}
+
+// NewModprobeStage creates a new modprobe.d Stage object.
+func NewModprobeStage(options *ModprobeStageOptions) *Stage {
+	return &Stage{
+		Name:    "org.osbuild.modprobe",
+		Options: options,
+	}
+}
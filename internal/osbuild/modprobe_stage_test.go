@@ -0,0 +1,16 @@
+package osbuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewModprobeStage(t *testing.T) {
+	expectedStage := &Stage{
+		Name:    "org.osbuild.modprobe",
+		Options: &ModprobeStageOptions{},
+	}
+	actualStage := NewModprobeStage(&ModprobeStageOptions{})
+	assert.Equal(t, expectedStage, actualStage)
+}
@@ -0,0 +1,18 @@
+package osbuild
+
+// The ModulesLoadStageOptions describe a configuration file written to
+// /etc/modules-load.d, listing kernel modules to load at boot.
+type ModulesLoadStageOptions struct {
+	Filename string   `json:"filename"`
+	Modules  []string `json:"modules"`
+}
+
+func (ModulesLoadStageOptions) isStageOptions() {}
+
+// NewModulesLoadStage creates a new modules-load.d Stage object.
+func NewModulesLoadStage(options *ModulesLoadStageOptions) *Stage {
+	return &Stage{
+		Name:    "org.osbuild.modules-load",
+		Options: options,
+	}
+}
@@ -0,0 +1,16 @@
+package osbuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewModulesLoadStage(t *testing.T) {
+	expectedStage := &Stage{
+		Name:    "org.osbuild.modules-load",
+		Options: &ModulesLoadStageOptions{},
+	}
+	actualStage := NewModulesLoadStage(&ModulesLoadStageOptions{})
+	assert.Equal(t, expectedStage, actualStage)
+}
@@ -0,0 +1,68 @@
+package osbuild
+
+import (
+	"strconv"
+	"strings"
+)
+
+// The NMKeyfileStageOptions describe NetworkManager connection profiles,
+// which are written as keyfiles to /etc/NetworkManager/system-connections.
+type NMKeyfileStageOptions struct {
+	Connections []NMKeyfileConnection `json:"connections"`
+}
+
+func (NMKeyfileStageOptions) isStageOptions() {}
+
+// An NMKeyfileConnection is one keyfile. Settings maps the names of the
+// keyfile groups (e.g. "connection" or "ipv4") to their properties.
+type NMKeyfileConnection struct {
+	Filename string                       `json:"filename"`
+	Settings map[string]map[string]string `json:"settings"`
+}
+
+// NewNMKeyfileStage creates a new NetworkManager keyfile Stage object.
+func NewNMKeyfileStage(options *NMKeyfileStageOptions) *Stage {
+	return &Stage{
+		Name:    "org.osbuild.nm.keyfile",
+		Options: options,
+	}
+}
+
+// NewNMKeyfileConnection creates a connection profile with the mandatory
+// "connection" settings set.
+func NewNMKeyfileConnection(id string, connectionType string) NMKeyfileConnection {
+	return NMKeyfileConnection{
+		Filename: id + ".nmconnection",
+		Settings: map[string]map[string]string{
+			"connection": {
+				"id":          id,
+				"type":        connectionType,
+				"autoconnect": "true",
+			},
+		},
+	}
+}
+
+// Set sets one property of the profile.
+func (c *NMKeyfileConnection) Set(setting, key, value string) {
+	if c.Settings[setting] == nil {
+		c.Settings[setting] = map[string]string{}
+	}
+	c.Settings[setting][key] = value
+}
+
+// SetIP configures the "ipv4" or "ipv6" setting of the profile. Addresses
+// are in CIDR notation, lists are written in the keyfile format, in which
+// every element is terminated by a semicolon.
+func (c *NMKeyfileConnection) SetIP(setting, method string, addresses []string, gateway string, dns []string) {
+	c.Set(setting, "method", method)
+	for i, address := range addresses {
+		c.Set(setting, "address"+strconv.Itoa(i+1), address)
+	}
+	if gateway != "" {
+		c.Set(setting, "gateway", gateway)
+	}
+	if len(dns) > 0 {
+		c.Set(setting, "dns", strings.Join(dns, ";")+";")
+	}
+}
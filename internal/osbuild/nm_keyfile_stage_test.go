@@ -0,0 +1,46 @@
+package osbuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewNMKeyfileStage(t *testing.T) {
+	expectedStage := &Stage{
+		Name:    "org.osbuild.nm.keyfile",
+		Options: &NMKeyfileStageOptions{},
+	}
+	actualStage := NewNMKeyfileStage(&NMKeyfileStageOptions{})
+	assert.Equal(t, expectedStage, actualStage)
+}
+
+func TestNMKeyfileConnection(t *testing.T) {
+	expectedConnection := NMKeyfileConnection{
+		Filename: "eth0.nmconnection",
+		Settings: map[string]map[string]string{
+			"connection": {
+				"id":             "eth0",
+				"type":           "ethernet",
+				"autoconnect":    "true",
+				"interface-name": "eth0",
+			},
+			"ipv4": {
+				"method":   "manual",
+				"address1": "192.168.1.10/24",
+				"address2": "192.168.2.10/24",
+				"gateway":  "192.168.1.1",
+				"dns":      "192.168.1.1;192.168.1.2;",
+			},
+			"ipv6": {
+				"method": "auto",
+			},
+		},
+	}
+
+	connection := NewNMKeyfileConnection("eth0", "ethernet")
+	connection.Set("connection", "interface-name", "eth0")
+	connection.SetIP("ipv4", "manual", []string{"192.168.1.10/24", "192.168.2.10/24"}, "192.168.1.1", []string{"192.168.1.1", "192.168.1.2"})
+	connection.SetIP("ipv6", "auto", nil, "", nil)
+	assert.Equal(t, expectedConnection, connection)
+}
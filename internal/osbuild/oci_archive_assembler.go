@@ -0,0 +1,32 @@
+package osbuild
+
+// OCIArchiveAssemblerOptions describe how to assemble a tree into an OCI
+// image archive.
+//
+// The assembler stores the tree as the single layer of an image for the
+// given OCI architecture (e.g. "amd64"), with an optional config, and writes
+// the archive to the given filename.
+type OCIArchiveAssemblerOptions struct {
+	Architecture string            `json:"architecture"`
+	Filename     string            `json:"filename"`
+	Config       *OCIArchiveConfig `json:"config,omitempty"`
+}
+
+func (OCIArchiveAssemblerOptions) isAssemblerOptions() {}
+
+// OCIArchiveConfig is the execution config of an OCI image. Its keys follow
+// the OCI image specification.
+type OCIArchiveConfig struct {
+	Entrypoint []string          `json:"Entrypoint,omitempty"`
+	Cmd        []string          `json:"Cmd,omitempty"`
+	Env        []string          `json:"Env,omitempty"`
+	Labels     map[string]string `json:"Labels,omitempty"`
+}
+
+// NewOCIArchiveAssembler creates a new OCI archive Assembler object.
+func NewOCIArchiveAssembler(options *OCIArchiveAssemblerOptions) *Assembler {
+	return &Assembler{
+		Name:    "org.osbuild.oci-archive",
+		Options: options,
+	}
+}
@@ -1,10 +1,22 @@
 package osbuild
 
 // OSTreeCommitAssemblerOptions desrcibe how to assemble a tree into an OSTree commit.
+// If StaticDeltas is set, a static delta from the parent to the commit is
+// generated.
 type OSTreeCommitAssemblerOptions struct {
-	Ref    string                          `json:"ref"`
-	Parent string                          `json:"parent,omitempty"`
-	Tar    OSTreeCommitAssemblerTarOptions `json:"tar"`
+	Ref          string                            `json:"ref"`
+	Parent       string                            `json:"parent,omitempty"`
+	Sign         *OSTreeCommitAssemblerSignOptions `json:"sign,omitempty"`
+	StaticDeltas bool                              `json:"static_deltas,omitempty"`
+	Tar          OSTreeCommitAssemblerTarOptions   `json:"tar"`
+}
+
+// OSTreeCommitAssemblerSignOptions describe how to sign the commit. The key
+// is not part of the manifest, it is looked up by its name on the host
+// running osbuild.
+type OSTreeCommitAssemblerSignOptions struct {
+	Type string `json:"type"`
+	Key  string `json:"key"`
 }
 
 // OSTreeCommitAssemblerTarOptions desrcibes the output tarball
@@ -0,0 +1,19 @@
+package osbuild
+
+// The OSTreeSource specifies OSTree commits, which osbuild pulls into its
+// repository from remote repositories, for example the parent of a new
+// commit.
+type OSTreeSource struct {
+	Items map[string]OSTreeSourceItem `json:"items"`
+}
+
+// An OSTreeSourceItem is the remote repository a commit is pulled from.
+type OSTreeSourceItem struct {
+	Remote OSTreeRemote `json:"remote"`
+}
+
+type OSTreeRemote struct {
+	URL string `json:"url"`
+}
+
+func (OSTreeSource) isSource() {}
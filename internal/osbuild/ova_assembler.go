@@ -0,0 +1,37 @@
+package osbuild
+
+// OVAAssemblerOptions describe how to assemble a tree into an OVA, which
+// vSphere imports as a virtual machine in one step.
+//
+// The assembler creates the disk image like the qemu assembler does with the
+// options in Disk, which should describe a streamOptimized vmdk. It writes an
+// OVF descriptor of a virtual machine with this disk and the virtual hardware
+// in Descriptor, and packs both of them with a manifest of their checksums
+// into a tarball with the given filename.
+type OVAAssemblerOptions struct {
+	Filename   string                `json:"filename"`
+	Disk       *QEMUAssemblerOptions `json:"disk"`
+	Descriptor OVFDescriptor         `json:"descriptor"`
+}
+
+// OVFDescriptor describes the virtual machine of an OVA. Memory is given in
+// MiB. The network adapter of type NICType is connected to the network of the
+// given name. OSType is the vSphere guest identifier, e.g. rhel8_64Guest.
+type OVFDescriptor struct {
+	Name    string `json:"name"`
+	OSType  string `json:"os_type,omitempty"`
+	CPUs    uint   `json:"cpus"`
+	Memory  uint64 `json:"memory"`
+	Network string `json:"network"`
+	NICType string `json:"nic_type"`
+}
+
+func (OVAAssemblerOptions) isAssemblerOptions() {}
+
+// NewOVAAssembler creates a new OVA assembler object.
+func NewOVAAssembler(options *OVAAssemblerOptions) *Assembler {
+	return &Assembler{
+		Name:    "org.osbuild.ova",
+		Options: options,
+	}
+}
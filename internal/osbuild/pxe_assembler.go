@@ -0,0 +1,28 @@
+package osbuild
+
+// PXEAssemblerOptions describe how to assemble a tree into the files needed
+// to boot it over the network.
+//
+// The assembler copies the kernel and initramfs of the tree to the given
+// filenames and compresses the tree into a squashfs root filesystem. The
+// initramfs must contain the dmsquash-live and livenet dracut modules, which
+// fetch and mount the root filesystem. If IPXEScript is set, a sample iPXE
+// script, which boots the files from the directory of the script, is written
+// as well. KernelOptions are appended to its kernel command line.
+type PXEAssemblerOptions struct {
+	Kernel        string `json:"kernel"`
+	Initramfs     string `json:"initramfs"`
+	RootFS        string `json:"rootfs"`
+	IPXEScript    string `json:"ipxe_script,omitempty"`
+	KernelOptions string `json:"kernel_opts,omitempty"`
+}
+
+func (PXEAssemblerOptions) isAssemblerOptions() {}
+
+// NewPXEAssembler creates a new PXE assembler object.
+func NewPXEAssembler(options *PXEAssemblerOptions) *Assembler {
+	return &Assembler{
+		Name:    "org.osbuild.pxe",
+		Options: options,
+	}
+}
@@ -5,31 +5,81 @@ package osbuild
 // The assembler creates an image of the given size, adds a GRUB2 bootloader
 // and if necessary and a partition table to it with the given PTUUID
 // containing the indicated partitions. Finally, the image is converted into
-// the target format and stored with the given filename.
+// the target format and stored with the given filename. The subformat is
+// passed to qemu-img, e.g. streamOptimized for vmdk images, which vSphere
+// can import. If Tar is set, the image is packed into a tarball, like Google
+// Compute Engine expects raw disk images.
 type QEMUAssemblerOptions struct {
 	Bootloader *QEMUBootloader `json:"bootloader,omitempty"`
 	Format     string          `json:"format"`
+	Subformat  string          `json:"subformat,omitempty"`
 	Filename   string          `json:"filename"`
 	Size       uint64          `json:"size"`
 	PTUUID     string          `json:"ptuuid"`
 	PTType     string          `json:"pttype"`
 	Partitions []QEMUPartition `json:"partitions"`
+	Tar        *QEMUTarOptions `json:"tar,omitempty"`
 }
 
+// QEMUTarOptions describe the tarball, which contains the image under the
+// filename of the assembler options.
+type QEMUTarOptions struct {
+	Filename    string `json:"filename"`
+	Compression string `json:"compression,omitempty"`
+}
+
+// A QEMUPartition holds either a filesystem or, if LVM is set, an LVM
+// physical volume. If LUKS is set, either of them is created inside of a
+// LUKS2 container. Start and size are given in sectors of 512 bytes.
 type QEMUPartition struct {
-	Start      uint64          `json:"start"`
+	Start      uint64           `json:"start"`
+	Size       uint64           `json:"size,omitempty"`
+	Type       string           `json:"type,omitempty"`
+	Bootable   bool             `json:"bootable,omitempty"`
+	UUID       string           `json:"uuid,omitempty"`
+	Filesystem *QEMUFilesystem  `json:"filesystem,omitempty"`
+	LVM        *QEMUVolumeGroup `json:"lvm,omitempty"`
+	LUKS       *QEMULUKS        `json:"luks,omitempty"`
+}
+
+// A QEMULUKS describes the LUKS2 container of a partition. The passphrase is
+// meant to be temporary, without one the assembler generates a random
+// passphrase. If Clevis is set, the container is bound to the clevis pin with
+// the given JSON policy, so that it can be unlocked without the passphrase.
+type QEMULUKS struct {
+	UUID       string      `json:"uuid"`
+	Passphrase string      `json:"passphrase,omitempty"`
+	Clevis     *QEMUClevis `json:"clevis,omitempty"`
+}
+
+type QEMUClevis struct {
+	Pin    string `json:"pin"`
+	Policy string `json:"policy"`
+}
+
+// A QEMUVolumeGroup is created on the partition, which is its only physical
+// volume. The logical volumes are created in order, a volume without a size
+// fills the rest of the volume group.
+type QEMUVolumeGroup struct {
+	Name    string              `json:"name"`
+	Volumes []QEMULogicalVolume `json:"volumes"`
+}
+
+// A QEMULogicalVolume holds a filesystem. Its size is given in sectors of
+// 512 bytes.
+type QEMULogicalVolume struct {
+	Name       string          `json:"name"`
 	Size       uint64          `json:"size,omitempty"`
-	Type       string          `json:"type,omitempty"`
-	Bootable   bool            `json:"bootable,omitempty"`
-	UUID       string          `json:"uuid,omitempty"`
-	Filesystem *QEMUFilesystem `json:"filesystem,omitempty"`
+	Filesystem *QEMUFilesystem `json:"filesystem"`
 }
 
+// A QEMUFilesystem is created on a partition. Swap filesystems are not
+// mounted and have no mountpoint.
 type QEMUFilesystem struct {
 	Type       string `json:"type"`
 	UUID       string `json:"uuid"`
 	Label      string `json:"label,omitempty"`
-	Mountpoint string `json:"mountpoint"`
+	Mountpoint string `json:"mountpoint,omitempty"`
 }
 
 type QEMUBootloader struct {
@@ -39,6 +89,114 @@ type QEMUBootloader struct {
 
 func (QEMUAssemblerOptions) isAssemblerOptions() {}
 
+// UseLVM splits the last partition, which is expected to be the root
+// partition filling the rest of the image, into a boot partition of bootSize
+// sectors and an LVM physical volume holding the volume group vg. The root
+// filesystem moves to a logical volume named "root", which is created after
+// the other volumes of vg and fills the rest of it.
+func (options *QEMUAssemblerOptions) UseLVM(boot *QEMUFilesystem, bootSize uint64, vg QEMUVolumeGroup) {
+	partitionType := "8e"
+	if options.PTType == "gpt" {
+		partitionType = "E6D6D379-F507-44C2-A23C-238F2A3DF928"
+	}
+
+	options.AddBootPartition(boot, bootSize)
+
+	root := &options.Partitions[len(options.Partitions)-1]
+	vg.Volumes = append(vg.Volumes, QEMULogicalVolume{
+		Name:       "root",
+		Filesystem: root.Filesystem,
+	})
+	root.Type = partitionType
+	root.Filesystem = nil
+	root.LVM = &vg
+}
+
+// AddBootPartition inserts a boot partition of bootSize sectors in front of
+// the last partition, which is moved back accordingly. The boot partition
+// takes over the bootable flag of the last partition.
+func (options *QEMUAssemblerOptions) AddBootPartition(boot *QEMUFilesystem, bootSize uint64) {
+	last := len(options.Partitions) - 1
+	root := options.Partitions[last]
+
+	options.Partitions = append(options.Partitions[:last],
+		QEMUPartition{
+			Start:      root.Start,
+			Size:       bootSize,
+			Type:       root.Type,
+			Bootable:   root.Bootable,
+			Filesystem: boot,
+		},
+		QEMUPartition{
+			Start:      root.Start + bootSize,
+			Type:       root.Type,
+			UUID:       root.UUID,
+			Filesystem: root.Filesystem,
+		},
+	)
+}
+
+// EncryptLastPartition puts the content of the last partition into a LUKS2
+// container. The boot filesystem must be on a separate partition, see
+// AddBootPartition.
+func (options *QEMUAssemblerOptions) EncryptLastPartition(luks QEMULUKS) {
+	options.Partitions[len(options.Partitions)-1].LUKS = &luks
+}
+
+// AddSwapPartition inserts a swap partition of at least the given size in
+// bytes in front of the last partition, which is moved back accordingly. The
+// last partition is expected to be the root partition, filling the rest of
+// the image.
+func (options *QEMUAssemblerOptions) AddSwapPartition(size uint64, uuid string) {
+	// round up to 1 MiB (2048 sectors), so that the following partition
+	// stays aligned
+	const MegaByte = 1024 * 1024
+	sectors := (size + MegaByte - 1) / MegaByte * 2048
+
+	partitionType := "82"
+	if options.PTType == "gpt" {
+		partitionType = "0657FD6D-A4AB-43C4-84E5-0933C84B4F4F"
+	}
+
+	last := len(options.Partitions) - 1
+	root := options.Partitions[last]
+	swap := QEMUPartition{
+		Start: root.Start,
+		Size:  sectors,
+		Type:  partitionType,
+		Filesystem: &QEMUFilesystem{
+			Type: "swap",
+			UUID: uuid,
+		},
+	}
+	root.Start += sectors
+
+	options.Partitions = append(options.Partitions[:last], swap, root)
+}
+
+// AddBIOSBootPartition makes a GPT partitioned image bootable with BIOS in
+// addition to UEFI. It inserts a BIOS boot partition of 1 MiB (2048 sectors)
+// in front of the first partition, moves all partitions back accordingly and
+// installs grub2 for the given platform into it.
+func (options *QEMUAssemblerOptions) AddBIOSBootPartition(platform string) {
+	const sectors = 2048
+
+	bios := QEMUPartition{
+		Start: options.Partitions[0].Start,
+		Size:  sectors,
+		Type:  "21686148-6449-6E6F-744E-656564454649",
+	}
+	for i := range options.Partitions {
+		options.Partitions[i].Start += sectors
+	}
+
+	options.Partitions = append([]QEMUPartition{bios}, options.Partitions...)
+	options.Bootloader = &QEMUBootloader{
+		Type:     "grub2",
+		Platform: platform,
+	}
+}
+
 // NewQEMUAssembler creates a new QEMU Assembler object.
 func NewQEMUAssembler(options *QEMUAssemblerOptions) *Assembler {
 	return &Assembler{
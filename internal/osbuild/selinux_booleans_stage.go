@@ -0,0 +1,17 @@
+package osbuild
+
+// The SELinuxBooleansStageOptions describe SELinux booleans, which are
+// set persistently in the policy store of the tree.
+type SELinuxBooleansStageOptions struct {
+	Booleans map[string]bool `json:"booleans"`
+}
+
+func (SELinuxBooleansStageOptions) isStageOptions() {}
+
+// NewSELinuxBooleansStage creates a new SELinux booleans Stage object.
+func NewSELinuxBooleansStage(options *SELinuxBooleansStageOptions) *Stage {
+	return &Stage{
+		Name:    "org.osbuild.selinux.booleans",
+		Options: options,
+	}
+}
@@ -0,0 +1,16 @@
+package osbuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSELinuxBooleansStage(t *testing.T) {
+	expectedStage := &Stage{
+		Name:    "org.osbuild.selinux.booleans",
+		Options: &SELinuxBooleansStageOptions{},
+	}
+	actualStage := NewSELinuxBooleansStage(&SELinuxBooleansStageOptions{})
+	assert.Equal(t, expectedStage, actualStage)
+}
@@ -0,0 +1,21 @@
+package osbuild
+
+// The SELinuxConfigStageOptions describe the SELinux configuration written
+// to /etc/selinux/config.
+//
+// State is the mode the system boots in, i.e. one of "enforcing",
+// "permissive" or "disabled", Type is the name of the policy to load.
+type SELinuxConfigStageOptions struct {
+	State string `json:"state,omitempty"`
+	Type  string `json:"type,omitempty"`
+}
+
+func (SELinuxConfigStageOptions) isStageOptions() {}
+
+// NewSELinuxConfigStage creates a new SELinux config Stage object.
+func NewSELinuxConfigStage(options *SELinuxConfigStageOptions) *Stage {
+	return &Stage{
+		Name:    "org.osbuild.selinux.config",
+		Options: options,
+	}
+}
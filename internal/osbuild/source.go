@@ -29,6 +29,8 @@ func (sources *Sources) UnmarshalJSON(data []byte) error {
 		switch name {
 		case "org.osbuild.files":
 			source = new(FilesSource)
+		case "org.osbuild.ostree":
+			source = new(OSTreeSource)
 		default:
 			return errors.New("unexpected suorce name" + name)
 		}
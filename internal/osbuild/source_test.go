@@ -72,6 +72,18 @@ func TestSource_UnmarshalJSON(t *testing.T) {
 				data: []byte(`{"org.osbuild.files":{"urls":{"checksum1":{"url":"url1"},"checksum2":{"url":"url2"}}}}`),
 			},
 		},
+		{
+			name: "ostree",
+			fields: fields{
+				Name: "org.osbuild.ostree",
+				Source: &OSTreeSource{Items: map[string]OSTreeSourceItem{
+					"checksum1": OSTreeSourceItem{Remote: OSTreeRemote{URL: "url1"}},
+				}},
+			},
+			args: args{
+				data: []byte(`{"org.osbuild.ostree":{"items":{"checksum1":{"remote":{"url":"url1"}}}}}`),
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
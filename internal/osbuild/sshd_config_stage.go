@@ -0,0 +1,80 @@
+package osbuild
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// The SSHDConfigStageOptions describe options set in /etc/ssh/sshd_config.
+//
+// On systems which use crypto-policies, the stage also disables the policy
+// for sshd when Ciphers, MACs or KexAlgorithms are set, because the policy
+// takes precedence over sshd_config otherwise.
+type SSHDConfigStageOptions struct {
+	Config SSHDConfig `json:"config"`
+}
+
+func (SSHDConfigStageOptions) isStageOptions() {}
+
+// SSHDConfig is the typed subset of sshd_config options supported by the
+// stage. The names follow sshd_config(5).
+type SSHDConfig struct {
+	PermitRootLogin                 *string  `json:"PermitRootLogin,omitempty"`
+	PasswordAuthentication          *bool    `json:"PasswordAuthentication,omitempty"`
+	ChallengeResponseAuthentication *bool    `json:"ChallengeResponseAuthentication,omitempty"`
+	Ciphers                         []string `json:"Ciphers,omitempty"`
+	MACs                            []string `json:"MACs,omitempty"`
+	KexAlgorithms                   []string `json:"KexAlgorithms,omitempty"`
+}
+
+// NewSSHDConfigStage creates a new sshd config Stage object.
+func NewSSHDConfigStage(options *SSHDConfigStageOptions) *Stage {
+	return &Stage{
+		Name:    "org.osbuild.sshd.config",
+		Options: options,
+	}
+}
+
+var validSSHDCiphers = map[string]bool{
+	"3des-cbc":                      true,
+	"aes128-cbc":                    true,
+	"aes192-cbc":                    true,
+	"aes256-cbc":                    true,
+	"aes128-ctr":                    true,
+	"aes192-ctr":                    true,
+	"aes256-ctr":                    true,
+	"aes128-gcm@openssh.com":        true,
+	"aes256-gcm@openssh.com":        true,
+	"chacha20-poly1305@openssh.com": true,
+}
+
+var validSSHDAlgorithm = regexp.MustCompile(`^[a-z0-9][a-z0-9@.+-]*$`)
+
+// Validate returns an error if sshd would refuse to start with the options.
+func (options *SSHDConfigStageOptions) Validate() error {
+	config := options.Config
+
+	if config.PermitRootLogin != nil {
+		switch *config.PermitRootLogin {
+		case "yes", "no", "prohibit-password", "forced-commands-only":
+		default:
+			return fmt.Errorf("invalid value for PermitRootLogin: %q", *config.PermitRootLogin)
+		}
+	}
+
+	for _, cipher := range config.Ciphers {
+		if !validSSHDCiphers[cipher] {
+			return fmt.Errorf("unknown cipher: %q", cipher)
+		}
+	}
+
+	for _, algorithms := range [][]string{config.MACs, config.KexAlgorithms} {
+		for _, algorithm := range algorithms {
+			if !validSSHDAlgorithm.MatchString(algorithm) {
+				return fmt.Errorf("invalid algorithm name: %q", algorithm)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package osbuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSSHDConfigStage(t *testing.T) {
+	expectedStage := &Stage{
+		Name:    "org.osbuild.sshd.config",
+		Options: &SSHDConfigStageOptions{},
+	}
+	actualStage := NewSSHDConfigStage(&SSHDConfigStageOptions{})
+	assert.Equal(t, expectedStage, actualStage)
+}
+
+func TestSSHDConfigStageOptions_Validate(t *testing.T) {
+	no := "no"
+	maybe := "maybe"
+	tests := []struct {
+		name    string
+		config  SSHDConfig
+		wantErr bool
+	}{
+		{
+			name:   "empty",
+			config: SSHDConfig{},
+		},
+		{
+			name: "valid",
+			config: SSHDConfig{
+				PermitRootLogin: &no,
+				Ciphers:         []string{"aes256-gcm@openssh.com", "chacha20-poly1305@openssh.com"},
+				MACs:            []string{"hmac-sha2-512-etm@openssh.com"},
+				KexAlgorithms:   []string{"curve25519-sha256"},
+			},
+		},
+		{
+			name:    "invalid PermitRootLogin",
+			config:  SSHDConfig{PermitRootLogin: &maybe},
+			wantErr: true,
+		},
+		{
+			name:    "unknown cipher",
+			config:  SSHDConfig{Ciphers: []string{"rot13"}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid mac",
+			config:  SSHDConfig{MACs: []string{"hmac-sha1\nPermitRootLogin yes"}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := SSHDConfigStageOptions{Config: tt.config}
+			err := options.Validate()
+			assert.Equal(t, tt.wantErr, err != nil, "Validate() error = %v", err)
+		})
+	}
+}
@@ -68,6 +68,44 @@ func (stage *Stage) UnmarshalJSON(data []byte) error {
 		options = new(SystemdStageOptions)
 	case "org.osbuild.script":
 		options = new(ScriptStageOptions)
+	case "org.osbuild.first-boot":
+		options = new(FirstBootStageOptions)
+	case "org.osbuild.selinux.config":
+		options = new(SELinuxConfigStageOptions)
+	case "org.osbuild.selinux.booleans":
+		options = new(SELinuxBooleansStageOptions)
+	case "org.osbuild.nm.keyfile":
+		options = new(NMKeyfileStageOptions)
+	case "org.osbuild.ca-trust":
+		options = new(CATrustStageOptions)
+	case "org.osbuild.sysctld":
+		options = new(SysctldStageOptions)
+	case "org.osbuild.modprobe":
+		options = new(ModprobeStageOptions)
+	case "org.osbuild.modules-load":
+		options = new(ModulesLoadStageOptions)
+	case "org.osbuild.tuned":
+		options = new(TunedStageOptions)
+	case "org.osbuild.cloud-init":
+		options = new(CloudInitStageOptions)
+	case "org.osbuild.sudoers":
+		options = new(SudoersStageOptions)
+	case "org.osbuild.sshd.config":
+		options = new(SSHDConfigStageOptions)
+	case "org.osbuild.yum.repos":
+		options = new(YumReposStageOptions)
+	case "org.osbuild.swapfile":
+		options = new(SwapfileStageOptions)
+	case "org.osbuild.clevis.luks":
+		options = new(ClevisLUKSStageOptions)
+	case "org.osbuild.dracut.conf":
+		options = new(DracutConfStageOptions)
+	case "org.osbuild.dracut":
+		options = new(DracutStageOptions)
+	case "org.osbuild.tar.extract":
+		options = new(TarExtractStageOptions)
+	case "org.osbuild.kickstart":
+		options = new(KickstartStageOptions)
 	default:
 		return fmt.Errorf("unexpected stage name: %s", rawStage.Name)
 	}
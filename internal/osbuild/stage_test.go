@@ -52,6 +52,16 @@ func TestStage_UnmarshalJSON(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "ca-trust",
+			fields: fields{
+				Name:    "org.osbuild.ca-trust",
+				Options: &CATrustStageOptions{},
+			},
+			args: args{
+				data: []byte(`{"name":"org.osbuild.ca-trust","options":{"anchors":null}}`),
+			},
+		},
 		{
 			name: "chrony",
 			fields: fields{
@@ -62,6 +72,46 @@ func TestStage_UnmarshalJSON(t *testing.T) {
 				data: []byte(`{"name":"org.osbuild.chrony","options":{"timeservers":null}}`),
 			},
 		},
+		{
+			name: "clevis.luks",
+			fields: fields{
+				Name:    "org.osbuild.clevis.luks",
+				Options: &ClevisLUKSStageOptions{},
+			},
+			args: args{
+				data: []byte(`{"name":"org.osbuild.clevis.luks","options":{"uuid":"","pin":"","policy":""}}`),
+			},
+		},
+		{
+			name: "cloud-init",
+			fields: fields{
+				Name:    "org.osbuild.cloud-init",
+				Options: &CloudInitStageOptions{},
+			},
+			args: args{
+				data: []byte(`{"name":"org.osbuild.cloud-init","options":{"filename":"","config":null}}`),
+			},
+		},
+		{
+			name: "dracut",
+			fields: fields{
+				Name:    "org.osbuild.dracut",
+				Options: &DracutStageOptions{},
+			},
+			args: args{
+				data: []byte(`{"name":"org.osbuild.dracut","options":{}}`),
+			},
+		},
+		{
+			name: "dracut.conf",
+			fields: fields{
+				Name:    "org.osbuild.dracut.conf",
+				Options: &DracutConfStageOptions{},
+			},
+			args: args{
+				data: []byte(`{"name":"org.osbuild.dracut.conf","options":{"filename":"","config":{}}}`),
+			},
+		},
 		{
 			name: "firewall",
 			fields: fields{
@@ -72,6 +122,31 @@ func TestStage_UnmarshalJSON(t *testing.T) {
 				data: []byte(`{"name":"org.osbuild.firewall","options":{}}`),
 			},
 		},
+		{
+			name: "firewall-zones",
+			fields: fields{
+				Name: "org.osbuild.firewall",
+				Options: &FirewallStageOptions{
+					DefaultZone: "dmz",
+					Zones: []FirewallZone{
+						{Name: "trusted", Sources: []string{"10.0.0.0/8"}, Services: []string{"ssh"}},
+					},
+				},
+			},
+			args: args{
+				data: []byte(`{"name":"org.osbuild.firewall","options":{"default_zone":"dmz","zones":[{"name":"trusted","sources":["10.0.0.0/8"],"services":["ssh"]}]}}`),
+			},
+		},
+		{
+			name: "first-boot",
+			fields: fields{
+				Name:    "org.osbuild.first-boot",
+				Options: &FirstBootStageOptions{},
+			},
+			args: args{
+				data: []byte(`{"name":"org.osbuild.first-boot","options":{"commands":null}}`),
+			},
+		},
 		{
 			name: "fix-bls",
 			fields: fields{
@@ -162,6 +237,16 @@ func TestStage_UnmarshalJSON(t *testing.T) {
 				data: []byte(`{"name":"org.osbuild.keymap","options":{"keymap":""}}`),
 			},
 		},
+		{
+			name: "kickstart",
+			fields: fields{
+				Name:    "org.osbuild.kickstart",
+				Options: &KickstartStageOptions{},
+			},
+			args: args{
+				data: []byte(`{"name":"org.osbuild.kickstart","options":{"path":""}}`),
+			},
+		},
 		{
 			name: "locale",
 			fields: fields{
@@ -172,6 +257,36 @@ func TestStage_UnmarshalJSON(t *testing.T) {
 				data: []byte(`{"name":"org.osbuild.locale","options":{"language":""}}`),
 			},
 		},
+		{
+			name: "modprobe",
+			fields: fields{
+				Name:    "org.osbuild.modprobe",
+				Options: &ModprobeStageOptions{},
+			},
+			args: args{
+				data: []byte(`{"name":"org.osbuild.modprobe","options":{"filename":"","commands":null}}`),
+			},
+		},
+		{
+			name: "modules-load",
+			fields: fields{
+				Name:    "org.osbuild.modules-load",
+				Options: &ModulesLoadStageOptions{},
+			},
+			args: args{
+				data: []byte(`{"name":"org.osbuild.modules-load","options":{"filename":"","modules":null}}`),
+			},
+		},
+		{
+			name: "nm.keyfile",
+			fields: fields{
+				Name:    "org.osbuild.nm.keyfile",
+				Options: &NMKeyfileStageOptions{},
+			},
+			args: args{
+				data: []byte(`{"name":"org.osbuild.nm.keyfile","options":{"connections":null}}`),
+			},
+		},
 		{
 			name: "rpm-empty",
 			fields: fields{
@@ -237,6 +352,66 @@ func TestStage_UnmarshalJSON(t *testing.T) {
 				data: []byte(`{"name":"org.osbuild.selinux","options":{"file_contexts":""}}`),
 			},
 		},
+		{
+			name: "selinux.booleans",
+			fields: fields{
+				Name:    "org.osbuild.selinux.booleans",
+				Options: &SELinuxBooleansStageOptions{},
+			},
+			args: args{
+				data: []byte(`{"name":"org.osbuild.selinux.booleans","options":{"booleans":null}}`),
+			},
+		},
+		{
+			name: "selinux.config",
+			fields: fields{
+				Name:    "org.osbuild.selinux.config",
+				Options: &SELinuxConfigStageOptions{},
+			},
+			args: args{
+				data: []byte(`{"name":"org.osbuild.selinux.config","options":{}}`),
+			},
+		},
+		{
+			name: "sshd.config",
+			fields: fields{
+				Name:    "org.osbuild.sshd.config",
+				Options: &SSHDConfigStageOptions{},
+			},
+			args: args{
+				data: []byte(`{"name":"org.osbuild.sshd.config","options":{"config":{}}}`),
+			},
+		},
+		{
+			name: "sudoers",
+			fields: fields{
+				Name:    "org.osbuild.sudoers",
+				Options: &SudoersStageOptions{},
+			},
+			args: args{
+				data: []byte(`{"name":"org.osbuild.sudoers","options":{"filename":"","rules":null}}`),
+			},
+		},
+		{
+			name: "swapfile",
+			fields: fields{
+				Name:    "org.osbuild.swapfile",
+				Options: &SwapfileStageOptions{},
+			},
+			args: args{
+				data: []byte(`{"name":"org.osbuild.swapfile","options":{"filename":"","size":0}}`),
+			},
+		},
+		{
+			name: "sysctld",
+			fields: fields{
+				Name:    "org.osbuild.sysctld",
+				Options: &SysctldStageOptions{},
+			},
+			args: args{
+				data: []byte(`{"name":"org.osbuild.sysctld","options":{"filename":"","config":null}}`),
+			},
+		},
 		{
 			name: "systemd",
 			fields: fields{
@@ -259,6 +434,16 @@ func TestStage_UnmarshalJSON(t *testing.T) {
 				data: []byte(`{"name":"org.osbuild.systemd","options":{"enabled_services":["foo.service"]}}`),
 			},
 		},
+		{
+			name: "tar.extract",
+			fields: fields{
+				Name:    "org.osbuild.tar.extract",
+				Options: &TarExtractStageOptions{},
+			},
+			args: args{
+				data: []byte(`{"name":"org.osbuild.tar.extract","options":{"source":"","path":""}}`),
+			},
+		},
 		{
 			name: "timezone",
 			fields: fields{
@@ -269,6 +454,16 @@ func TestStage_UnmarshalJSON(t *testing.T) {
 				data: []byte(`{"name":"org.osbuild.timezone","options":{"zone":""}}`),
 			},
 		},
+		{
+			name: "tuned",
+			fields: fields{
+				Name:    "org.osbuild.tuned",
+				Options: &TunedStageOptions{},
+			},
+			args: args{
+				data: []byte(`{"name":"org.osbuild.tuned","options":{"profiles":null}}`),
+			},
+		},
 		{
 			name: "users",
 			fields: fields{
@@ -279,6 +474,16 @@ func TestStage_UnmarshalJSON(t *testing.T) {
 				data: []byte(`{"name":"org.osbuild.users","options":{"users":null}}`),
 			},
 		},
+		{
+			name: "yum.repos",
+			fields: fields{
+				Name:    "org.osbuild.yum.repos",
+				Options: &YumReposStageOptions{},
+			},
+			args: args{
+				data: []byte(`{"name":"org.osbuild.yum.repos","options":{"filename":"","repos":null}}`),
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,71 @@
+package osbuild
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// The SudoersStageOptions describe a file written to /etc/sudoers.d.
+type SudoersStageOptions struct {
+	Filename string        `json:"filename"`
+	Rules    []SudoersRule `json:"rules"`
+}
+
+func (SudoersStageOptions) isStageOptions() {}
+
+// A SudoersRule allows either a user or the members of a group to run
+// commands as another user, which defaults to any user. Commands must be
+// absolute paths, optionally followed by arguments, or "ALL".
+type SudoersRule struct {
+	User       string   `json:"user,omitempty"`
+	Group      string   `json:"group,omitempty"`
+	RunAs      string   `json:"runas,omitempty"`
+	Commands   []string `json:"commands"`
+	NoPassword bool     `json:"nopasswd,omitempty"`
+}
+
+// NewSudoersStage creates a new sudoers Stage object.
+func NewSudoersStage(options *SudoersStageOptions) *Stage {
+	return &Stage{
+		Name:    "org.osbuild.sudoers",
+		Options: options,
+	}
+}
+
+// sudo ignores files in /etc/sudoers.d whose names contain a dot or end
+// with a tilde
+var validSudoersFilename = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+var validSudoersName = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9_.-]*[$]?$`)
+
+// Validate returns an error if the options would result in a sudoers file
+// that sudo refuses to parse or that grants more than the rules describe.
+func (options *SudoersStageOptions) Validate() error {
+	if !validSudoersFilename.MatchString(options.Filename) {
+		return fmt.Errorf("invalid sudoers filename: %q", options.Filename)
+	}
+
+	for _, rule := range options.Rules {
+		if (rule.User == "") == (rule.Group == "") {
+			return fmt.Errorf("sudoers rule must apply to either a user or a group")
+		}
+		for _, name := range []string{rule.User, rule.Group, rule.RunAs} {
+			if name != "" && name != "ALL" && !validSudoersName.MatchString(name) {
+				return fmt.Errorf("invalid user or group name in sudoers rule: %q", name)
+			}
+		}
+		if len(rule.Commands) == 0 {
+			return fmt.Errorf("sudoers rule for %s%s has no commands", rule.User, rule.Group)
+		}
+		for _, command := range rule.Commands {
+			if command != "ALL" && !strings.HasPrefix(command, "/") {
+				return fmt.Errorf("sudoers command must be an absolute path or ALL: %q", command)
+			}
+			if strings.ContainsAny(command, ",:=\\\n") {
+				return fmt.Errorf("invalid character in sudoers command: %q", command)
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package osbuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSudoersStage(t *testing.T) {
+	expectedStage := &Stage{
+		Name:    "org.osbuild.sudoers",
+		Options: &SudoersStageOptions{},
+	}
+	actualStage := NewSudoersStage(&SudoersStageOptions{})
+	assert.Equal(t, expectedStage, actualStage)
+}
+
+func TestSudoersStageOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		options SudoersStageOptions
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			options: SudoersStageOptions{
+				Filename: "osbuild-composer",
+				Rules: []SudoersRule{
+					{User: "deploy", Commands: []string{"ALL"}, NoPassword: true},
+					{Group: "operators", RunAs: "root", Commands: []string{"/usr/bin/systemctl restart httpd"}},
+				},
+			},
+		},
+		{
+			name:    "filename with dot",
+			options: SudoersStageOptions{Filename: "osbuild.conf"},
+			wantErr: true,
+		},
+		{
+			name: "user and group",
+			options: SudoersStageOptions{
+				Filename: "osbuild-composer",
+				Rules:    []SudoersRule{{User: "deploy", Group: "wheel", Commands: []string{"ALL"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "neither user nor group",
+			options: SudoersStageOptions{
+				Filename: "osbuild-composer",
+				Rules:    []SudoersRule{{Commands: []string{"ALL"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid user",
+			options: SudoersStageOptions{
+				Filename: "osbuild-composer",
+				Rules:    []SudoersRule{{User: "deploy ALL=(ALL)", Commands: []string{"ALL"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "no commands",
+			options: SudoersStageOptions{
+				Filename: "osbuild-composer",
+				Rules:    []SudoersRule{{User: "deploy"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "relative command",
+			options: SudoersStageOptions{
+				Filename: "osbuild-composer",
+				Rules:    []SudoersRule{{User: "deploy", Commands: []string{"systemctl"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "multiple commands in one",
+			options: SudoersStageOptions{
+				Filename: "osbuild-composer",
+				Rules:    []SudoersRule{{User: "deploy", Commands: []string{"/usr/bin/id, ALL"}}},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.options.Validate()
+			assert.Equal(t, tt.wantErr, err != nil, "Validate() error = %v", err)
+		})
+	}
+}
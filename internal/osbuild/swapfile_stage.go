@@ -0,0 +1,18 @@
+package osbuild
+
+// The SwapfileStageOptions describe a swap file, which is created in the
+// tree with the given size in bytes and formatted with mkswap.
+type SwapfileStageOptions struct {
+	Filename string `json:"filename"`
+	Size     uint64 `json:"size"`
+}
+
+func (SwapfileStageOptions) isStageOptions() {}
+
+// NewSwapfileStage creates a new swapfile Stage object.
+func NewSwapfileStage(options *SwapfileStageOptions) *Stage {
+	return &Stage{
+		Name:    "org.osbuild.swapfile",
+		Options: options,
+	}
+}
@@ -0,0 +1,16 @@
+package osbuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSwapfileStage(t *testing.T) {
+	expectedStage := &Stage{
+		Name:    "org.osbuild.swapfile",
+		Options: &SwapfileStageOptions{},
+	}
+	actualStage := NewSwapfileStage(&SwapfileStageOptions{})
+	assert.Equal(t, expectedStage, actualStage)
+}
@@ -0,0 +1,24 @@
+package osbuild
+
+// The SysctldStageOptions describe a configuration file written to
+// /etc/sysctl.d, which sets kernel parameters at boot.
+type SysctldStageOptions struct {
+	Filename string                    `json:"filename"`
+	Config   []SysctldStageConfigEntry `json:"config"`
+}
+
+func (SysctldStageOptions) isStageOptions() {}
+
+// A SysctldStageConfigEntry is one "key = value" line of the file.
+type SysctldStageConfigEntry struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// NewSysctldStage creates a new sysctl.d Stage object.
+func NewSysctldStage(options *SysctldStageOptions) *Stage {
+	return &Stage{
+		Name:    "org.osbuild.sysctld",
+		Options: options,
+	}
+}
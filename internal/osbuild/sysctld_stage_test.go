@@ -0,0 +1,16 @@
+package osbuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSysctldStage(t *testing.T) {
+	expectedStage := &Stage{
+		Name:    "org.osbuild.sysctld",
+		Options: &SysctldStageOptions{},
+	}
+	actualStage := NewSysctldStage(&SysctldStageOptions{})
+	assert.Equal(t, expectedStage, actualStage)
+}
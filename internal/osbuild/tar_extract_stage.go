@@ -0,0 +1,19 @@
+package osbuild
+
+// The TarExtractStageOptions describe a tarball, which is extracted into the
+// tree at the given path. The tarball is taken from the org.osbuild.files
+// source and identified by its checksum.
+type TarExtractStageOptions struct {
+	Source string `json:"source"`
+	Path   string `json:"path"`
+}
+
+func (TarExtractStageOptions) isStageOptions() {}
+
+// NewTarExtractStage creates a new tar extract stage object.
+func NewTarExtractStage(options *TarExtractStageOptions) *Stage {
+	return &Stage{
+		Name:    "org.osbuild.tar.extract",
+		Options: options,
+	}
+}
@@ -0,0 +1,16 @@
+package osbuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTarExtractStage(t *testing.T) {
+	expectedStage := &Stage{
+		Name:    "org.osbuild.tar.extract",
+		Options: &TarExtractStageOptions{},
+	}
+	actualStage := NewTarExtractStage(&TarExtractStageOptions{})
+	assert.Equal(t, expectedStage, actualStage)
+}
@@ -0,0 +1,17 @@
+package osbuild
+
+// The TunedStageOptions describe the tuned profiles to activate, which is
+// done by writing them to /etc/tuned/active_profile.
+type TunedStageOptions struct {
+	Profiles []string `json:"profiles"`
+}
+
+func (TunedStageOptions) isStageOptions() {}
+
+// NewTunedStage creates a new tuned Stage object.
+func NewTunedStage(options *TunedStageOptions) *Stage {
+	return &Stage{
+		Name:    "org.osbuild.tuned",
+		Options: options,
+	}
+}
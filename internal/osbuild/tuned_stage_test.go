@@ -0,0 +1,16 @@
+package osbuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTunedStage(t *testing.T) {
+	expectedStage := &Stage{
+		Name:    "org.osbuild.tuned",
+		Options: &TunedStageOptions{},
+	}
+	actualStage := NewTunedStage(&TunedStageOptions{})
+	assert.Equal(t, expectedStage, actualStage)
+}
@@ -0,0 +1,42 @@
+package osbuild
+
+// The YumReposStageOptions describe a file written to /etc/yum.repos.d.
+//
+// GPGKeys are written to /etc/pki/rpm-gpg and imported into the rpm
+// database of the tree, so repositories can reference them with a file://
+// URL.
+type YumReposStageOptions struct {
+	Filename string           `json:"filename"`
+	Repos    []YumRepository  `json:"repos"`
+	GPGKeys  []YumReposGPGKey `json:"gpgkeys,omitempty"`
+}
+
+func (YumReposStageOptions) isStageOptions() {}
+
+// A YumRepository is one section of a .repo file, see yum.conf(5).
+type YumRepository struct {
+	ID           string   `json:"id"`
+	Name         string   `json:"name,omitempty"`
+	BaseURL      []string `json:"baseurl,omitempty"`
+	Metalink     string   `json:"metalink,omitempty"`
+	Mirrorlist   string   `json:"mirrorlist,omitempty"`
+	Enabled      *bool    `json:"enabled,omitempty"`
+	GPGCheck     *bool    `json:"gpgcheck,omitempty"`
+	RepoGPGCheck *bool    `json:"repo_gpgcheck,omitempty"`
+	GPGKey       []string `json:"gpgkey,omitempty"`
+}
+
+// A YumReposGPGKey is an ASCII armored key, written to
+// /etc/pki/rpm-gpg/<filename>.
+type YumReposGPGKey struct {
+	Filename string `json:"filename"`
+	Key      string `json:"key"`
+}
+
+// NewYumReposStage creates a new yum.repos Stage object.
+func NewYumReposStage(options *YumReposStageOptions) *Stage {
+	return &Stage{
+		Name:    "org.osbuild.yum.repos",
+		Options: options,
+	}
+}
@@ -0,0 +1,16 @@
+package osbuild
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewYumReposStage(t *testing.T) {
+	expectedStage := &Stage{
+		Name:    "org.osbuild.yum.repos",
+		Options: &YumReposStageOptions{},
+	}
+	actualStage := NewYumReposStage(&YumReposStageOptions{})
+	assert.Equal(t, expectedStage, actualStage)
+}
@@ -0,0 +1,68 @@
+// Package ostree provides access to remote OSTree repositories.
+package ostree
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var checksumRegex = regexp.MustCompile("^[0-9a-f]{64}$")
+
+// The grammar of refs in ostree, which also keeps them from escaping the
+// refs directory of a repository
+var refRegex = regexp.MustCompile(`^[\w\d][-._\w\d]*(/[\w\d][-._\w\d]*)*$`)
+
+// Refs are tiny files, a repository which does not serve one within this
+// time is considered unreachable.
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// IsChecksum returns whether s is the checksum of an OSTree commit.
+func IsChecksum(s string) bool {
+	return checksumRegex.MatchString(s)
+}
+
+// IsRef returns whether s is a valid name of a ref.
+func IsRef(s string) bool {
+	return refRegex.MatchString(s)
+}
+
+// ResolveRef returns the checksum of the commit that ref points to in the
+// OSTree repository at location.
+func ResolveRef(location, ref string) (string, error) {
+	if !IsRef(ref) {
+		return "", fmt.Errorf("invalid ref %q", ref)
+	}
+
+	u, err := url.Parse(location)
+	if err != nil {
+		return "", fmt.Errorf("error parsing repository location %s: %v", location, err)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/refs/heads/" + ref
+
+	response, err := httpClient.Get(u.String())
+	if err != nil {
+		return "", fmt.Errorf("error fetching ref %s: %v", ref, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("error fetching ref %s: %s returned %s", ref, u, response.Status)
+	}
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return "", fmt.Errorf("error reading ref %s: %v", ref, err)
+	}
+
+	checksum := strings.TrimSpace(string(body))
+	if !IsChecksum(checksum) {
+		return "", fmt.Errorf("ref %s does not point to a valid commit: %s", ref, checksum)
+	}
+
+	return checksum, nil
+}
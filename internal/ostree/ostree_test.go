@@ -0,0 +1,62 @@
+package ostree
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveRef(t *testing.T) {
+	const checksum = "02604b2da6e954bd34b8b82a835e5a77d2b60ffa4d1b2f6a7a4f7b7c4c4c4c4c"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repo/refs/heads/rhel/8/x86_64/edge":
+			fmt.Fprintln(w, checksum)
+		case "/repo/refs/heads/invalid":
+			fmt.Fprintln(w, "not a checksum")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	resolved, err := ResolveRef(server.URL+"/repo", "rhel/8/x86_64/edge")
+	require.NoError(t, err)
+	assert.Equal(t, checksum, resolved)
+
+	resolved, err = ResolveRef(server.URL+"/repo/", "rhel/8/x86_64/edge")
+	require.NoError(t, err)
+	assert.Equal(t, checksum, resolved)
+
+	_, err = ResolveRef(server.URL+"/repo", "invalid")
+	assert.Error(t, err)
+
+	_, err = ResolveRef(server.URL+"/repo", "missing")
+	assert.Error(t, err)
+
+	_, err = ResolveRef(server.URL+"/repo", "../refs/heads/invalid")
+	assert.Error(t, err)
+}
+
+func TestIsRef(t *testing.T) {
+	assert.True(t, IsRef("rhel/8/x86_64/edge"))
+	assert.True(t, IsRef("fedora-iot_2.0"))
+	assert.False(t, IsRef(""))
+	assert.False(t, IsRef("../../x"))
+	assert.False(t, IsRef("rhel/8/./edge"))
+	assert.False(t, IsRef("rhel//edge"))
+	assert.False(t, IsRef("rhel/edge/"))
+	assert.False(t, IsRef("edge?x=1"))
+	assert.False(t, IsRef("edge#x"))
+}
+
+func TestIsChecksum(t *testing.T) {
+	assert.True(t, IsChecksum("02604b2da6e954bd34b8b82a835e5a77d2b60ffa4d1b2f6a7a4f7b7c4c4c4c4c"))
+	assert.False(t, IsChecksum("rhel/8/x86_64/edge"))
+	assert.False(t, IsChecksum(""))
+}
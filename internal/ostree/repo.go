@@ -0,0 +1,307 @@
+package ostree
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// A Repo is an OSTree repository in archive mode, which contains the
+// commits composer built. Commits are imported from the tarballs produced
+// by the org.osbuild.ostree.commit assembler.
+//
+// The repository is stored in `$DIR/repo`, the history of its refs in
+// `$DIR/history.json`.
+type Repo struct {
+	dir string
+
+	// Protects refs and the history, objects are immutable
+	mutex   sync.RWMutex
+	history map[string][]Commit
+}
+
+// A Commit is an entry in the history of a ref.
+type Commit struct {
+	Checksum  string    `json:"checksum"`
+	ComposeID uuid.UUID `json:"compose_id"`
+	Imported  time.Time `json:"imported"`
+}
+
+const repoConfig = `[core]
+repo_version=1
+mode=archive-z2
+`
+
+// NewRepo opens the repository in dir, creating it if it does not exist.
+func NewRepo(dir string) (*Repo, error) {
+	r := &Repo{
+		dir:     dir,
+		history: make(map[string][]Commit),
+	}
+
+	for _, d := range []string{"objects", "deltas", "refs/heads", "refs/remotes", "tmp"} {
+		err := os.MkdirAll(path.Join(r.repoDir(), d), 0755)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create ostree repository: %v", err)
+		}
+	}
+
+	config := path.Join(r.repoDir(), "config")
+	if _, err := os.Stat(config); os.IsNotExist(err) {
+		err = ioutil.WriteFile(config, []byte(repoConfig), 0644)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create ostree repository: %v", err)
+		}
+	}
+
+	data, err := ioutil.ReadFile(path.Join(dir, "history.json"))
+	if err == nil {
+		err = json.Unmarshal(data, &r.history)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read ostree history: %v", err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("cannot read ostree history: %v", err)
+	}
+
+	return r, nil
+}
+
+func (r *Repo) repoDir() string {
+	return path.Join(r.dir, "repo")
+}
+
+// Import adds the objects and static deltas of the repository in the
+// tarball from reader, and points the refs in it to their new commits. Refs
+// are only updated once all objects are in place, so that clients never see
+// a ref to an incomplete commit.
+func (r *Repo) Import(reader io.Reader, composeID uuid.UUID) error {
+	refs := make(map[string]string)
+
+	tr := tar.NewReader(reader)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("error reading commit tarball: %v", err)
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := path.Clean(strings.TrimPrefix(header.Name, "./"))
+		if !strings.HasPrefix(name, "repo/") {
+			continue
+		}
+		name = strings.TrimPrefix(name, "repo/")
+
+		switch {
+		case strings.HasPrefix(name, "refs/heads/"):
+			checksum, err := ioutil.ReadAll(tr)
+			if err != nil {
+				return fmt.Errorf("error reading commit tarball: %v", err)
+			}
+			ref := strings.TrimPrefix(name, "refs/heads/")
+			refs[ref] = strings.TrimSpace(string(checksum))
+
+		case strings.HasPrefix(name, "objects/"), strings.HasPrefix(name, "deltas/"):
+			err := r.writeFile(name, tr)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(refs) == 0 {
+		return errors.New("commit tarball does not contain any refs")
+	}
+	for ref, checksum := range refs {
+		if !IsChecksum(checksum) {
+			return fmt.Errorf("ref %s does not point to a valid commit: %s", ref, checksum)
+		}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for ref, checksum := range refs {
+		err := r.writeFile(path.Join("refs/heads", ref), strings.NewReader(checksum+"\n"))
+		if err != nil {
+			return err
+		}
+		r.history[ref] = append(r.history[ref], Commit{
+			Checksum:  checksum,
+			ComposeID: composeID,
+			Imported:  time.Now(),
+		})
+	}
+
+	return r.writeHistory()
+}
+
+// writeFile atomically writes the contents of reader to name in the
+// repository, by writing it to a temporary file first.
+func (r *Repo) writeFile(name string, reader io.Reader) error {
+	// path.Clean() removed all ".." that don't lead out of the repository
+	if path.IsAbs(name) || strings.HasPrefix(name, "..") {
+		return fmt.Errorf("invalid path in commit tarball: %s", name)
+	}
+
+	p := path.Join(r.repoDir(), name)
+	err := os.MkdirAll(path.Dir(p), 0755)
+	if err != nil {
+		return fmt.Errorf("error writing %s: %v", name, err)
+	}
+
+	f, err := ioutil.TempFile(path.Join(r.repoDir(), "tmp"), "import-")
+	if err != nil {
+		return fmt.Errorf("error writing %s: %v", name, err)
+	}
+	defer os.Remove(f.Name())
+
+	_, err = io.Copy(f, reader)
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("error writing %s: %v", name, err)
+	}
+
+	err = f.Chmod(0644)
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("error writing %s: %v", name, err)
+	}
+
+	err = f.Close()
+	if err != nil {
+		return fmt.Errorf("error writing %s: %v", name, err)
+	}
+
+	err = os.Rename(f.Name(), p)
+	if err != nil {
+		return fmt.Errorf("error writing %s: %v", name, err)
+	}
+
+	return nil
+}
+
+func (r *Repo) writeHistory() error {
+	data, err := json.Marshal(r.history)
+	if err != nil {
+		panic(err)
+	}
+
+	f, err := ioutil.TempFile(r.dir, "history-")
+	if err != nil {
+		return fmt.Errorf("error writing ostree history: %v", err)
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.Write(data)
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("error writing ostree history: %v", err)
+	}
+
+	err = f.Close()
+	if err != nil {
+		return fmt.Errorf("error writing ostree history: %v", err)
+	}
+
+	err = os.Rename(f.Name(), path.Join(r.dir, "history.json"))
+	if err != nil {
+		return fmt.Errorf("error writing ostree history: %v", err)
+	}
+
+	return nil
+}
+
+// Refs returns all refs in the repository.
+func (r *Repo) Refs() []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	refs := make([]string, 0, len(r.history))
+	for ref := range r.history {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+
+	return refs
+}
+
+// History returns the commits of ref, oldest first.
+func (r *Repo) History(ref string) ([]Commit, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	history, exists := r.history[ref]
+	if !exists {
+		return nil, false
+	}
+
+	return append([]Commit(nil), history...), true
+}
+
+// Handler returns an http.Handler, which serves the repository read-only
+// below `/repo/` and the history of its refs below `/history/`.
+func (r *Repo) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/repo/", http.StripPrefix("/repo/", readOnly(http.FileServer(http.Dir(r.repoDir())))))
+	mux.Handle("/history/", readOnly(http.HandlerFunc(r.historyHandler)))
+	return mux
+}
+
+func readOnly(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodGet && request.Method != http.MethodHead {
+			writer.Header().Set("Allow", "GET, HEAD")
+			http.Error(writer, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		handler.ServeHTTP(writer, request)
+	})
+}
+
+// Serves the refs at `/history/` and the commits of a ref at
+// `/history/$REF`.
+func (r *Repo) historyHandler(writer http.ResponseWriter, request *http.Request) {
+	ref := strings.TrimPrefix(request.URL.Path, "/history/")
+
+	var reply interface{}
+	if ref == "" {
+		reply = struct {
+			Refs []string `json:"refs"`
+		}{r.Refs()}
+	} else {
+		history, exists := r.History(ref)
+		if !exists {
+			http.NotFound(writer, request)
+			return
+		}
+		reply = struct {
+			Ref     string   `json:"ref"`
+			Commits []Commit `json:"commits"`
+		}{ref, history}
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(writer).Encode(reply)
+	if err != nil {
+		panic(err)
+	}
+}
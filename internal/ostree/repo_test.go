@@ -0,0 +1,140 @@
+package ostree
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func commitTarball(t *testing.T, files map[string]string) *bytes.Buffer {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for name, content := range files {
+		err := tw.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0644,
+			Size:     int64(len(content)),
+			Typeflag: tar.TypeReg,
+		})
+		require.NoError(t, err)
+		_, err = tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	return &buf
+}
+
+func TestRepo(t *testing.T) {
+	const commit1 = "02604b2da6e954bd34b8b82a835e5a77d2b60ffa4d1b2f6a7a4f7b7c4c4c4c4c"
+	const commit2 = "6d3b1b9c0a8a3f0e3c9a8b7d6e5f4a3b2c1d0e9f8a7b6c5d4e3f2a1b0c9d8e7f"
+
+	dir, err := ioutil.TempDir("", "ostree-repo-test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	repo, err := NewRepo(dir)
+	require.NoError(t, err)
+	assert.Empty(t, repo.Refs())
+
+	compose1 := uuid.New()
+	err = repo.Import(commitTarball(t, map[string]string{
+		"compose.json":                       "{}",
+		"repo/config":                        "[core]\nmode=bare\n",
+		"repo/objects/02/604b.commit":        "commit1",
+		"repo/refs/heads/test/edge":          commit1 + "\n",
+		"repo/refs/heads/../../../evil":      commit1,
+		"repo/objects/../../../history.json": "evil",
+	}), compose1)
+	require.NoError(t, err)
+
+	compose2 := uuid.New()
+	err = repo.Import(commitTarball(t, map[string]string{
+		"./repo/objects/6d/3b1b.commit":    "commit2",
+		"./repo/deltas/6d/3b1b/superblock": "delta",
+		"./repo/refs/heads/test/edge":      commit2,
+	}), compose2)
+	require.NoError(t, err)
+
+	// a tarball without refs is rejected
+	err = repo.Import(commitTarball(t, map[string]string{"repo/objects/aa/bb.commit": "commit"}), uuid.New())
+	require.Error(t, err)
+
+	// a ref must point to a commit
+	err = repo.Import(commitTarball(t, map[string]string{"repo/refs/heads/test/edge": "foo"}), uuid.New())
+	require.Error(t, err)
+
+	assert.Equal(t, []string{"test/edge"}, repo.Refs())
+	history, exists := repo.History("test/edge")
+	require.True(t, exists)
+	require.Len(t, history, 2)
+	assert.Equal(t, commit1, history[0].Checksum)
+	assert.Equal(t, compose1, history[0].ComposeID)
+	assert.Equal(t, commit2, history[1].Checksum)
+	assert.Equal(t, compose2, history[1].ComposeID)
+
+	_, err = os.Stat(path.Join(dir, "evil"))
+	assert.True(t, os.IsNotExist(err))
+
+	// the history is persisted
+	repo, err = NewRepo(dir)
+	require.NoError(t, err)
+	reopened, _ := repo.History("test/edge")
+	assert.Len(t, reopened, 2)
+
+	server := httptest.NewServer(repo.Handler())
+	defer server.Close()
+
+	get := func(p string) (int, string) {
+		response, err := http.Get(server.URL + p)
+		require.NoError(t, err)
+		defer response.Body.Close()
+		body, err := ioutil.ReadAll(response.Body)
+		require.NoError(t, err)
+		return response.StatusCode, string(body)
+	}
+
+	status, body := get("/repo/refs/heads/test/edge")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, commit2+"\n", body)
+
+	status, body = get("/repo/objects/02/604b.commit")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "commit1", body)
+
+	status, body = get("/repo/config")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, repoConfig, body)
+
+	status, body = get("/history/")
+	assert.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, `{"refs":["test/edge"]}`, body)
+
+	status, body = get("/history/test/edge")
+	assert.Equal(t, http.StatusOK, status)
+	var reply struct {
+		Ref     string   `json:"ref"`
+		Commits []Commit `json:"commits"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(body), &reply))
+	assert.Equal(t, "test/edge", reply.Ref)
+	require.Len(t, reply.Commits, 2)
+	assert.Equal(t, compose2, reply.Commits[1].ComposeID)
+
+	status, _ = get("/history/test/missing")
+	assert.Equal(t, http.StatusNotFound, status)
+
+	response, err := http.Post(server.URL+"/repo/refs/heads/test/edge", "text/plain", bytes.NewBufferString(commit1))
+	require.NoError(t, err)
+	response.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, response.StatusCode)
+}
@@ -107,6 +107,7 @@ type PackageSpec struct {
 	Checksum       string `json:"checksum,omitempty"`
 	Secrets        string `json:"secrets,omitempty"`
 	CheckGPG       bool   `json:"check_gpg,omitempty"`
+	InstallSize    uint64 `json:"install_size,omitempty"`
 }
 
 type dnfPackageSpec struct {
@@ -120,6 +121,7 @@ type dnfPackageSpec struct {
 	RemoteLocation string `json:"remote_location,omitempty"`
 	Checksum       string `json:"checksum,omitempty"`
 	Secrets        string `json:"secrets,omitempty"`
+	InstallSize    uint64 `json:"install_size,omitempty"`
 }
 
 type PackageSource struct {
@@ -418,6 +420,7 @@ func (r *rpmmdImpl) Depsolve(specs, excludeSpecs []string, repos []RepoConfig, m
 		dependencies[i].RemoteLocation = dep.RemoteLocation
 		dependencies[i].Checksum = dep.Checksum
 		dependencies[i].CheckGPG = repo.CheckGPG
+		dependencies[i].InstallSize = dep.InstallSize
 		if repo.RHSM {
 			dependencies[i].Secrets = "org.osbuild.rhsm"
 		}
@@ -0,0 +1,36 @@
+package target
+
+// ContainerRegistryTargetOptions describe where to push an OCI archive. The
+// registry is a host name, optionally with a port, or a URL. Credentials are
+// either given directly or read from AuthFile, which uses the format of
+// containers-auth.json(5).
+type ContainerRegistryTargetOptions struct {
+	Filename   string `json:"filename"`
+	Registry   string `json:"registry"`
+	Repository string `json:"repository"`
+	Tag        string `json:"tag"`
+	Username   string `json:"username,omitempty"`
+	Password   string `json:"password,omitempty"`
+	AuthFile   string `json:"auth_file,omitempty"`
+}
+
+func (ContainerRegistryTargetOptions) isTargetOptions() {}
+
+func NewContainerRegistryTarget(options *ContainerRegistryTargetOptions) *Target {
+	return newTarget("org.osbuild.container-registry", options)
+}
+
+// ContainerRegistryTargetResultOptions contain the digest of the manifest,
+// which was pushed to the repository and tag of the registry.
+type ContainerRegistryTargetResultOptions struct {
+	Registry   string `json:"registry"`
+	Repository string `json:"repository"`
+	Tag        string `json:"tag"`
+	Digest     string `json:"digest"`
+}
+
+func (ContainerRegistryTargetResultOptions) isTargetResultOptions() {}
+
+func NewContainerRegistryTargetResult(options *ContainerRegistryTargetResultOptions) *TargetResult {
+	return newTargetResult("org.osbuild.container-registry", options)
+}
@@ -0,0 +1,31 @@
+package target
+
+// GCPTargetOptions describe how to import an image into Google Compute
+// Engine. The image is uploaded to Bucket as Object, from which the image
+// ImageName is created in Project. Credentials is the JSON key of a service
+// account, which may write to the bucket and create images.
+type GCPTargetOptions struct {
+	Filename    string `json:"filename"`
+	Project     string `json:"project"`
+	Bucket      string `json:"bucket"`
+	Object      string `json:"object"`
+	ImageName   string `json:"image_name"`
+	Credentials string `json:"credentials"`
+}
+
+func (GCPTargetOptions) isTargetOptions() {}
+
+func NewGCPTarget(options *GCPTargetOptions) *Target {
+	return newTarget("org.osbuild.gcp", options)
+}
+
+type GCPTargetResultOptions struct {
+	Project   string `json:"project"`
+	ImageName string `json:"image_name"`
+}
+
+func (GCPTargetResultOptions) isTargetResultOptions() {}
+
+func NewGCPTargetResult(options *GCPTargetResultOptions) *TargetResult {
+	return newTargetResult("org.osbuild.gcp", options)
+}
@@ -3,10 +3,15 @@ package target
 import "github.com/google/uuid"
 
 type LocalTargetOptions struct {
-	ComposeId       uuid.UUID `json:"compose_id"`
-	ImageBuildId    int       `json:"image_build_id"`
-	Filename        string    `json:"filename"`
-	StreamOptimized bool      `json:"stream_optimized"` // return image as stream optimized
+	ComposeId    uuid.UUID `json:"compose_id"`
+	ImageBuildId int       `json:"image_build_id"`
+	Filename     string    `json:"filename"`
+	Filenames    []string  `json:"filenames,omitempty"` // all files of images consisting of more than one
+
+	// StreamOptimized is only set for vmdk images of jobs, which were
+	// queued before the manifests built streamOptimized vmdk images. The
+	// worker still converts them.
+	StreamOptimized bool `json:"stream_optimized,omitempty"`
 }
 
 func (LocalTargetOptions) isTargetOptions() {}
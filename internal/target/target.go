@@ -72,6 +72,10 @@ func UnmarshalTargetOptions(targetName string, rawOptions json.RawMessage) (Targ
 		options = new(LocalTargetOptions)
 	case "org.osbuild.koji":
 		options = new(KojiTargetOptions)
+	case "org.osbuild.container-registry":
+		options = new(ContainerRegistryTargetOptions)
+	case "org.osbuild.gcp":
+		options = new(GCPTargetOptions)
 	default:
 		return nil, errors.New("unexpected target name")
 	}
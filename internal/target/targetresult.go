@@ -0,0 +1,61 @@
+package target
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// A TargetResult is what a worker reports back about a target after
+// uploading the image to it, for example the location of the image.
+type TargetResult struct {
+	Name    string              `json:"name"`
+	Options TargetResultOptions `json:"options"`
+}
+
+func newTargetResult(name string, options TargetResultOptions) *TargetResult {
+	return &TargetResult{
+		Name:    name,
+		Options: options,
+	}
+}
+
+type TargetResultOptions interface {
+	isTargetResultOptions()
+}
+
+type rawTargetResult struct {
+	Name    string          `json:"name"`
+	Options json.RawMessage `json:"options"`
+}
+
+func (targetResult *TargetResult) UnmarshalJSON(data []byte) error {
+	var rawTR rawTargetResult
+	err := json.Unmarshal(data, &rawTR)
+	if err != nil {
+		return err
+	}
+	options, err := UnmarshalTargetResultOptions(rawTR.Name, rawTR.Options)
+	if err != nil {
+		return err
+	}
+
+	targetResult.Name = rawTR.Name
+	targetResult.Options = options
+
+	return nil
+}
+
+func UnmarshalTargetResultOptions(targetName string, rawOptions json.RawMessage) (TargetResultOptions, error) {
+	var options TargetResultOptions
+	switch targetName {
+	case "org.osbuild.container-registry":
+		options = new(ContainerRegistryTargetResultOptions)
+	case "org.osbuild.gcp":
+		options = new(GCPTargetResultOptions)
+	default:
+		return nil, errors.New("unexpected target result name")
+	}
+	err := json.Unmarshal(rawOptions, options)
+
+	return options, err
+}
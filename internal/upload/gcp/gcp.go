@@ -0,0 +1,351 @@
+// Package gcp uploads images to Google Cloud Storage and imports them as
+// Compute Engine images, using the JSON APIs of both services.
+package gcp
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	storageURL = "https://storage.googleapis.com"
+	computeURL = "https://compute.googleapis.com/compute/v1"
+	scopes     = "https://www.googleapis.com/auth/devstorage.read_write https://www.googleapis.com/auth/compute"
+)
+
+// Credentials are the fields of the JSON key of a service account, which are
+// needed to request access tokens.
+type Credentials struct {
+	ClientEmail  string `json:"client_email"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	TokenURI     string `json:"token_uri"`
+}
+
+// GCP uploads images with the permissions of a service account.
+type GCP struct {
+	credentials *Credentials
+	privateKey  *rsa.PrivateKey
+	client      *http.Client
+	token       string
+	expiry      time.Time
+
+	storageURL   string
+	computeURL   string
+	pollInterval time.Duration
+	pollTimeout  time.Duration
+}
+
+// New creates a GCP object from the JSON key of a service account.
+func New(credentials []byte) (*GCP, error) {
+	var c Credentials
+	err := json.Unmarshal(credentials, &c)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse credentials: %v", err)
+	}
+	if c.ClientEmail == "" || c.TokenURI == "" {
+		return nil, errors.New("credentials are not the key of a service account")
+	}
+
+	block, _ := pem.Decode([]byte(c.PrivateKey))
+	if block == nil {
+		return nil, errors.New("credentials do not contain a PEM encoded private key")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse private key: %v", err)
+	}
+	privateKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not an RSA key")
+	}
+
+	return &GCP{
+		credentials:  &c,
+		privateKey:   privateKey,
+		client:       newHTTPClient(),
+		storageURL:   storageURL,
+		computeURL:   computeURL,
+		pollInterval: 10 * time.Second,
+		pollTimeout:  time.Hour,
+	}, nil
+}
+
+// newHTTPClient returns a client, which gives up on connections and
+// responses which stall. It does not limit the duration of whole requests,
+// because uploading an image can take long.
+func newHTTPClient() *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			DialContext: (&net.Dialer{
+				Timeout:   30 * time.Second,
+				KeepAlive: 30 * time.Second,
+			}).DialContext,
+			TLSHandshakeTimeout:   30 * time.Second,
+			ResponseHeaderTimeout: 5 * time.Minute,
+		},
+	}
+}
+
+// Upload uploads the file at filename to bucket as object.
+func (g *GCP) Upload(filename, bucket, object string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	query.Set("uploadType", "media")
+	query.Set("name", object)
+	u := fmt.Sprintf("%s/upload/storage/v1/b/%s/o?%s", g.storageURL, url.PathEscape(bucket), query.Encode())
+
+	request, err := http.NewRequest(http.MethodPost, u, f)
+	if err != nil {
+		return err
+	}
+	request.ContentLength = info.Size()
+	request.Header.Set("Content-Type", "application/octet-stream")
+
+	response, err := g.do(request)
+	if err != nil {
+		return fmt.Errorf("cannot upload %s to bucket %s: %v", object, bucket, err)
+	}
+	response.Body.Close()
+
+	return nil
+}
+
+// DeleteObject deletes object from bucket.
+func (g *GCP) DeleteObject(bucket, object string) error {
+	u := fmt.Sprintf("%s/storage/v1/b/%s/o/%s", g.storageURL, url.PathEscape(bucket), url.PathEscape(object))
+	request, err := http.NewRequest(http.MethodDelete, u, nil)
+	if err != nil {
+		return err
+	}
+
+	response, err := g.do(request)
+	if err != nil {
+		return fmt.Errorf("cannot delete %s from bucket %s: %v", object, bucket, err)
+	}
+	response.Body.Close()
+
+	return nil
+}
+
+type operation struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Error  *struct {
+		Errors []struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	} `json:"error"`
+}
+
+// CreateImage imports object in bucket, which must be a gzip compressed
+// tarball of a raw disk named disk.raw, as the Compute Engine image name in
+// project. It waits until the image is created, but at most an hour.
+func (g *GCP) CreateImage(project, name, bucket, object string) error {
+	image := map[string]interface{}{
+		"name": name,
+		"rawDisk": map[string]string{
+			"source": fmt.Sprintf("%s/%s/%s", storageURL, bucket, object),
+		},
+		"guestOsFeatures": []map[string]string{
+			{"type": "VIRTIO_SCSI_MULTIQUEUE"},
+		},
+	}
+	body, err := json.Marshal(image)
+	if err != nil {
+		return err
+	}
+
+	u := fmt.Sprintf("%s/projects/%s/global/images", g.computeURL, url.PathEscape(project))
+	request, err := http.NewRequest(http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	var op operation
+	err = g.doJSON(request, &op)
+	if err != nil {
+		return fmt.Errorf("cannot create image %s: %v", name, err)
+	}
+
+	deadline := time.Now().Add(g.pollTimeout)
+	for op.Status != "DONE" {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("cannot create image %s: timed out after %v", name, g.pollTimeout)
+		}
+		time.Sleep(g.pollInterval)
+
+		u := fmt.Sprintf("%s/projects/%s/global/operations/%s", g.computeURL, url.PathEscape(project), url.PathEscape(op.Name))
+		request, err := http.NewRequest(http.MethodGet, u, nil)
+		if err != nil {
+			return err
+		}
+
+		err = g.doJSON(request, &op)
+		if err != nil {
+			return fmt.Errorf("cannot get status of creating image %s: %v", name, err)
+		}
+	}
+
+	if op.Error != nil && len(op.Error.Errors) > 0 {
+		messages := make([]string, len(op.Error.Errors))
+		for i, e := range op.Error.Errors {
+			messages[i] = fmt.Sprintf("%s: %s", e.Code, e.Message)
+		}
+		return fmt.Errorf("cannot create image %s: %s", name, strings.Join(messages, "; "))
+	}
+
+	return nil
+}
+
+// do sends request with an access token and returns an error if the
+// response does not indicate success.
+func (g *GCP) do(request *http.Request) (*http.Response, error) {
+	err := g.authenticate()
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Authorization", "Bearer "+g.token)
+
+	response, err := g.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		defer response.Body.Close()
+		return nil, errorFromResponse(response)
+	}
+
+	return response, nil
+}
+
+func (g *GCP) doJSON(request *http.Request, v interface{}) error {
+	response, err := g.do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	err = json.NewDecoder(response.Body).Decode(v)
+	if err != nil {
+		return fmt.Errorf("cannot parse response: %v", err)
+	}
+
+	return nil
+}
+
+func errorFromResponse(response *http.Response) error {
+	var body struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	data, _ := ioutil.ReadAll(io.LimitReader(response.Body, 64*1024))
+	if json.Unmarshal(data, &body) == nil && body.Error.Message != "" {
+		return fmt.Errorf("%s: %s", response.Status, body.Error.Message)
+	}
+	return errors.New(response.Status)
+}
+
+// authenticate exchanges a JSON web token signed with the key of the service
+// account for an access token, unless the current one is still valid.
+func (g *GCP) authenticate() error {
+	now := time.Now()
+	if g.token != "" && now.Add(time.Minute).Before(g.expiry) {
+		return nil
+	}
+
+	assertion, err := g.signedJWT(now)
+	if err != nil {
+		return err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "urn:ietf:params:oauth:grant-type:jwt-bearer")
+	form.Set("assertion", assertion)
+	response, err := g.client.PostForm(g.credentials.TokenURI, form)
+	if err != nil {
+		return fmt.Errorf("cannot request access token: %v", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("cannot request access token: %s", response.Status)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	err = json.NewDecoder(response.Body).Decode(&token)
+	if err != nil || token.AccessToken == "" {
+		return errors.New("cannot parse access token")
+	}
+
+	g.token = token.AccessToken
+	g.expiry = now.Add(time.Duration(token.ExpiresIn) * time.Second)
+
+	return nil
+}
+
+func (g *GCP) signedJWT(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{
+		"alg": "RS256",
+		"typ": "JWT",
+		"kid": g.credentials.PrivateKeyID,
+	})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"iss":   g.credentials.ClientEmail,
+		"scope": scopes,
+		"aud":   g.credentials.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	encoding := base64.RawURLEncoding
+	unsigned := encoding.EncodeToString(header) + "." + encoding.EncodeToString(claims)
+	hash := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, g.privateKey, crypto.SHA256, hash[:])
+	if err != nil {
+		return "", fmt.Errorf("cannot sign token request: %v", err)
+	}
+
+	return unsigned + "." + encoding.EncodeToString(signature), nil
+}
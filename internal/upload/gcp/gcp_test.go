@@ -0,0 +1,219 @@
+package gcp
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// A stand-in for the token endpoint and the parts of the storage and
+// compute APIs, which are needed to create images.
+type testGCP struct {
+	t          *testing.T
+	mutex      sync.Mutex
+	publicKey  *rsa.PublicKey
+	objects    map[string][]byte
+	images     map[string]string
+	tokens     int
+	polls      int
+	failImport bool
+	hangImport bool
+}
+
+func (g *testGCP) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if request.URL.Path == "/token" {
+		require.NoError(g.t, request.ParseForm())
+		assert.Equal(g.t, "urn:ietf:params:oauth:grant-type:jwt-bearer", request.PostForm.Get("grant_type"))
+		parts := strings.Split(request.PostForm.Get("assertion"), ".")
+		require.Len(g.t, parts, 3)
+		signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+		require.NoError(g.t, err)
+		hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		if rsa.VerifyPKCS1v15(g.publicKey, crypto.SHA256, hash[:], signature) != nil {
+			writer.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		claims, err := base64.RawURLEncoding.DecodeString(parts[1])
+		require.NoError(g.t, err)
+		assert.Contains(g.t, string(claims), `"iss":"builder@project.iam.gserviceaccount.com"`)
+		g.tokens++
+		_, _ = writer.Write([]byte(`{"access_token":"access-token","expires_in":3600,"token_type":"Bearer"}`))
+		return
+	}
+
+	if request.Header.Get("Authorization") != "Bearer access-token" {
+		writer.WriteHeader(http.StatusUnauthorized)
+		_, _ = writer.Write([]byte(`{"error":{"code":401,"message":"Invalid Credentials"}}`))
+		return
+	}
+
+	switch {
+	case request.Method == http.MethodPost && request.URL.Path == "/upload/storage/v1/b/bucket/o":
+		assert.Equal(g.t, "media", request.URL.Query().Get("uploadType"))
+		data, err := ioutil.ReadAll(request.Body)
+		require.NoError(g.t, err)
+		g.objects[request.URL.Query().Get("name")] = data
+		_, _ = writer.Write([]byte(`{}`))
+
+	case request.Method == http.MethodDelete && strings.HasPrefix(request.URL.Path, "/storage/v1/b/bucket/o/"):
+		delete(g.objects, strings.TrimPrefix(request.URL.Path, "/storage/v1/b/bucket/o/"))
+		writer.WriteHeader(http.StatusNoContent)
+
+	case request.Method == http.MethodPost && request.URL.Path == "/compute/projects/project/global/images":
+		var image struct {
+			Name    string `json:"name"`
+			RawDisk struct {
+				Source string `json:"source"`
+			} `json:"rawDisk"`
+		}
+		require.NoError(g.t, json.NewDecoder(request.Body).Decode(&image))
+		g.images[image.Name] = image.RawDisk.Source
+		_, _ = writer.Write([]byte(`{"name":"operation-1","status":"RUNNING"}`))
+
+	case request.Method == http.MethodGet && request.URL.Path == "/compute/projects/project/global/operations/operation-1":
+		g.polls++
+		if g.hangImport {
+			_, _ = writer.Write([]byte(`{"name":"operation-1","status":"RUNNING"}`))
+		} else if g.failImport {
+			_, _ = writer.Write([]byte(`{"name":"operation-1","status":"DONE","error":{"errors":[{"code":"INVALID_IMAGE","message":"disk.raw not found"}]}}`))
+		} else {
+			_, _ = writer.Write([]byte(`{"name":"operation-1","status":"DONE"}`))
+		}
+
+	default:
+		writer.WriteHeader(http.StatusNotFound)
+		_, _ = writer.Write([]byte(`{"error":{"code":404,"message":"Not Found"}}`))
+	}
+}
+
+func newTestGCP(t *testing.T) (*testGCP, *httptest.Server, []byte) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	g := &testGCP{
+		t:         t,
+		publicKey: &key.PublicKey,
+		objects:   make(map[string][]byte),
+		images:    make(map[string]string),
+	}
+	server := httptest.NewServer(g)
+
+	credentials, err := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"project_id":     "project",
+		"private_key_id": "key-1",
+		"private_key":    string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"client_email":   "builder@project.iam.gserviceaccount.com",
+		"token_uri":      server.URL + "/token",
+	})
+	require.NoError(t, err)
+
+	return g, server, credentials
+}
+
+func newTestClient(t *testing.T, server *httptest.Server, credentials []byte) *GCP {
+	client, err := New(credentials)
+	require.NoError(t, err)
+	client.storageURL = server.URL
+	client.computeURL = server.URL + "/compute"
+	client.pollInterval = time.Millisecond
+	client.pollTimeout = time.Second
+	return client
+}
+
+func TestCreateImage(t *testing.T) {
+	g, server, credentials := newTestGCP(t)
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "gcp-test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	image := path.Join(dir, "image.tar.gz")
+	require.NoError(t, ioutil.WriteFile(image, []byte("disk"), 0644))
+
+	client := newTestClient(t, server, credentials)
+
+	err = client.Upload(image, "bucket", "composer/image.tar.gz")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("disk"), g.objects["composer/image.tar.gz"])
+
+	err = client.CreateImage("project", "rhel-8", "bucket", "composer/image.tar.gz")
+	require.NoError(t, err)
+	assert.Equal(t, "https://storage.googleapis.com/bucket/composer/image.tar.gz", g.images["rhel-8"])
+	assert.Equal(t, 1, g.polls)
+
+	err = client.DeleteObject("bucket", "composer/image.tar.gz")
+	require.NoError(t, err)
+	assert.Empty(t, g.objects)
+
+	// the access token is reused until it expires
+	assert.Equal(t, 1, g.tokens)
+
+	g.failImport = true
+	err = client.CreateImage("project", "rhel-8", "bucket", "composer/image.tar.gz")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "INVALID_IMAGE: disk.raw not found")
+
+	// give up on imports which do not finish
+	g.failImport = false
+	g.hangImport = true
+	client.pollTimeout = 20 * time.Millisecond
+	err = client.CreateImage("project", "rhel-8", "bucket", "composer/image.tar.gz")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out")
+
+	err = client.Upload(image, "other-bucket", "image.tar.gz")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Not Found")
+}
+
+func TestNew(t *testing.T) {
+	_, server, credentials := newTestGCP(t)
+	defer server.Close()
+
+	_, err := New(credentials)
+	require.NoError(t, err)
+
+	_, err = New([]byte(`{"type":"authorized_user"}`))
+	assert.Error(t, err)
+
+	_, err = New([]byte(fmt.Sprintf(`{"client_email":"builder@project.iam.gserviceaccount.com","token_uri":"%s/token","private_key":"invalid"}`, server.URL)))
+	assert.Error(t, err)
+
+	// a key which the token endpoint does not accept
+	other, otherServer, _ := newTestGCP(t)
+	defer otherServer.Close()
+	var c map[string]string
+	require.NoError(t, json.Unmarshal(credentials, &c))
+	c["token_uri"] = otherServer.URL + "/token"
+	wrongKey, err := json.Marshal(c)
+	require.NoError(t, err)
+
+	client := newTestClient(t, otherServer, wrongKey)
+	err = client.DeleteObject("bucket", "image.tar.gz")
+	assert.Error(t, err)
+	assert.Equal(t, 0, other.tokens)
+}
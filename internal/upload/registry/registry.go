@@ -0,0 +1,453 @@
+// Package registry pushes OCI image archives to container registries, which
+// implement the docker registry HTTP API v2.
+package registry
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const ociManifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+
+var validDigest = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
+// Credentials for the registry. Registries which use token authentication
+// exchange them for a token.
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// A Client pushes images to a registry.
+type Client struct {
+	base        *url.URL
+	credentials *Credentials
+	client      *http.Client
+	token       string
+}
+
+// NewClient creates a client for registry, which is either a host name,
+// optionally with a port, or a URL. Registries without a scheme are
+// accessed via https. credentials may be nil.
+func NewClient(registry string, credentials *Credentials) (*Client, error) {
+	if !strings.Contains(registry, "://") {
+		registry = "https://" + registry
+	}
+
+	base, err := url.Parse(registry)
+	if err != nil {
+		return nil, fmt.Errorf("invalid registry %s: %v", registry, err)
+	}
+
+	return &Client{
+		base:        base,
+		credentials: credentials,
+		client:      newHTTPClient(),
+	}, nil
+}
+
+// newHTTPClient returns a client, which gives up on registries which stall.
+// It does not limit the duration of whole requests, because uploading large
+// layers can take long.
+func newHTTPClient() *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			DialContext: (&net.Dialer{
+				Timeout:   30 * time.Second,
+				KeepAlive: 30 * time.Second,
+			}).DialContext,
+			TLSHandshakeTimeout:   30 * time.Second,
+			ResponseHeaderTimeout: 5 * time.Minute,
+		},
+	}
+}
+
+// ReadAuthFile returns the credentials for registry in authFile, which has
+// the format of containers-auth.json(5).
+func ReadAuthFile(authFile, registry string) (*Credentials, error) {
+	data, err := ioutil.ReadFile(authFile)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read auth file: %v", err)
+	}
+
+	var config struct {
+		Auths map[string]struct {
+			Auth string `json:"auth"`
+		} `json:"auths"`
+	}
+	err = json.Unmarshal(data, &config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse auth file: %v", err)
+	}
+
+	entry, exists := config.Auths[registry]
+	if !exists {
+		return nil, fmt.Errorf("auth file does not contain credentials for %s", registry)
+	}
+
+	auth, err := base64.StdEncoding.DecodeString(entry.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("invalid credentials for %s in auth file: %v", registry, err)
+	}
+
+	parts := strings.SplitN(string(auth), ":", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid credentials for %s in auth file", registry)
+	}
+
+	return &Credentials{
+		Username: parts[0],
+		Password: parts[1],
+	}, nil
+}
+
+type descriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+// Push uploads the image in the OCI archive at archivePath to
+// repository:tag, and returns the digest of its manifest. The archive must
+// contain exactly one image. Blobs are read from the archive directly, it is
+// not extracted.
+func (c *Client) Push(archivePath, repository, tag string) (string, error) {
+	archive, err := openArchive(archivePath)
+	if err != nil {
+		return "", err
+	}
+	defer archive.Close()
+
+	var index struct {
+		Manifests []descriptor `json:"manifests"`
+	}
+	indexEntry, exists := archive.entries["index.json"]
+	if !exists {
+		return "", errors.New("OCI archive does not contain an index")
+	}
+	data, err := ioutil.ReadAll(indexEntry)
+	if err != nil {
+		return "", fmt.Errorf("cannot read index of OCI archive: %v", err)
+	}
+	err = json.Unmarshal(data, &index)
+	if err != nil {
+		return "", fmt.Errorf("cannot parse index of OCI archive: %v", err)
+	}
+	if len(index.Manifests) != 1 {
+		return "", fmt.Errorf("OCI archive contains %d images, expected 1", len(index.Manifests))
+	}
+
+	manifestDescriptor := index.Manifests[0]
+	if manifestDescriptor.MediaType == "" {
+		manifestDescriptor.MediaType = ociManifestMediaType
+	}
+	blob, err := archive.blob(manifestDescriptor.Digest)
+	if err != nil {
+		return "", err
+	}
+	manifest, err := ioutil.ReadAll(blob)
+	if err != nil {
+		return "", fmt.Errorf("cannot read blob %s of OCI archive: %v", manifestDescriptor.Digest, err)
+	}
+
+	var image struct {
+		Config descriptor   `json:"config"`
+		Layers []descriptor `json:"layers"`
+	}
+	err = json.Unmarshal(manifest, &image)
+	if err != nil {
+		return "", fmt.Errorf("cannot parse manifest of OCI archive: %v", err)
+	}
+
+	for _, blob := range append(image.Layers, image.Config) {
+		err = c.pushBlob(archive, repository, blob.Digest)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return c.pushManifest(repository, tag, manifestDescriptor, manifest)
+}
+
+// An ociArchive is an OCI archive, whose index and blobs are read from the
+// position of their contents in the tarball.
+type ociArchive struct {
+	file    *os.File
+	entries map[string]*io.SectionReader
+}
+
+// Finds the index and the blobs in the OCI archive at archivePath.
+func openArchive(archivePath string) (*ociArchive, error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open OCI archive: %v", err)
+	}
+
+	archive := &ociArchive{
+		file:    f,
+		entries: make(map[string]*io.SectionReader),
+	}
+
+	tr := tar.NewReader(f)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("cannot read OCI archive: %v", err)
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := path.Clean(strings.TrimPrefix(header.Name, "./"))
+		if name != "index.json" && !strings.HasPrefix(name, "blobs/sha256/") {
+			continue
+		}
+
+		// the tar reader does not read ahead, so the file is positioned at
+		// the contents of the entry
+		offset, err := f.Seek(0, io.SeekCurrent)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("cannot read OCI archive: %v", err)
+		}
+		archive.entries[name] = io.NewSectionReader(f, offset, header.Size)
+	}
+
+	return archive, nil
+}
+
+func (a *ociArchive) Close() error {
+	return a.file.Close()
+}
+
+// Returns a new reader for the blob with digest, so that it can be read
+// more than once.
+func (a *ociArchive) blob(digest string) (*io.SectionReader, error) {
+	if !validDigest.MatchString(digest) {
+		return nil, fmt.Errorf("invalid digest in OCI archive: %s", digest)
+	}
+
+	entry, exists := a.entries["blobs/sha256/"+strings.TrimPrefix(digest, "sha256:")]
+	if !exists {
+		return nil, fmt.Errorf("OCI archive does not contain blob %s", digest)
+	}
+
+	return io.NewSectionReader(entry, 0, entry.Size()), nil
+}
+
+func (c *Client) pushBlob(archive *ociArchive, repository, digest string) error {
+	if _, err := archive.blob(digest); err != nil {
+		return err
+	}
+
+	blobURL := c.url("/v2/%s/blobs/%s", repository, digest)
+	response, err := c.do(repository, func() (*http.Request, error) {
+		return http.NewRequest(http.MethodHead, blobURL.String(), nil)
+	})
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+	if response.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	uploadURL := c.url("/v2/%s/blobs/uploads/", repository)
+	response, err = c.do(repository, func() (*http.Request, error) {
+		return http.NewRequest(http.MethodPost, uploadURL.String(), nil)
+	})
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("cannot start upload of blob %s: %s", digest, response.Status)
+	}
+
+	location, err := uploadURL.Parse(response.Header.Get("Location"))
+	if err != nil {
+		return fmt.Errorf("invalid upload location for blob %s: %v", digest, err)
+	}
+	query := location.Query()
+	query.Set("digest", digest)
+	location.RawQuery = query.Encode()
+
+	response, err = c.do(repository, func() (*http.Request, error) {
+		blob, err := archive.blob(digest)
+		if err != nil {
+			return nil, err
+		}
+
+		request, err := http.NewRequest(http.MethodPut, location.String(), blob)
+		if err != nil {
+			return nil, err
+		}
+		request.ContentLength = blob.Size()
+		request.Header.Set("Content-Type", "application/octet-stream")
+		return request, nil
+	})
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusCreated {
+		return fmt.Errorf("cannot upload blob %s: %s", digest, response.Status)
+	}
+
+	return nil
+}
+
+func (c *Client) pushManifest(repository, tag string, manifestDescriptor descriptor, manifest []byte) (string, error) {
+	manifestURL := c.url("/v2/%s/manifests/%s", repository, tag)
+	response, err := c.do(repository, func() (*http.Request, error) {
+		request, err := http.NewRequest(http.MethodPut, manifestURL.String(), bytes.NewReader(manifest))
+		if err != nil {
+			return nil, err
+		}
+		request.Header.Set("Content-Type", manifestDescriptor.MediaType)
+		return request, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("cannot upload manifest: %s", response.Status)
+	}
+
+	digest := response.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		digest = manifestDescriptor.Digest
+	}
+
+	return digest, nil
+}
+
+func (c *Client) url(format string, a ...interface{}) *url.URL {
+	u := *c.base
+	u.Path = strings.TrimSuffix(u.Path, "/") + fmt.Sprintf(format, a...)
+	return &u
+}
+
+// Sends the request created by newRequest, authenticating with the
+// credentials of the client. newRequest is called a second time if the
+// registry asks for token authentication.
+func (c *Client) do(repository string, newRequest func() (*http.Request, error)) (*http.Response, error) {
+	response, err := c.send(newRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge := response.Header.Get("WWW-Authenticate")
+	if response.StatusCode != http.StatusUnauthorized || !strings.HasPrefix(challenge, "Bearer ") {
+		return response, nil
+	}
+	response.Body.Close()
+
+	err = c.fetchToken(challenge, repository)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.send(newRequest)
+}
+
+func (c *Client) send(newRequest func() (*http.Request, error)) (*http.Response, error) {
+	request, err := newRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	if c.token != "" {
+		request.Header.Set("Authorization", "Bearer "+c.token)
+	} else if c.credentials != nil {
+		request.SetBasicAuth(c.credentials.Username, c.credentials.Password)
+	}
+
+	response, err := c.client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to registry: %v", err)
+	}
+
+	return response, nil
+}
+
+var challengeParam = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// Fetches a token for pushing to repository from the authorization server
+// in the bearer challenge of the registry.
+func (c *Client) fetchToken(challenge, repository string) error {
+	params := make(map[string]string)
+	for _, match := range challengeParam.FindAllStringSubmatch(challenge, -1) {
+		params[match[1]] = match[2]
+	}
+
+	realm, err := url.Parse(params["realm"])
+	if err != nil || params["realm"] == "" {
+		return fmt.Errorf("invalid authentication challenge from registry: %s", challenge)
+	}
+
+	query := realm.Query()
+	if service, exists := params["service"]; exists {
+		query.Set("service", service)
+	}
+	query.Set("scope", fmt.Sprintf("repository:%s:pull,push", repository))
+	realm.RawQuery = query.Encode()
+
+	request, err := http.NewRequest(http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return err
+	}
+	if c.credentials != nil {
+		request.SetBasicAuth(c.credentials.Username, c.credentials.Password)
+	}
+
+	response, err := c.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("cannot connect to authorization server: %v", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("authorization server denied access to %s: %s", repository, response.Status)
+	}
+
+	var reply struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	err = json.NewDecoder(response.Body).Decode(&reply)
+	if err != nil {
+		return fmt.Errorf("cannot parse reply of authorization server: %v", err)
+	}
+
+	c.token = reply.Token
+	if c.token == "" {
+		c.token = reply.AccessToken
+	}
+	if c.token == "" {
+		return errors.New("authorization server did not return a token")
+	}
+
+	return nil
+}
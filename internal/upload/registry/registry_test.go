@@ -0,0 +1,255 @@
+package registry
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// A registry stand-in, which implements the parts of the v2 API needed for
+// pushing images.
+type testRegistry struct {
+	t          *testing.T
+	mutex      sync.Mutex
+	tokenAuth  bool
+	blobs      map[string][]byte
+	manifests  map[string][]byte
+	mediaTypes map[string]string
+	uploads    int
+}
+
+func newTestRegistry(t *testing.T, tokenAuth bool) *testRegistry {
+	return &testRegistry{
+		t:          t,
+		tokenAuth:  tokenAuth,
+		blobs:      make(map[string][]byte),
+		manifests:  make(map[string][]byte),
+		mediaTypes: make(map[string]string),
+	}
+}
+
+func (r *testRegistry) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if request.URL.Path == "/token" {
+		username, password, ok := request.BasicAuth()
+		if !ok || username != "user" || password != "secret" {
+			writer.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		assert.Equal(r.t, "repository:library/test:pull,push", request.URL.Query().Get("scope"))
+		_, _ = writer.Write([]byte(`{"token":"push-token"}`))
+		return
+	}
+
+	if r.tokenAuth {
+		if request.Header.Get("Authorization") != "Bearer push-token" {
+			writer.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="http://%s/token",service="test"`, request.Host))
+			writer.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+	} else {
+		username, password, ok := request.BasicAuth()
+		if !ok || username != "user" || password != "secret" {
+			writer.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+			writer.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+	}
+
+	const prefix = "/v2/library/test"
+	p := strings.TrimPrefix(request.URL.Path, prefix)
+
+	switch {
+	case request.Method == http.MethodHead && strings.HasPrefix(p, "/blobs/"):
+		if _, exists := r.blobs[strings.TrimPrefix(p, "/blobs/")]; !exists {
+			writer.WriteHeader(http.StatusNotFound)
+		}
+
+	case request.Method == http.MethodPost && p == "/blobs/uploads/":
+		r.uploads++
+		writer.Header().Set("Location", fmt.Sprintf("%s/blobs/uploads/%d?state=x", prefix, r.uploads))
+		writer.WriteHeader(http.StatusAccepted)
+
+	case request.Method == http.MethodPut && strings.HasPrefix(p, "/blobs/uploads/"):
+		assert.Equal(r.t, "x", request.URL.Query().Get("state"))
+		digest := request.URL.Query().Get("digest")
+		data, err := ioutil.ReadAll(request.Body)
+		require.NoError(r.t, err)
+		if digest != fmt.Sprintf("sha256:%x", sha256.Sum256(data)) {
+			writer.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		r.blobs[digest] = data
+		writer.WriteHeader(http.StatusCreated)
+
+	case request.Method == http.MethodPut && strings.HasPrefix(p, "/manifests/"):
+		data, err := ioutil.ReadAll(request.Body)
+		require.NoError(r.t, err)
+		var manifest struct {
+			Config descriptor   `json:"config"`
+			Layers []descriptor `json:"layers"`
+		}
+		require.NoError(r.t, json.Unmarshal(data, &manifest))
+		for _, blob := range append(manifest.Layers, manifest.Config) {
+			if _, exists := r.blobs[blob.Digest]; !exists {
+				writer.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+		tag := strings.TrimPrefix(p, "/manifests/")
+		r.manifests[tag] = data
+		r.mediaTypes[tag] = request.Header.Get("Content-Type")
+		writer.Header().Set("Docker-Content-Digest", fmt.Sprintf("sha256:%x", sha256.Sum256(data)))
+		writer.WriteHeader(http.StatusCreated)
+
+	default:
+		writer.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func writeBlob(t *testing.T, tw *tar.Writer, data []byte) descriptor {
+	digest := fmt.Sprintf("%x", sha256.Sum256(data))
+	writeFile(t, tw, "blobs/sha256/"+digest, data)
+	return descriptor{Digest: "sha256:" + digest, Size: int64(len(data))}
+}
+
+// Writes the file with a PAX header, which precedes the contents in the
+// archive, like the ones of the archives built by osbuild.
+func writeFile(t *testing.T, tw *tar.Writer, name string, data []byte) {
+	err := tw.WriteHeader(&tar.Header{
+		Name:       "./" + name,
+		Mode:       0644,
+		Size:       int64(len(data)),
+		Typeflag:   tar.TypeReg,
+		PAXRecords: map[string]string{"SCHILY.xattr.user.test": name},
+	})
+	require.NoError(t, err)
+	_, err = tw.Write(data)
+	require.NoError(t, err)
+}
+
+// Writes an OCI archive with a single image to dir and returns its path and
+// the digest of the manifest.
+func writeArchive(t *testing.T, dir string) (string, string) {
+	p := path.Join(dir, "container.tar")
+	f, err := os.Create(p)
+	require.NoError(t, err)
+	defer f.Close()
+
+	tw := tar.NewWriter(f)
+	writeFile(t, tw, "oci-layout", []byte(`{"imageLayoutVersion":"1.0.0"}`))
+
+	layer := writeBlob(t, tw, []byte("layer"))
+	layer.MediaType = "application/vnd.oci.image.layer.v1.tar"
+	config := writeBlob(t, tw, []byte(`{"architecture":"amd64","os":"linux"}`))
+	config.MediaType = "application/vnd.oci.image.config.v1+json"
+
+	manifestData, err := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"config":        config,
+		"layers":        []descriptor{layer},
+	})
+	require.NoError(t, err)
+	manifest := writeBlob(t, tw, manifestData)
+	manifest.MediaType = ociManifestMediaType
+
+	index, err := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"manifests":     []descriptor{manifest},
+	})
+	require.NoError(t, err)
+	writeFile(t, tw, "index.json", index)
+	require.NoError(t, tw.Close())
+
+	return p, manifest.Digest
+}
+
+func TestPush(t *testing.T) {
+	dir, err := ioutil.TempDir("", "registry-test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	archive, digest := writeArchive(t, dir)
+
+	for _, tokenAuth := range []bool{false, true} {
+		r := newTestRegistry(t, tokenAuth)
+		server := httptest.NewServer(r)
+
+		client, err := NewClient(server.URL, &Credentials{Username: "user", Password: "secret"})
+		require.NoError(t, err)
+		pushed, err := client.Push(archive, "library/test", "latest")
+		require.NoError(t, err)
+		assert.Equal(t, digest, pushed)
+		assert.Len(t, r.blobs, 2)
+		assert.Contains(t, r.manifests, "latest")
+		assert.Equal(t, ociManifestMediaType, r.mediaTypes["latest"])
+
+		// blobs which exist in the registry are not uploaded again
+		pushed, err = client.Push(archive, "library/test", "v1")
+		require.NoError(t, err)
+		assert.Equal(t, digest, pushed)
+		assert.Equal(t, 2, r.uploads)
+
+		client, err = NewClient(server.URL, &Credentials{Username: "user", Password: "wrong"})
+		require.NoError(t, err)
+		_, err = client.Push(archive, "library/test", "latest")
+		assert.Error(t, err)
+
+		server.Close()
+	}
+}
+
+func TestPushTimeout(t *testing.T) {
+	dir, err := ioutil.TempDir("", "registry-test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	archive, _ := writeArchive(t, dir)
+
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		<-done
+	}))
+	defer server.Close()
+	defer close(done)
+
+	client, err := NewClient(server.URL, nil)
+	require.NoError(t, err)
+	client.client.Transport.(*http.Transport).ResponseHeaderTimeout = 10 * time.Millisecond
+	_, err = client.Push(archive, "library/test", "latest")
+	assert.Error(t, err)
+}
+
+func TestReadAuthFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "registry-test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	authFile := path.Join(dir, "auth.json")
+	auth := base64.StdEncoding.EncodeToString([]byte("user:sec:ret"))
+	err = ioutil.WriteFile(authFile, []byte(`{"auths":{"registry.example.com":{"auth":"`+auth+`"}}}`), 0600)
+	require.NoError(t, err)
+
+	credentials, err := ReadAuthFile(authFile, "registry.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, &Credentials{Username: "user", Password: "sec:ret"}, credentials)
+
+	_, err = ReadAuthFile(authFile, "quay.io")
+	assert.Error(t, err)
+}
@@ -28,6 +28,7 @@ import (
 	"github.com/osbuild/osbuild-composer/internal/distro"
 	"github.com/osbuild/osbuild-composer/internal/jobqueue"
 	"github.com/osbuild/osbuild-composer/internal/osbuild"
+	"github.com/osbuild/osbuild-composer/internal/ostree"
 	"github.com/osbuild/osbuild-composer/internal/rpmmd"
 	"github.com/osbuild/osbuild-composer/internal/store"
 	"github.com/osbuild/osbuild-composer/internal/target"
@@ -47,6 +48,79 @@ type API struct {
 	router *httprouter.Router
 
 	compatOutputDir string
+
+	ostreeRepo    *ostree.Repo
+	ostreeImports chan ostreeImport
+}
+
+// An ostreeImport is the commit of a finished compose, which is waiting to
+// be imported into the repository.
+type ostreeImport struct {
+	composeId uuid.UUID
+	jobId     uuid.UUID
+	filename  string
+}
+
+// SetOSTreeRepo makes the API import the commits of finished composes of
+// image types, which build a commit, into repo. They are imported one after
+// another in the background, so that workers do not wait for it.
+func (api *API) SetOSTreeRepo(repo *ostree.Repo) {
+	api.ostreeRepo = repo
+	api.ostreeImports = make(chan ostreeImport, 64)
+	go api.importOSTreeCommits()
+	api.workers.OnJobFinished(api.queueOSTreeCommit)
+}
+
+func (api *API) queueOSTreeCommit(jobId uuid.UUID, targets []*target.Target, result *worker.OSBuildJobResult) {
+	if result.OSBuildOutput == nil || !result.OSBuildOutput.Success {
+		return
+	}
+
+	for _, t := range targets {
+		options, ok := t.Options.(*target.LocalTargetOptions)
+		if !ok {
+			continue
+		}
+
+		compose, exists := api.store.GetCompose(options.ComposeId)
+		if !exists || compose.ImageBuild.JobID != jobId || compose.ImageBuild.ImageType.OSTreeRef() == "" {
+			continue
+		}
+
+		// this runs while the worker waits for its job to be finished, so
+		// do not wait for the imports in front of this one
+		select {
+		case api.ostreeImports <- ostreeImport{
+			composeId: options.ComposeId,
+			jobId:     jobId,
+			filename:  compose.ImageBuild.ImageType.Filename(),
+		}:
+		default:
+			log.Printf("Not importing commit of compose %s: too many commits are waiting to be imported", options.ComposeId)
+		}
+		return
+	}
+}
+
+func (api *API) importOSTreeCommits() {
+	for i := range api.ostreeImports {
+		err := api.importOSTreeCommit(i)
+		if err != nil {
+			log.Printf("Error importing commit of compose %s: %v", i.composeId, err)
+		}
+	}
+}
+
+func (api *API) importOSTreeCommit(i ostreeImport) error {
+	reader, _, err := api.workers.JobArtifact(i.jobId, i.filename)
+	if err != nil {
+		return err
+	}
+	if closer, ok := reader.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	return api.ostreeRepo.Import(reader, i.composeId)
 }
 
 // systemRepoIDs returns a list of the system repos
@@ -162,11 +236,12 @@ func (api *API) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
 }
 
 type composeStatus struct {
-	State    common.ComposeState
-	Queued   time.Time
-	Started  time.Time
-	Finished time.Time
-	Result   *osbuild.Result
+	State         common.ComposeState
+	Queued        time.Time
+	Started       time.Time
+	Finished      time.Time
+	Result        *osbuild.Result
+	TargetResults []*target.TargetResult
 }
 
 // Returns the state of the image in `compose` and the times the job was
@@ -202,20 +277,98 @@ func (api *API) getComposeStatus(compose store.Compose) *composeStatus {
 	// is it ok to ignore this error?
 	jobStatus, _ := api.workers.JobStatus(jobId)
 	return &composeStatus{
-		State:    jobStatus.State,
-		Queued:   jobStatus.Queued,
-		Started:  jobStatus.Started,
-		Finished: jobStatus.Finished,
-		Result:   jobStatus.Result.OSBuildOutput,
+		State:         jobStatus.State,
+		Queued:        jobStatus.Queued,
+		Started:       jobStatus.Started,
+		Finished:      jobStatus.Finished,
+		Result:        jobStatus.Result.OSBuildOutput,
+		TargetResults: jobStatus.Result.TargetResults,
 	}
 }
 
-// Opens the image file for `compose`. This asks the worker server for the
-// artifact first, and then falls back to looking in
-// `{outputs}/{composeId}/{imageBuildId}` for backwards compatibility.
+// imageFilename returns the name of the file users download for images of
+// `imageType`. Images, which consist of more than one file, are served as a
+// tarball of all of them.
+func imageFilename(imageType distro.ImageType) string {
+	if len(imageType.Filenames()) > 1 {
+		return imageType.Name() + ".tar"
+	}
+	return imageType.Filename()
+}
+
+// localTargetFilenames returns the files of `imageType`, which the worker
+// uploads as artifacts. It is nil for images consisting of a single file,
+// which only need the target's Filename.
+func localTargetFilenames(imageType distro.ImageType) []string {
+	if len(imageType.Filenames()) > 1 {
+		return imageType.Filenames()
+	}
+	return nil
+}
+
+// Opens the image file for `compose`. If the image consists of more than one
+// file, a tarball of all of them is returned.
 func (api *API) openImageFile(composeId uuid.UUID, compose store.Compose) (io.Reader, int64, error) {
-	name := compose.ImageBuild.ImageType.Filename()
+	names := compose.ImageBuild.ImageType.Filenames()
+	if len(names) == 1 {
+		return api.openArtifact(composeId, compose, names[0])
+	}
+
+	readers := make([]io.Reader, 0, len(names))
+	sizes := make([]int64, 0, len(names))
+	closeReaders := func() {
+		for _, reader := range readers {
+			if closer, ok := reader.(io.Closer); ok {
+				closer.Close()
+			}
+		}
+	}
+
+	// Each file is preceded by a header block and padded to a full block.
+	// The archive ends with two zero blocks.
+	var size int64 = 2 * 512
+	for _, name := range names {
+		reader, fileSize, err := api.openArtifact(composeId, compose, name)
+		if err != nil {
+			closeReaders()
+			return nil, 0, err
+		}
+		readers = append(readers, reader)
+		sizes = append(sizes, fileSize)
+		size += 512 + (fileSize+511)/512*512
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		defer closeReaders()
 
+		tw := tar.NewWriter(pw)
+		for i, name := range names {
+			hdr := &tar.Header{
+				Name:    name,
+				Mode:    0644,
+				Size:    sizes[i],
+				ModTime: time.Now().Truncate(time.Second),
+			}
+			err := tw.WriteHeader(hdr)
+			if err == nil {
+				_, err = io.Copy(tw, readers[i])
+			}
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+		pw.CloseWithError(tw.Close())
+	}()
+
+	return pr, size, nil
+}
+
+// Opens the artifact `name` of `compose`. This asks the worker server for the
+// artifact first, and then falls back to looking in
+// `{outputs}/{composeId}/{imageBuildId}` for backwards compatibility.
+func (api *API) openArtifact(composeId uuid.UUID, compose store.Compose, name string) (io.Reader, int64, error) {
 	reader, size, err := api.workers.JobArtifact(compose.ImageBuild.JobID, name)
 	if err != nil {
 		if api.compatOutputDir == "" || err != jobqueue.ErrNotExist {
@@ -1032,7 +1185,7 @@ func (api *API) blueprintsInfoHandler(writer http.ResponseWriter, request *http.
 			})
 			continue
 		}
-		blueprints = append(blueprints, *blueprint)
+		blueprints = append(blueprints, blueprint.Redacted())
 		changes = append(changes, change{changed, blueprint.Name})
 	}
 
@@ -1079,9 +1232,12 @@ func (api *API) blueprintsDepsolveHandler(writer http.ResponseWriter, request *h
 		return
 	}
 
+	// the estimated size only covers the packages of the blueprint, as
+	// the packages of the image type are not known yet
 	type entry struct {
-		Blueprint    blueprint.Blueprint `json:"blueprint"`
-		Dependencies []rpmmd.PackageSpec `json:"dependencies"`
+		Blueprint     blueprint.Blueprint `json:"blueprint"`
+		Dependencies  []rpmmd.PackageSpec `json:"dependencies"`
+		EstimatedSize uint64              `json:"estimated_size"`
 	}
 	type reply struct {
 		Blueprints []entry         `json:"blueprints"`
@@ -1128,7 +1284,7 @@ func (api *API) blueprintsDepsolveHandler(writer http.ResponseWriter, request *h
 			dependencies = []rpmmd.PackageSpec{}
 		}
 
-		blueprints = append(blueprints, entry{*blueprint, dependencies})
+		blueprints = append(blueprints, entry{blueprint.Redacted(), dependencies, distro.EstimateSize(dependencies)})
 	}
 
 	err := json.NewEncoder(writer).Encode(reply{
@@ -1206,7 +1362,7 @@ func (api *API) blueprintsFreezeHandler(writer http.ResponseWriter, request *htt
 			break
 		}
 		// Make a copy of the blueprint since we will be replacing the version globs
-		blueprint := bp.DeepCopy()
+		blueprint := bp.Redacted()
 		dependencies, _, err := api.depsolveBlueprint(&blueprint, nil)
 		if err != nil {
 			rerr := responseError{
@@ -1286,8 +1442,8 @@ func (api *API) blueprintsDiffHandler(writer http.ResponseWriter, request *http.
 	}
 
 	type diff struct {
-		New *pack `json:"new"`
-		Old *pack `json:"old"`
+		New interface{} `json:"new"`
+		Old interface{} `json:"old"`
 	}
 
 	type reply struct {
@@ -1375,6 +1531,40 @@ func (api *API) blueprintsDiffHandler(writer http.ResponseWriter, request *http.
 		diffs = append(diffs, diff{Old: &pack{oldPackage}, New: nil})
 	}
 
+	// Customizations are compared section by section, like lorax does, and
+	// reported as {"Customizations.<section>": value}
+	oldRedacted := oldBlueprint.Redacted()
+	newRedacted := newBlueprint.Redacted()
+	oldCustomizations := customizationsSections(oldRedacted.Customizations)
+	newCustomizations := customizationsSections(newRedacted.Customizations)
+	var sections []string
+	for section := range oldCustomizations {
+		sections = append(sections, section)
+	}
+	for section := range newCustomizations {
+		if _, exists := oldCustomizations[section]; !exists {
+			sections = append(sections, section)
+		}
+	}
+	sort.Strings(sections)
+
+	for _, section := range sections {
+		oldValue, oldExists := oldCustomizations[section]
+		newValue, newExists := newCustomizations[section]
+		if oldExists && newExists && bytes.Equal(oldValue, newValue) {
+			continue
+		}
+
+		d := diff{}
+		if oldExists {
+			d.Old = map[string]json.RawMessage{"Customizations." + section: oldValue}
+		}
+		if newExists {
+			d.New = map[string]json.RawMessage{"Customizations." + section: newValue}
+		}
+		diffs = append(diffs, d)
+	}
+
 	err := json.NewEncoder(writer).Encode(reply{diffs})
 	common.PanicOnError(err)
 }
@@ -1691,9 +1881,30 @@ func (api *API) composeHandler(writer http.ResponseWriter, request *http.Request
 		return
 	}
 
+	type OSTreeSignRequest struct {
+		Type string `json:"type"`
+		// The name of the key on the worker
+		Key string `json:"key"`
+	}
+
 	type OSTreeRequest struct {
 		Ref    string `json:"ref"`
 		Parent string `json:"parent"`
+		// The repository to pull the parent from
+		URL          string             `json:"url,omitempty"`
+		Sign         *OSTreeSignRequest `json:"sign,omitempty"`
+		StaticDeltas bool               `json:"static_deltas,omitempty"`
+		// The compose of the commit, which an installer deploys
+		Compose string `json:"compose,omitempty"`
+	}
+
+	// The virtual machine in the OVF descriptor of ova images. Memory is
+	// given in MiB.
+	type OVFRequest struct {
+		CPUs    uint   `json:"cpus,omitempty"`
+		Memory  uint64 `json:"memory,omitempty"`
+		Network string `json:"network,omitempty"`
+		NICType string `json:"nic_type,omitempty"`
 	}
 
 	// https://weldr.io/lorax/pylorax.api.html#pylorax.api.v0.v0_compose_start
@@ -1702,6 +1913,7 @@ func (api *API) composeHandler(writer http.ResponseWriter, request *http.Request
 		ComposeType   string         `json:"compose_type"`
 		Size          uint64         `json:"size"`
 		OSTree        OSTreeRequest  `json:"ostree"`
+		OVF           OVFRequest     `json:"ovf"`
 		Branch        string         `json:"branch"`
 		Upload        *uploadRequest `json:"upload"`
 	}
@@ -1750,16 +1962,24 @@ func (api *API) composeHandler(writer http.ResponseWriter, request *http.Request
 
 	var targets []*target.Target
 	if isRequestVersionAtLeast(params, 1) && cr.Upload != nil {
+		if cr.Upload.Provider == "container-registry" && imageType.Name() != "container" {
+			errors := responseError{
+				ID:  "UploadError",
+				Msg: fmt.Sprintf("Compose type %s cannot be pushed to a container registry", imageType.Name()),
+			}
+			statusResponseError(writer, http.StatusBadRequest, errors)
+			return
+		}
 		t := uploadRequestToTarget(*cr.Upload, imageType)
 		targets = append(targets, t)
 	}
 
 	targets = append(targets, target.NewLocalTarget(
 		&target.LocalTargetOptions{
-			ComposeId:       composeID,
-			ImageBuildId:    0,
-			Filename:        imageType.Filename(),
-			StreamOptimized: imageType.Name() == "vmdk", // TODO: move conversion to osbuild
+			ComposeId:    composeID,
+			ImageBuildId: 0,
+			Filename:     imageType.Filename(),
+			Filenames:    localTargetFilenames(imageType),
 		},
 	))
 
@@ -1773,6 +1993,18 @@ func (api *API) composeHandler(writer http.ResponseWriter, request *http.Request
 		return
 	}
 
+	if bp.Customizations.GetCloudInit() != nil {
+		imageTypePackages, _ := imageType.Packages(*bp)
+		if !containsPackage(imageTypePackages, "cloud-init") {
+			errors := responseError{
+				ID:  "InvalidCustomization",
+				Msg: fmt.Sprintf("Compose type %s does not include cloud-init, remove the cloudinit customization from the blueprint", imageType.Name()),
+			}
+			statusResponseError(writer, http.StatusBadRequest, errors)
+			return
+		}
+	}
+
 	packages, buildPackages, err := api.depsolveBlueprint(bp, imageType)
 	if err != nil {
 		errors := responseError{
@@ -1794,15 +2026,131 @@ func (api *API) composeHandler(writer http.ResponseWriter, request *http.Request
 		return
 	}
 
-	size := imageType.Size(cr.Size)
-	manifest, err := imageType.Manifest(bp.Customizations,
-		distro.ImageOptions{
-			Size: size,
-			OSTree: distro.OSTreeImageOptions{
-				Ref:    cr.OSTree.Ref,
-				Parent: cr.OSTree.Parent,
-			},
+	// An explicitly requested size, either in the compose request or in the
+	// blueprint, must fit the packages. The default size of the image type
+	// grows instead.
+	size := cr.Size
+	if size == 0 {
+		size = bp.Customizations.GetSize()
+	}
+	estimatedSize := distro.EstimateSize(packages)
+	if size != 0 && size < estimatedSize {
+		errors := responseError{
+			ID:  "InvalidSize",
+			Msg: fmt.Sprintf("Image size of %d bytes is too small, the packages need an estimated %d bytes", size, estimatedSize),
+		}
+		statusResponseError(writer, http.StatusBadRequest, errors)
+		return
+	}
+	size = imageType.Size(size)
+	if size < estimatedSize {
+		size = imageType.Size(estimatedSize)
+	}
+
+	// An installer deploys the commit of an earlier compose, which it takes
+	// as an input. The installer job runs after the commit job finished.
+	var inputs []worker.OSBuildJobInput
+	if imageType.Name() == "rhel-edge-installer" {
+		commitID, err := uuid.Parse(cr.OSTree.Compose)
+		if err != nil {
+			errors := responseError{
+				ID:  "UnknownUUID",
+				Msg: fmt.Sprintf("Compose type %s requires the UUID of a commit compose", imageType.Name()),
+			}
+			statusResponseError(writer, http.StatusBadRequest, errors)
+			return
+		}
+
+		commit, exists := api.store.GetCompose(commitID)
+		if !exists {
+			errors := responseError{
+				ID:  "UnknownUUID",
+				Msg: fmt.Sprintf("Compose %s doesn't exist", commitID),
+			}
+			statusResponseError(writer, http.StatusBadRequest, errors)
+			return
+		}
+
+		if commit.ImageBuild.ImageType.Name() != "rhel-edge-commit" || commit.ImageBuild.JobID == uuid.Nil {
+			errors := responseError{
+				ID:  "InvalidCompose",
+				Msg: fmt.Sprintf("Compose %s is not a rhel-edge-commit compose", commitID),
+			}
+			statusResponseError(writer, http.StatusBadRequest, errors)
+			return
+		}
+
+		// An installer waits for its commit compose to finish, but one
+		// which already failed would never provide the commit.
+		if api.getComposeStatus(commit).State == common.CFailed {
+			errors := responseError{
+				ID:  "InvalidCompose",
+				Msg: fmt.Sprintf("Compose %s failed and has no commit", commitID),
+			}
+			statusResponseError(writer, http.StatusBadRequest, errors)
+			return
+		}
+
+		inputs = append(inputs, worker.OSBuildJobInput{
+			JobID: commit.ImageBuild.JobID,
+			Name:  commit.ImageBuild.ImageType.Filename(),
+		})
+	}
+
+	imageOptions := distro.ImageOptions{
+		Size: size,
+		OSTree: distro.OSTreeImageOptions{
+			Ref:          cr.OSTree.Ref,
+			Parent:       cr.OSTree.Parent,
+			URL:          cr.OSTree.URL,
+			StaticDeltas: cr.OSTree.StaticDeltas,
 		},
+	}
+	if cr.OSTree.Sign != nil {
+		imageOptions.OSTree.SignType = cr.OSTree.Sign.Type
+		imageOptions.OSTree.SignKey = cr.OSTree.Sign.Key
+	}
+	if err := imageOptions.OSTree.Validate(); err != nil {
+		errors := responseError{
+			ID:  "OSTreeOptionsError",
+			Msg: err.Error(),
+		}
+		statusResponseError(writer, http.StatusBadRequest, errors)
+		return
+	}
+	if err := imageOptions.OSTree.ResolveParent(imageType.OSTreeRef()); err != nil {
+		errors := responseError{
+			ID:  "OSTreeOptionsError",
+			Msg: err.Error(),
+		}
+		statusResponseError(writer, http.StatusBadRequest, errors)
+		return
+	}
+	imageOptions.OVF = distro.OVFImageOptions{
+		CPUs:    cr.OVF.CPUs,
+		Memory:  cr.OVF.Memory,
+		Network: cr.OVF.Network,
+		NICType: cr.OVF.NICType,
+	}
+	if err := imageOptions.OVF.Validate(); err != nil {
+		errors := responseError{
+			ID:  "OVFOptionsError",
+			Msg: err.Error(),
+		}
+		statusResponseError(writer, http.StatusBadRequest, errors)
+		return
+	}
+	if subscription := bp.Customizations.GetSubscription(); subscription != nil {
+		imageOptions.Subscription = &distro.SubscriptionImageOptions{
+			Organization:  subscription.Organization,
+			ActivationKey: subscription.ActivationKey,
+			ServerUrl:     subscription.ServerURL,
+			BaseUrl:       subscription.BaseURL,
+			Insights:      subscription.Insights,
+		}
+	}
+	manifest, err := imageType.Manifest(bp.Customizations,
+		imageOptions,
 		api.allRepositories(),
 		packages,
 		buildPackages)
@@ -1825,7 +2173,7 @@ func (api *API) composeHandler(writer http.ResponseWriter, request *http.Request
 	} else {
 		var jobId uuid.UUID
 
-		jobId, err = api.workers.Enqueue(api.arch.Name(), manifest, targets)
+		jobId, err = api.workers.EnqueueWithInputs(api.arch.Name(), manifest, targets, inputs)
 		if err == nil {
 			err = api.store.PushCompose(composeID, manifest, imageType, bp, size, targets, jobId)
 		}
@@ -2153,7 +2501,10 @@ func (api *API) composeInfoHandler(writer http.ResponseWriter, request *http.Req
 	}
 
 	reply.ID = id
-	reply.Blueprint = compose.Blueprint
+	if compose.Blueprint != nil {
+		redacted := compose.Blueprint.Redacted()
+		reply.Blueprint = &redacted
+	}
 	reply.Deps = Dependencies{
 		Packages: make([]map[string]interface{}, 0),
 	}
@@ -2165,7 +2516,7 @@ func (api *API) composeInfoHandler(writer http.ResponseWriter, request *http.Req
 	reply.ImageSize = compose.ImageBuild.Size
 
 	if isRequestVersionAtLeast(params, 1) {
-		reply.Uploads = targetsToUploadResponses(compose.ImageBuild.Targets, composeStatus.State)
+		reply.Uploads = targetsToUploadResponses(compose.ImageBuild.Targets, composeStatus.TargetResults, composeStatus.State)
 	}
 
 	err = json.NewEncoder(writer).Encode(reply)
@@ -2208,7 +2559,7 @@ func (api *API) composeImageHandler(writer http.ResponseWriter, request *http.Re
 		return
 	}
 
-	imageName := compose.ImageBuild.ImageType.Filename()
+	imageName := imageFilename(compose.ImageBuild.ImageType)
 	imageMime := compose.ImageBuild.ImageType.MIMEType()
 
 	reader, fileSize, err := api.openImageFile(uuid, compose)
@@ -2220,6 +2571,9 @@ func (api *API) composeImageHandler(writer http.ResponseWriter, request *http.Re
 		statusResponseError(writer, http.StatusBadRequest, errors)
 		return
 	}
+	if closer, ok := reader.(io.Closer); ok {
+		defer closer.Close()
+	}
 
 	writer.Header().Set("Content-Disposition", "attachment; filename="+uuid.String()+"-"+imageName)
 	writer.Header().Set("Content-Type", imageMime)
@@ -2366,8 +2720,11 @@ func (api *API) composeResultsHandler(writer http.ResponseWriter, request *http.
 
 	reader, fileSize, err := api.openImageFile(uuid, compose)
 	if err == nil {
+		if closer, ok := reader.(io.Closer); ok {
+			defer closer.Close()
+		}
 		hdr = &tar.Header{
-			Name:    uuid.String() + "-" + compose.ImageBuild.ImageType.Filename(),
+			Name:    uuid.String() + "-" + imageFilename(compose.ImageBuild.ImageType),
 			Mode:    0644,
 			Size:    int64(fileSize),
 			ModTime: time.Now().Truncate(time.Second),
@@ -2569,7 +2926,7 @@ func (api *API) depsolveBlueprint(bp *blueprint.Blueprint, imageType distro.Imag
 
 	buildPackages := []rpmmd.PackageSpec{}
 	if imageType != nil {
-		buildSpecs := imageType.BuildPackages()
+		buildSpecs := imageType.BuildPackages(*bp)
 		buildPackages, _, err = api.rpmmd.Depsolve(buildSpecs, nil, repos, api.distro.ModulePlatformID(), api.arch.Name())
 		if err != nil {
 			return nil, nil, err
@@ -3,12 +3,17 @@ package weldr
 import (
 	"archive/tar"
 	"bytes"
+	"context"
+	"encoding/json"
 	"io"
+	"io/ioutil"
 	"math/rand"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -16,14 +21,20 @@ import (
 	"github.com/osbuild/osbuild-composer/internal/common"
 	"github.com/osbuild/osbuild-composer/internal/distro"
 	test_distro "github.com/osbuild/osbuild-composer/internal/distro/fedoratest"
+	"github.com/osbuild/osbuild-composer/internal/distro/rhel8"
+	"github.com/osbuild/osbuild-composer/internal/jobqueue/testjobqueue"
 	rpmmd_mock "github.com/osbuild/osbuild-composer/internal/mocks/rpmmd"
+	"github.com/osbuild/osbuild-composer/internal/osbuild"
+	"github.com/osbuild/osbuild-composer/internal/ostree"
 	"github.com/osbuild/osbuild-composer/internal/rpmmd"
 	"github.com/osbuild/osbuild-composer/internal/store"
 	"github.com/osbuild/osbuild-composer/internal/target"
 	"github.com/osbuild/osbuild-composer/internal/test"
+	"github.com/osbuild/osbuild-composer/internal/worker"
 
 	"github.com/BurntSushi/toml"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 )
 
@@ -80,6 +91,12 @@ func TestBlueprintsNew(t *testing.T) {
 		{"POST", "/api/v0/blueprints/new", `{"name":"test","description":"Test","packages:}`, http.StatusBadRequest, `{"status":false,"errors":[{"id":"BlueprintsError","msg":"400 Bad Request: The browser (or proxy) sent a request that this server could not understand: unexpected EOF"}]}`},
 		{"POST", "/api/v0/blueprints/new", `{"name":"","description":"Test","packages":[{"name":"httpd","version":"2.4.*"}],"version":"0.0.0"}`, http.StatusBadRequest, `{"status":false,"errors":[{"id":"InvalidChars","msg":"Invalid characters in API path"}]}`},
 		{"POST", "/api/v0/blueprints/new", ``, http.StatusBadRequest, `{"status":false,"errors":[{"id":"BlueprintsError","msg":"Missing blueprint"}]}`},
+		{"POST", "/api/v0/blueprints/new", `{"name":"test","description":"Test","packages":[],"version":"0.0.0","customizations":{"selinux":{"mode":"permissive","booleans":["httpd_can_network_connect=on"]}}}`, http.StatusOK, `{"status":true}`},
+		{"POST", "/api/v0/blueprints/new", `{"name":"test","description":"Test","packages":[],"version":"0.0.0","customizations":{"selinux":{"mode":"disabled"}}}`, http.StatusBadRequest, `{"status":false,"errors":[{"id":"BlueprintsError","msg":"Invalid SELinux mode: disabled"}]}`},
+		{"POST", "/api/v0/blueprints/new", `{"name":"test","description":"Test","packages":[],"version":"0.0.0","customizations":{"network":{"connections":[{"name":"eth0","interface":"eth0","ipv4":{"addresses":["192.168.1.10/24"],"gateway":"192.168.1.1"}}]}}}`, http.StatusOK, `{"status":true}`},
+		{"POST", "/api/v0/blueprints/new", `{"name":"test","description":"Test","packages":[],"version":"0.0.0","customizations":{"network":{"connections":[{"name":"eth0","interface":"eth0","ipv4":{"addresses":["192.168.1.300/24"]}}]}}}`, http.StatusBadRequest, `{"status":false,"errors":[{"id":"BlueprintsError","msg":"Invalid IPv4 address for network connection eth0: 192.168.1.300/24"}]}`},
+		{"POST", "/api/v0/blueprints/new", `{"name":"test","description":"Test","packages":[],"version":"0.0.0","customizations":{"firewall":{"default_zone":"dmz","zones":[{"name":"trusted","sources":["10.0.0.0/8"]}]}}}`, http.StatusOK, `{"status":true}`},
+		{"POST", "/api/v0/blueprints/new", `{"name":"test","description":"Test","packages":[],"version":"0.0.0","customizations":{"firewall":{"zones":[{"name":"trusted","sources":["10.0.0.0/64"]}]}}}`, http.StatusBadRequest, `{"status":false,"errors":[{"id":"BlueprintsError","msg":"Invalid source for firewall zone trusted: 10.0.0.0/64"}]}`},
 	}
 
 	for _, c := range cases {
@@ -241,6 +258,17 @@ func TestBlueprintsInfo(t *testing.T) {
 	}
 }
 
+func TestBlueprintsInfoSubscription(t *testing.T) {
+	api, _ := createWeldrAPI(rpmmd_mock.BaseFixture)
+	test.SendHTTP(api, true, "POST", "/api/v0/blueprints/new", `{"name":"test-subscription","description":"Test","packages":[],"version":"0.0.0","customizations":{"subscription":{"organization":2040324,"activation_key":"my-secret-key","server_url":"subscription.rhsm.redhat.com","base_url":"http://cdn.redhat.com/","insights":true}}}`)
+	test.TestRoute(t, api, true, "GET", "/api/v0/blueprints/info/test-subscription", ``, http.StatusOK, `{"blueprints":[{"name":"test-subscription","description":"Test","modules":[],"packages":[],"groups":[],"version":"0.0.0","customizations":{"subscription":{"organization":2040324,"server_url":"subscription.rhsm.redhat.com","base_url":"http://cdn.redhat.com/","insights":true}}}],
+		"changes":[{"name":"test-subscription","changed":false}], "errors":[]}`)
+	// pushing the redacted blueprint back fails instead of dropping the key
+	resp := test.SendHTTP(api, true, "POST", "/api/v0/blueprints/new", `{"name":"test-subscription","description":"Test","packages":[],"version":"0.0.0","customizations":{"subscription":{"organization":2040324,"server_url":"subscription.rhsm.redhat.com","base_url":"http://cdn.redhat.com/","insights":true}}}`)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	test.SendHTTP(api, true, "DELETE", "/api/v0/blueprints/delete/test-subscription", ``)
+}
+
 func TestBlueprintsInfoToml(t *testing.T) {
 	api, _ := createWeldrAPI(rpmmd_mock.BaseFixture)
 	test.SendHTTP(api, true, "POST", "/api/v0/blueprints/new", `{"name":"test1","description":"Test","packages":[{"name":"httpd","version":"2.4.*"}],"version":"0.0.0"}`)
@@ -269,6 +297,31 @@ func TestBlueprintsInfoToml(t *testing.T) {
 	require.Equalf(t, expected, got, "received unexpected blueprint")
 }
 
+func TestBlueprintsCustomizationsToml(t *testing.T) {
+	blueprint := `
+name = "test-firstboot"
+description = "Test"
+version = "0.0.0"
+
+[customizations.firstboot]
+commands = ["/usr/bin/touch /etc/first-boot-done"]
+wait_for_network = true`
+
+	req := httptest.NewRequest("POST", "/api/v0/blueprints/new", bytes.NewReader([]byte(blueprint)))
+	req.Header.Set("Content-Type", "text/x-toml")
+	recorder := httptest.NewRecorder()
+
+	api, _ := createWeldrAPI(rpmmd_mock.BaseFixture)
+	api.ServeHTTP(recorder, req)
+
+	r := recorder.Result()
+	require.Equal(t, http.StatusOK, r.StatusCode)
+
+	test.TestRoute(t, api, true, "GET", "/api/v0/blueprints/info/test-firstboot", ``, http.StatusOK, `{"blueprints":[{"name":"test-firstboot","description":"Test","modules":[],"packages":[],"groups":[],"version":"0.0.0",
+	"customizations":{"firstboot":{"commands":["/usr/bin/touch /etc/first-boot-done"],"wait_for_network":true}}}],
+	"changes":[{"name":"test-firstboot","changed":false}], "errors":[]}`)
+}
+
 func TestNonExistentBlueprintsInfoToml(t *testing.T) {
 	api, _ := createWeldrAPI(rpmmd_mock.BaseFixture)
 	req := httptest.NewRequest("GET", "/api/v0/blueprints/info/test3-non?format=toml", nil)
@@ -400,6 +453,14 @@ func TestBlueprintsDiff(t *testing.T) {
 	}
 }
 
+func TestBlueprintsDiffCustomizations(t *testing.T) {
+	api, _ := createWeldrAPI(rpmmd_mock.BaseFixture)
+	test.SendHTTP(api, true, "POST", "/api/v0/blueprints/new", `{"name":"test","description":"Test","packages":[],"version":"0.0.0","customizations":{"hostname":"old"}}`)
+	test.SendHTTP(api, true, "POST", "/api/v0/blueprints/workspace", `{"name":"test","description":"Test","packages":[],"version":"0.0.0","customizations":{"firstboot":{"commands":["/usr/bin/true"],"wait_for_network":true}}}`)
+	test.TestRoute(t, api, true, "GET", "/api/v0/blueprints/diff/test/NEWEST/WORKSPACE", ``, http.StatusOK, `{"diff":[{"new":{"Customizations.firstboot":{"commands":["/usr/bin/true"],"wait_for_network":true}},"old":null},{"new":null,"old":{"Customizations.hostname":"old"}}]}`)
+	test.SendHTTP(api, true, "DELETE", "/api/v0/blueprints/delete/test", ``)
+}
+
 func TestBlueprintsDelete(t *testing.T) {
 	var cases = []struct {
 		Method         string
@@ -441,9 +502,9 @@ func TestBlueprintsDepsolve(t *testing.T) {
 		ExpectedStatus int
 		ExpectedJSON   string
 	}{
-		{rpmmd_mock.BaseFixture, http.StatusOK, `{"blueprints":[{"blueprint":{"name":"test","description":"Test","version":"0.0.1","packages":[{"name":"dep-package1","version":"*"}],"groups":[],"modules":[{"name":"dep-package3","version":"*"}]},"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64"},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64"},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64"}]}],"errors":[]}`},
-		{rpmmd_mock.NonExistingPackage, http.StatusOK, `{"blueprints":[{"blueprint":{"name":"test","description":"Test","version":"0.0.1","packages":[{"name":"dep-package1","version":"*"}],"groups":[],"modules":[{"name":"dep-package3","version":"*"}]},"dependencies":[]}],"errors":[{"id":"BlueprintsError","msg":"test: DNF error occured: MarkingErrors: Error occurred when marking packages for installation: Problems in request:\nmissing packages: fash"}]}`},
-		{rpmmd_mock.BadDepsolve, http.StatusOK, `{"blueprints":[{"blueprint":{"name":"test","description":"Test","version":"0.0.1","packages":[{"name":"dep-package1","version":"*"}],"groups":[],"modules":[{"name":"dep-package3","version":"*"}]},"dependencies":[]}],"errors":[{"id":"BlueprintsError","msg":"test: DNF error occured: DepsolveError: There was a problem depsolving ['go2rpm']: \n Problem: conflicting requests\n  - nothing provides askalono-cli needed by go2rpm-1-4.fc31.noarch"}]}`},
+		{rpmmd_mock.BaseFixture, http.StatusOK, `{"blueprints":[{"blueprint":{"name":"test","description":"Test","version":"0.0.1","packages":[{"name":"dep-package1","version":"*"}],"groups":[],"modules":[{"name":"dep-package3","version":"*"}]},"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64","install_size":3145728},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64","install_size":1048576},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64","install_size":2097152}],"estimated_size":9437184}],"errors":[]}`},
+		{rpmmd_mock.NonExistingPackage, http.StatusOK, `{"blueprints":[{"blueprint":{"name":"test","description":"Test","version":"0.0.1","packages":[{"name":"dep-package1","version":"*"}],"groups":[],"modules":[{"name":"dep-package3","version":"*"}]},"dependencies":[],"estimated_size":0}],"errors":[{"id":"BlueprintsError","msg":"test: DNF error occured: MarkingErrors: Error occurred when marking packages for installation: Problems in request:\nmissing packages: fash"}]}`},
+		{rpmmd_mock.BadDepsolve, http.StatusOK, `{"blueprints":[{"blueprint":{"name":"test","description":"Test","version":"0.0.1","packages":[{"name":"dep-package1","version":"*"}],"groups":[],"modules":[{"name":"dep-package3","version":"*"}]},"dependencies":[],"estimated_size":0}],"errors":[{"id":"BlueprintsError","msg":"test: DNF error occured: DepsolveError: There was a problem depsolving ['go2rpm']: \n Problem: conflicting requests\n  - nothing provides askalono-cli needed by go2rpm-1-4.fc31.noarch"}]}`},
 	}
 
 	for _, c := range cases {
@@ -474,6 +535,7 @@ func TestCompose(t *testing.T) {
 			QueueStatus: common.IBWaiting,
 			ImageType:   imgType,
 			Manifest:    manifest,
+			Size:        9437184,
 			Targets: []*target.Target{
 				{
 					// skip Uuid and Created fields - they are ignored
@@ -498,6 +560,7 @@ func TestCompose(t *testing.T) {
 			QueueStatus: common.IBWaiting,
 			ImageType:   imgType,
 			Manifest:    manifest,
+			Size:        9437184,
 			Targets: []*target.Target{
 				{
 					Name:      "org.osbuild.aws",
@@ -522,7 +585,6 @@ func TestCompose(t *testing.T) {
 			},
 		},
 	}
-
 	var cases = []struct {
 		External        bool
 		Method          string
@@ -565,6 +627,175 @@ func TestCompose(t *testing.T) {
 	}
 }
 
+func TestComposeContainerRegistry(t *testing.T) {
+	arch, err := rhel8.New().GetArch("x86_64")
+	require.NoError(t, err)
+	imageType, err := arch.GetImageType("container")
+	require.NoError(t, err)
+
+	var cases = []struct {
+		ComposeType    string
+		ExpectedStatus int
+		ExpectedJSON   string
+	}{
+		{"container", http.StatusOK, `{"status":true}`},
+		{"qcow2", http.StatusBadRequest, `{"status":false,"errors":[{"id":"UploadError","msg":"Compose type qcow2 cannot be pushed to a container registry"}]}`},
+	}
+
+	for _, c := range cases {
+		fixture := rpmmd_mock.NoComposesFixture()
+		api := New(rpmmd_mock.NewRPMMDMock(fixture), arch, rhel8.New(), nil, nil, fixture.Store, fixture.Workers, "")
+		body := `{"blueprint_name":"test","compose_type":"` + c.ComposeType + `","branch":"master","upload":{"image_name":"test_upload","provider":"container-registry","settings":{"registry":"registry.example.com","repository":"library/test","tag":"latest","username":"user","password":"secret"}}}`
+		test.TestRoute(t, api, false, "POST", "/api/v1/compose", body, c.ExpectedStatus, c.ExpectedJSON, "build_id")
+
+		composes := fixture.Store.GetAllComposes()
+		if c.ExpectedStatus != http.StatusOK {
+			require.Empty(t, composes)
+			continue
+		}
+		require.Len(t, composes, 1)
+		for _, compose := range composes {
+			require.Len(t, compose.ImageBuild.Targets, 2)
+			options, ok := compose.ImageBuild.Targets[0].Options.(*target.ContainerRegistryTargetOptions)
+			require.True(t, ok)
+			require.Equal(t, imageType.Filename(), options.Filename)
+			require.Equal(t, "registry.example.com", options.Registry)
+			require.Equal(t, "library/test", options.Repository)
+			require.Equal(t, "latest", options.Tag)
+		}
+	}
+}
+
+func TestComposeCloudInitUnsupported(t *testing.T) {
+	api, s := createWeldrAPI(rpmmd_mock.NoComposesFixture)
+	test.SendHTTP(api, false, "POST", "/api/v0/blueprints/new", `{"name":"test-cloudinit","description":"Test","packages":[],"version":"0.0.0","customizations":{"cloudinit":{"default_user":"cloud-user"}}}`)
+	test.TestRoute(t, api, false, "POST", "/api/v0/compose", `{"blueprint_name": "test-cloudinit","compose_type": "qcow2","branch": "master"}`, http.StatusBadRequest, `{"status":false,"errors":[{"id":"InvalidCustomization","msg":"Compose type qcow2 does not include cloud-init, remove the cloudinit customization from the blueprint"}]}`)
+	require.Empty(t, s.GetAllComposes())
+}
+
+func TestComposeSubscription(t *testing.T) {
+	api, s := createWeldrAPI(rpmmd_mock.NoComposesFixture)
+	test.SendHTTP(api, false, "POST", "/api/v0/blueprints/new", `{"name":"test-subscription","description":"Test","packages":[],"version":"0.0.0","customizations":{"subscription":{"organization":2040324,"activation_key":"my-secret-key","server_url":"subscription.rhsm.redhat.com","base_url":"http://cdn.redhat.com/"}}}`)
+	test.TestRoute(t, api, false, "POST", "/api/v0/compose", `{"blueprint_name": "test-subscription","compose_type": "qcow2","branch": "master"}`, http.StatusOK, `{"status":true}`, "build_id")
+
+	composes := s.GetAllComposes()
+	require.Len(t, composes, 1)
+	for id, compose := range composes {
+		require.Equal(t, "my-secret-key", compose.Blueprint.Customizations.Subscription.ActivationKey)
+
+		resp := test.SendHTTP(api, false, "GET", "/api/v0/compose/info/"+id.String(), ``)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NotContains(t, string(body), "my-secret-key")
+		require.Contains(t, string(body), `"organization":2040324`)
+	}
+}
+
+func TestComposeSize(t *testing.T) {
+	var cases = []struct {
+		Blueprint      string
+		Body           string
+		ExpectedStatus int
+		ExpectedJSON   string
+	}{
+		{`{"name":"test-size","description":"Test","packages":[],"version":"0.0.0"}`, `{"blueprint_name":"test-size","compose_type":"qcow2","branch":"master","size":1024}`, http.StatusBadRequest, `{"status":false,"errors":[{"id":"InvalidSize","msg":"Image size of 1024 bytes is too small, the packages need an estimated 9437184 bytes"}]}`},
+		{`{"name":"test-size","description":"Test","packages":[],"version":"0.0.0","customizations":{"size":1024}}`, `{"blueprint_name":"test-size","compose_type":"qcow2","branch":"master"}`, http.StatusBadRequest, `{"status":false,"errors":[{"id":"InvalidSize","msg":"Image size of 1024 bytes is too small, the packages need an estimated 9437184 bytes"}]}`},
+		{`{"name":"test-size","description":"Test","packages":[],"version":"0.0.0","customizations":{"size":1024}}`, `{"blueprint_name":"test-size","compose_type":"qcow2","branch":"master","size":10485760}`, http.StatusOK, `{"status":true}`},
+	}
+
+	for _, c := range cases {
+		api, s := createWeldrAPI(rpmmd_mock.NoComposesFixture)
+		test.SendHTTP(api, false, "POST", "/api/v0/blueprints/new", c.Blueprint)
+		test.TestRoute(t, api, false, "POST", "/api/v0/compose", c.Body, c.ExpectedStatus, c.ExpectedJSON, "build_id")
+		if c.ExpectedStatus != http.StatusOK {
+			require.Empty(t, s.GetAllComposes())
+		}
+	}
+}
+
+func TestComposeOSTreeParent(t *testing.T) {
+	const parent = "02604b2da6e954bd34b8b82a835e5a77d2b60ffa4d1b2f6a7a4f7b7c4c4c4c4c"
+	repo := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/refs/heads/test/edge" {
+			http.NotFound(w, r)
+			return
+		}
+		_, err := w.Write([]byte(parent + "\n"))
+		require.NoError(t, err)
+	}))
+	defer repo.Close()
+
+	var cases = []struct {
+		OSTree         string
+		ExpectedStatus int
+		ExpectedJSON   string
+	}{
+		{`{"ref":"test/edge","url":"` + repo.URL + `"}`, http.StatusOK, `{"status":true}`},
+		{`{"ref":"test/next","parent":"test/edge","url":"` + repo.URL + `"}`, http.StatusOK, `{"status":true}`},
+		{`{"ref":"test/next","parent":"` + parent + `","url":"` + repo.URL + `"}`, http.StatusOK, `{"status":true}`},
+		{`{"url":"` + repo.URL + `"}`, http.StatusOK, `{"status":true}`},
+		{`{"ref":"test/missing","url":"` + repo.URL + `"}`, http.StatusBadRequest, `{"status":false,"errors":[{"id":"OSTreeOptionsError"}]}`},
+		{`{"ref":"../../test/edge","url":"` + repo.URL + `"}`, http.StatusBadRequest, `{"status":false,"errors":[{"id":"OSTreeOptionsError"}]}`},
+		{`{"ref":"test/next","parent":"test/edge?x","url":"` + repo.URL + `"}`, http.StatusBadRequest, `{"status":false,"errors":[{"id":"OSTreeOptionsError"}]}`},
+	}
+
+	for _, c := range cases {
+		api, s := createWeldrAPI(rpmmd_mock.NoComposesFixture)
+		body := `{"blueprint_name":"test","compose_type":"qcow2","branch":"master","ostree":` + c.OSTree + `}`
+		if c.ExpectedStatus == http.StatusOK {
+			test.TestRoute(t, api, false, "POST", "/api/v0/compose", body, c.ExpectedStatus, c.ExpectedJSON, "build_id")
+		} else {
+			test.TestRoute(t, api, false, "POST", "/api/v0/compose", body, c.ExpectedStatus, c.ExpectedJSON, "msg")
+			require.Empty(t, s.GetAllComposes())
+		}
+	}
+}
+
+func TestComposeOSTreeSign(t *testing.T) {
+	var cases = []struct {
+		OSTree         string
+		ExpectedStatus int
+		ExpectedJSON   string
+	}{
+		{`{"ref":"test/edge","sign":{"type":"gpg","key":"edge"}}`, http.StatusOK, `{"status":true}`},
+		{`{"ref":"test/edge","parent":"abc","static_deltas":true}`, http.StatusOK, `{"status":true}`},
+		{`{"ref":"test/edge","sign":{"type":"rsa","key":"edge"}}`, http.StatusBadRequest, `{"status":false,"errors":[{"id":"OSTreeOptionsError","msg":"unsupported signature type \"rsa\", use gpg or ed25519"}]}`},
+		{`{"ref":"test/edge","sign":{"type":"gpg","key":"/etc/edge"}}`, http.StatusBadRequest, `{"status":false,"errors":[{"id":"OSTreeOptionsError","msg":"invalid signing key name \"/etc/edge\""}]}`},
+		{`{"ref":"test/edge","static_deltas":true}`, http.StatusBadRequest, `{"status":false,"errors":[{"id":"OSTreeOptionsError","msg":"static deltas require a parent commit"}]}`},
+	}
+
+	for _, c := range cases {
+		api, s := createWeldrAPI(rpmmd_mock.NoComposesFixture)
+		body := `{"blueprint_name":"test","compose_type":"qcow2","branch":"master","ostree":` + c.OSTree + `}`
+		test.TestRoute(t, api, false, "POST", "/api/v0/compose", body, c.ExpectedStatus, c.ExpectedJSON, "build_id")
+		if c.ExpectedStatus != http.StatusOK {
+			require.Empty(t, s.GetAllComposes())
+		}
+	}
+}
+
+func TestComposeOVF(t *testing.T) {
+	var cases = []struct {
+		OVF            string
+		ExpectedStatus int
+		ExpectedJSON   string
+	}{
+		{`{"cpus":4,"memory":8192,"network":"VM Network","nic_type":"e1000e"}`, http.StatusOK, `{"status":true}`},
+		{`{"nic_type":"virtio"}`, http.StatusBadRequest, `{"status":false,"errors":[{"id":"OVFOptionsError","msg":"unsupported NIC type \"virtio\", use one of e1000, e1000e, vmxnet3"}]}`},
+		{`{"memory":64}`, http.StatusBadRequest, `{"status":false,"errors":[{"id":"OVFOptionsError","msg":"memory of 64 MiB is too small, use at least 256 MiB"}]}`},
+	}
+
+	for _, c := range cases {
+		api, s := createWeldrAPI(rpmmd_mock.NoComposesFixture)
+		body := `{"blueprint_name":"test","compose_type":"qcow2","branch":"master","ovf":` + c.OVF + `}`
+		test.TestRoute(t, api, false, "POST", "/api/v0/compose", body, c.ExpectedStatus, c.ExpectedJSON, "build_id")
+		if c.ExpectedStatus != http.StatusOK {
+			require.Empty(t, s.GetAllComposes())
+		}
+	}
+}
+
 func TestComposeDelete(t *testing.T) {
 	if len(os.Getenv("OSBUILD_COMPOSER_TEST_EXTERNAL")) > 0 {
 		t.Skip("This test is for internal testing only")
@@ -943,7 +1174,7 @@ func TestProjectsDepsolve(t *testing.T) {
 		ExpectedJSON   string
 	}{
 		{rpmmd_mock.NonExistingPackage, "/api/v0/projects/depsolve/fash", http.StatusBadRequest, `{"status":false,"errors":[{"id":"PROJECTS_ERROR","msg":"BadRequest: DNF error occured: MarkingErrors: Error occurred when marking packages for installation: Problems in request:\nmissing packages: fash"}]}`},
-		{rpmmd_mock.BaseFixture, "/api/v0/projects/depsolve/fish", http.StatusOK, `{"projects":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64"},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64"},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64"}]}`},
+		{rpmmd_mock.BaseFixture, "/api/v0/projects/depsolve/fish", http.StatusOK, `{"projects":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64","install_size":3145728},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64","install_size":1048576},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64","install_size":2097152}]}`},
 		{rpmmd_mock.BadDepsolve, "/api/v0/projects/depsolve/go2rpm", http.StatusBadRequest, `{"status":false,"errors":[{"id":"PROJECTS_ERROR","msg":"BadRequest: DNF error occured: DepsolveError: There was a problem depsolving ['go2rpm']: \n Problem: conflicting requests\n  - nothing provides askalono-cli needed by go2rpm-1-4.fc31.noarch"}]}`},
 	}
 
@@ -985,8 +1216,8 @@ func TestModulesInfo(t *testing.T) {
 		{rpmmd_mock.BaseFixture, "/api/v0/modules/info/", http.StatusBadRequest, `{"status":false,"errors":[{"id":"UnknownModule","msg":"No packages specified."}]}`},
 		{rpmmd_mock.BaseFixture, "/api/v0/modules/info/nonexistingpkg", http.StatusBadRequest, `{"status":false,"errors":[{"id":"UnknownModule","msg":"No packages have been found."}]}`},
 		{rpmmd_mock.BadDepsolve, "/api/v0/modules/info/package1", http.StatusBadRequest, `{"status":false,"errors":[{"id":"ModulesError","msg":"Cannot depsolve package package1: DNF error occured: DepsolveError: There was a problem depsolving ['go2rpm']: \n Problem: conflicting requests\n  - nothing provides askalono-cli needed by go2rpm-1-4.fc31.noarch"}]}`},
-		{rpmmd_mock.BaseFixture, "/api/v0/modules/info/package2*,package16", http.StatusOK, `{"modules":[{"name":"package16","summary":"pkg16 sum","description":"pkg16 desc","homepage":"https://pkg16.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2007-05-02T15:04:05","epoch":0,"release":"16.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"16.0","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2007-05-03T15:04:05","epoch":0,"release":"16.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"16.1","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64"},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64"},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64"}]},{"name":"package2","summary":"pkg2 sum","description":"pkg2 desc","homepage":"https://pkg2.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2006-03-02T15:04:05","epoch":0,"release":"2.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"2.0","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2006-03-03T15:04:05","epoch":0,"release":"2.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"2.1","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64"},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64"},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64"}]},{"name":"package20","summary":"pkg20 sum","description":"pkg20 desc","homepage":"https://pkg20.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2007-09-03T15:04:05","epoch":0,"release":"20.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"20.1","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2007-09-02T15:04:05","epoch":0,"release":"20.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"20.0","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64"},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64"},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64"}]},{"name":"package21","summary":"pkg21 sum","description":"pkg21 desc","homepage":"https://pkg21.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2007-10-02T15:04:05","epoch":0,"release":"21.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"21.0","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2007-10-03T15:04:05","epoch":0,"release":"21.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"21.1","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64"},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64"},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64"}]}]}`},
-		{rpmmd_mock.BaseFixture, "/api/v0/modules/info/*", http.StatusOK, `{"modules":[{"name":"package0","summary":"pkg0 sum","description":"pkg0 desc","homepage":"https://pkg0.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2006-01-03T15:04:05","epoch":0,"release":"0.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"0.1","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2006-01-02T15:04:05","epoch":0,"release":"0.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"0.0","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64"},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64"},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64"}]},{"name":"package1","summary":"pkg1 sum","description":"pkg1 desc","homepage":"https://pkg1.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2006-02-02T15:04:05","epoch":0,"release":"1.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"1.0","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2006-02-03T15:04:05","epoch":0,"release":"1.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"1.1","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64"},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64"},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64"}]},{"name":"package10","summary":"pkg10 sum","description":"pkg10 desc","homepage":"https://pkg10.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2006-11-02T15:04:05","epoch":0,"release":"10.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"10.0","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2006-11-03T15:04:05","epoch":0,"release":"10.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"10.1","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64"},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64"},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64"}]},{"name":"package11","summary":"pkg11 sum","description":"pkg11 desc","homepage":"https://pkg11.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2006-12-03T15:04:05","epoch":0,"release":"11.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"11.1","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2006-12-02T15:04:05","epoch":0,"release":"11.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"11.0","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64"},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64"},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64"}]},{"name":"package12","summary":"pkg12 sum","description":"pkg12 desc","homepage":"https://pkg12.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2007-01-02T15:04:05","epoch":0,"release":"12.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"12.0","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2007-01-03T15:04:05","epoch":0,"release":"12.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"12.1","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64"},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64"},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64"}]},{"name":"package13","summary":"pkg13 sum","description":"pkg13 desc","homepage":"https://pkg13.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2007-02-02T15:04:05","epoch":0,"release":"13.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"13.0","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2007-02-03T15:04:05","epoch":0,"release":"13.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"13.1","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64"},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64"},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64"}]},{"name":"package14","summary":"pkg14 sum","description":"pkg14 desc","homepage":"https://pkg14.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2007-03-03T15:04:05","epoch":0,"release":"14.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"14.1","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2007-03-02T15:04:05","epoch":0,"release":"14.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"14.0","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64"},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64"},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64"}]},{"name":"package15","summary":"pkg15 sum","description":"pkg15 desc","homepage":"https://pkg15.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2007-04-03T15:04:05","epoch":0,"release":"15.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"15.1","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2007-04-02T15:04:05","epoch":0,"release":"15.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"15.0","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64"},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64"},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64"}]},{"name":"package16","summary":"pkg16 sum","description":"pkg16 desc","homepage":"https://pkg16.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2007-05-02T15:04:05","epoch":0,"release":"16.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"16.0","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2007-05-03T15:04:05","epoch":0,"release":"16.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"16.1","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64"},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64"},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64"}]},{"name":"package17","summary":"pkg17 sum","description":"pkg17 desc","homepage":"https://pkg17.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2007-06-03T15:04:05","epoch":0,"release":"17.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"17.1","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2007-06-02T15:04:05","epoch":0,"release":"17.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"17.0","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64"},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64"},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64"}]},{"name":"package18","summary":"pkg18 sum","description":"pkg18 desc","homepage":"https://pkg18.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2007-07-02T15:04:05","epoch":0,"release":"18.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"18.0","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2007-07-03T15:04:05","epoch":0,"release":"18.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"18.1","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64"},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64"},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64"}]},{"name":"package19","summary":"pkg19 sum","description":"pkg19 desc","homepage":"https://pkg19.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2007-08-03T15:04:05","epoch":0,"release":"19.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"19.1","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2007-08-02T15:04:05","epoch":0,"release":"19.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"19.0","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64"},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64"},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64"}]},{"name":"package2","summary":"pkg2 sum","description":"pkg2 desc","homepage":"https://pkg2.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2006-03-02T15:04:05","epoch":0,"release":"2.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"2.0","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2006-03-03T15:04:05","epoch":0,"release":"2.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"2.1","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64"},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64"},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64"}]},{"name":"package20","summary":"pkg20 sum","description":"pkg20 desc","homepage":"https://pkg20.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2007-09-03T15:04:05","epoch":0,"release":"20.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"20.1","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2007-09-02T15:04:05","epoch":0,"release":"20.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"20.0","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64"},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64"},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64"}]},{"name":"package21","summary":"pkg21 sum","description":"pkg21 desc","homepage":"https://pkg21.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2007-10-02T15:04:05","epoch":0,"release":"21.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"21.0","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2007-10-03T15:04:05","epoch":0,"release":"21.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"21.1","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64"},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64"},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64"}]},{"name":"package3","summary":"pkg3 sum","description":"pkg3 desc","homepage":"https://pkg3.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2006-04-03T15:04:05","epoch":0,"release":"3.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"3.1","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2006-04-02T15:04:05","epoch":0,"release":"3.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"3.0","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64"},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64"},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64"}]},{"name":"package4","summary":"pkg4 sum","description":"pkg4 desc","homepage":"https://pkg4.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2006-05-03T15:04:05","epoch":0,"release":"4.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"4.1","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2006-05-02T15:04:05","epoch":0,"release":"4.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"4.0","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64"},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64"},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64"}]},{"name":"package5","summary":"pkg5 sum","description":"pkg5 desc","homepage":"https://pkg5.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2006-06-03T15:04:05","epoch":0,"release":"5.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"5.1","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2006-06-02T15:04:05","epoch":0,"release":"5.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"5.0","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64"},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64"},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64"}]},{"name":"package6","summary":"pkg6 sum","description":"pkg6 desc","homepage":"https://pkg6.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2006-07-02T15:04:05","epoch":0,"release":"6.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"6.0","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2006-07-03T15:04:05","epoch":0,"release":"6.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"6.1","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64"},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64"},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64"}]},{"name":"package7","summary":"pkg7 sum","description":"pkg7 desc","homepage":"https://pkg7.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2006-08-02T15:04:05","epoch":0,"release":"7.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"7.0","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2006-08-03T15:04:05","epoch":0,"release":"7.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"7.1","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64"},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64"},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64"}]},{"name":"package8","summary":"pkg8 sum","description":"pkg8 desc","homepage":"https://pkg8.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2006-09-03T15:04:05","epoch":0,"release":"8.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"8.1","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2006-09-02T15:04:05","epoch":0,"release":"8.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"8.0","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64"},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64"},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64"}]},{"name":"package9","summary":"pkg9 sum","description":"pkg9 desc","homepage":"https://pkg9.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2006-10-02T15:04:05","epoch":0,"release":"9.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"9.0","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2006-10-03T15:04:05","epoch":0,"release":"9.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"9.1","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64"},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64"},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64"}]}]}`},
+		{rpmmd_mock.BaseFixture, "/api/v0/modules/info/package2*,package16", http.StatusOK, `{"modules":[{"name":"package16","summary":"pkg16 sum","description":"pkg16 desc","homepage":"https://pkg16.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2007-05-02T15:04:05","epoch":0,"release":"16.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"16.0","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2007-05-03T15:04:05","epoch":0,"release":"16.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"16.1","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64","install_size":3145728},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64","install_size":1048576},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64","install_size":2097152}]},{"name":"package2","summary":"pkg2 sum","description":"pkg2 desc","homepage":"https://pkg2.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2006-03-02T15:04:05","epoch":0,"release":"2.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"2.0","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2006-03-03T15:04:05","epoch":0,"release":"2.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"2.1","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64","install_size":3145728},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64","install_size":1048576},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64","install_size":2097152}]},{"name":"package20","summary":"pkg20 sum","description":"pkg20 desc","homepage":"https://pkg20.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2007-09-03T15:04:05","epoch":0,"release":"20.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"20.1","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2007-09-02T15:04:05","epoch":0,"release":"20.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"20.0","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64","install_size":3145728},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64","install_size":1048576},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64","install_size":2097152}]},{"name":"package21","summary":"pkg21 sum","description":"pkg21 desc","homepage":"https://pkg21.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2007-10-02T15:04:05","epoch":0,"release":"21.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"21.0","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2007-10-03T15:04:05","epoch":0,"release":"21.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"21.1","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64","install_size":3145728},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64","install_size":1048576},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64","install_size":2097152}]}]}`},
+		{rpmmd_mock.BaseFixture, "/api/v0/modules/info/*", http.StatusOK, `{"modules":[{"name":"package0","summary":"pkg0 sum","description":"pkg0 desc","homepage":"https://pkg0.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2006-01-03T15:04:05","epoch":0,"release":"0.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"0.1","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2006-01-02T15:04:05","epoch":0,"release":"0.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"0.0","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64","install_size":3145728},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64","install_size":1048576},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64","install_size":2097152}]},{"name":"package1","summary":"pkg1 sum","description":"pkg1 desc","homepage":"https://pkg1.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2006-02-02T15:04:05","epoch":0,"release":"1.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"1.0","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2006-02-03T15:04:05","epoch":0,"release":"1.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"1.1","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64","install_size":3145728},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64","install_size":1048576},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64","install_size":2097152}]},{"name":"package10","summary":"pkg10 sum","description":"pkg10 desc","homepage":"https://pkg10.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2006-11-02T15:04:05","epoch":0,"release":"10.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"10.0","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2006-11-03T15:04:05","epoch":0,"release":"10.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"10.1","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64","install_size":3145728},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64","install_size":1048576},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64","install_size":2097152}]},{"name":"package11","summary":"pkg11 sum","description":"pkg11 desc","homepage":"https://pkg11.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2006-12-03T15:04:05","epoch":0,"release":"11.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"11.1","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2006-12-02T15:04:05","epoch":0,"release":"11.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"11.0","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64","install_size":3145728},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64","install_size":1048576},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64","install_size":2097152}]},{"name":"package12","summary":"pkg12 sum","description":"pkg12 desc","homepage":"https://pkg12.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2007-01-02T15:04:05","epoch":0,"release":"12.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"12.0","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2007-01-03T15:04:05","epoch":0,"release":"12.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"12.1","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64","install_size":3145728},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64","install_size":1048576},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64","install_size":2097152}]},{"name":"package13","summary":"pkg13 sum","description":"pkg13 desc","homepage":"https://pkg13.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2007-02-02T15:04:05","epoch":0,"release":"13.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"13.0","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2007-02-03T15:04:05","epoch":0,"release":"13.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"13.1","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64","install_size":3145728},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64","install_size":1048576},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64","install_size":2097152}]},{"name":"package14","summary":"pkg14 sum","description":"pkg14 desc","homepage":"https://pkg14.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2007-03-03T15:04:05","epoch":0,"release":"14.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"14.1","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2007-03-02T15:04:05","epoch":0,"release":"14.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"14.0","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64","install_size":3145728},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64","install_size":1048576},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64","install_size":2097152}]},{"name":"package15","summary":"pkg15 sum","description":"pkg15 desc","homepage":"https://pkg15.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2007-04-03T15:04:05","epoch":0,"release":"15.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"15.1","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2007-04-02T15:04:05","epoch":0,"release":"15.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"15.0","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64","install_size":3145728},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64","install_size":1048576},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64","install_size":2097152}]},{"name":"package16","summary":"pkg16 sum","description":"pkg16 desc","homepage":"https://pkg16.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2007-05-02T15:04:05","epoch":0,"release":"16.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"16.0","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2007-05-03T15:04:05","epoch":0,"release":"16.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"16.1","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64","install_size":3145728},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64","install_size":1048576},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64","install_size":2097152}]},{"name":"package17","summary":"pkg17 sum","description":"pkg17 desc","homepage":"https://pkg17.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2007-06-03T15:04:05","epoch":0,"release":"17.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"17.1","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2007-06-02T15:04:05","epoch":0,"release":"17.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"17.0","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64","install_size":3145728},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64","install_size":1048576},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64","install_size":2097152}]},{"name":"package18","summary":"pkg18 sum","description":"pkg18 desc","homepage":"https://pkg18.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2007-07-02T15:04:05","epoch":0,"release":"18.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"18.0","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2007-07-03T15:04:05","epoch":0,"release":"18.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"18.1","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64","install_size":3145728},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64","install_size":1048576},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64","install_size":2097152}]},{"name":"package19","summary":"pkg19 sum","description":"pkg19 desc","homepage":"https://pkg19.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2007-08-03T15:04:05","epoch":0,"release":"19.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"19.1","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2007-08-02T15:04:05","epoch":0,"release":"19.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"19.0","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64","install_size":3145728},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64","install_size":1048576},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64","install_size":2097152}]},{"name":"package2","summary":"pkg2 sum","description":"pkg2 desc","homepage":"https://pkg2.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2006-03-02T15:04:05","epoch":0,"release":"2.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"2.0","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2006-03-03T15:04:05","epoch":0,"release":"2.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"2.1","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64","install_size":3145728},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64","install_size":1048576},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64","install_size":2097152}]},{"name":"package20","summary":"pkg20 sum","description":"pkg20 desc","homepage":"https://pkg20.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2007-09-03T15:04:05","epoch":0,"release":"20.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"20.1","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2007-09-02T15:04:05","epoch":0,"release":"20.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"20.0","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64","install_size":3145728},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64","install_size":1048576},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64","install_size":2097152}]},{"name":"package21","summary":"pkg21 sum","description":"pkg21 desc","homepage":"https://pkg21.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2007-10-02T15:04:05","epoch":0,"release":"21.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"21.0","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2007-10-03T15:04:05","epoch":0,"release":"21.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"21.1","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64","install_size":3145728},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64","install_size":1048576},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64","install_size":2097152}]},{"name":"package3","summary":"pkg3 sum","description":"pkg3 desc","homepage":"https://pkg3.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2006-04-03T15:04:05","epoch":0,"release":"3.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"3.1","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2006-04-02T15:04:05","epoch":0,"release":"3.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"3.0","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64","install_size":3145728},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64","install_size":1048576},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64","install_size":2097152}]},{"name":"package4","summary":"pkg4 sum","description":"pkg4 desc","homepage":"https://pkg4.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2006-05-03T15:04:05","epoch":0,"release":"4.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"4.1","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2006-05-02T15:04:05","epoch":0,"release":"4.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"4.0","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64","install_size":3145728},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64","install_size":1048576},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64","install_size":2097152}]},{"name":"package5","summary":"pkg5 sum","description":"pkg5 desc","homepage":"https://pkg5.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2006-06-03T15:04:05","epoch":0,"release":"5.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"5.1","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2006-06-02T15:04:05","epoch":0,"release":"5.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"5.0","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64","install_size":3145728},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64","install_size":1048576},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64","install_size":2097152}]},{"name":"package6","summary":"pkg6 sum","description":"pkg6 desc","homepage":"https://pkg6.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2006-07-02T15:04:05","epoch":0,"release":"6.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"6.0","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2006-07-03T15:04:05","epoch":0,"release":"6.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"6.1","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64","install_size":3145728},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64","install_size":1048576},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64","install_size":2097152}]},{"name":"package7","summary":"pkg7 sum","description":"pkg7 desc","homepage":"https://pkg7.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2006-08-02T15:04:05","epoch":0,"release":"7.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"7.0","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2006-08-03T15:04:05","epoch":0,"release":"7.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"7.1","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64","install_size":3145728},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64","install_size":1048576},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64","install_size":2097152}]},{"name":"package8","summary":"pkg8 sum","description":"pkg8 desc","homepage":"https://pkg8.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2006-09-03T15:04:05","epoch":0,"release":"8.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"8.1","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2006-09-02T15:04:05","epoch":0,"release":"8.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"8.0","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64","install_size":3145728},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64","install_size":1048576},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64","install_size":2097152}]},{"name":"package9","summary":"pkg9 sum","description":"pkg9 desc","homepage":"https://pkg9.example.com","upstream_vcs":"UPSTREAM_VCS","builds":[{"arch":"x86_64","build_time":"2006-10-02T15:04:05","epoch":0,"release":"9.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"9.0","source_ref":"SOURCE_REF","metadata":{}}},{"arch":"x86_64","build_time":"2006-10-03T15:04:05","epoch":0,"release":"9.fc30","changelog":"CHANGELOG_NEEDED","build_config_ref":"BUILD_CONFIG_REF","build_env_ref":"BUILD_ENV_REF","metadata":{},"source":{"license":"MIT","version":"9.1","source_ref":"SOURCE_REF","metadata":{}}}],"dependencies":[{"name":"dep-package3","epoch":7,"version":"3.0.3","release":"1.fc30","arch":"x86_64","install_size":3145728},{"name":"dep-package1","epoch":0,"version":"1.33","release":"2.fc30","arch":"x86_64","install_size":1048576},{"name":"dep-package2","epoch":0,"version":"2.9","release":"1.fc30","arch":"x86_64","install_size":2097152}]}]}`},
 		{rpmmd_mock.BadFetch, "/api/v0/modules/info/package2*,package16", http.StatusBadRequest, `{"status":false,"errors":[{"id":"ModulesError","msg":"msg: DNF error occured: FetchError: There was a problem when fetching packages."}]}`},
 	}
 
@@ -1036,3 +1267,219 @@ func TestModulesList(t *testing.T) {
 		test.TestRoute(t, api, true, "GET", c.Path, ``, c.ExpectedStatus, c.ExpectedJSON)
 	}
 }
+
+func TestOSTreeRepoImport(t *testing.T) {
+	const commit = "02604b2da6e954bd34b8b82a835e5a77d2b60ffa4d1b2f6a7a4f7b7c4c4c4c4c"
+
+	artifactsDir, err := ioutil.TempDir("", "weldr-test-artifacts-")
+	require.NoError(t, err)
+	defer os.RemoveAll(artifactsDir)
+	repoDir, err := ioutil.TempDir("", "weldr-test-ostree-")
+	require.NoError(t, err)
+	defer os.RemoveAll(repoDir)
+
+	d := rhel8.New()
+	arch, err := d.GetArch("x86_64")
+	require.NoError(t, err)
+	imageType, err := arch.GetImageType("rhel-edge-commit")
+	require.NoError(t, err)
+
+	fixture := rpmmd_mock.BaseFixture()
+	workers := worker.NewServer(nil, testjobqueue.New(), artifactsDir)
+	api := New(rpmmd_mock.NewRPMMDMock(fixture), arch, d, nil, nil, fixture.Store, workers, "")
+
+	repo, err := ostree.NewRepo(repoDir)
+	require.NoError(t, err)
+	api.SetOSTreeRepo(repo)
+
+	composeId := uuid.New()
+	targets := []*target.Target{
+		target.NewLocalTarget(&target.LocalTargetOptions{ComposeId: composeId, Filename: imageType.Filename()}),
+	}
+	jobId, err := workers.Enqueue(arch.Name(), distro.Manifest("{}"), targets)
+	require.NoError(t, err)
+	err = fixture.Store.PushCompose(composeId, distro.Manifest("{}"), imageType, &blueprint.Blueprint{Name: "test"}, 0, targets, jobId)
+	require.NoError(t, err)
+
+	token, _, _, err := workers.RequestOSBuildJob(context.Background(), arch.Name())
+	require.NoError(t, err)
+
+	f, err := os.Create(path.Join(artifactsDir, "tmp", token.String(), "commit.tar"))
+	require.NoError(t, err)
+	tw := tar.NewWriter(f)
+	require.NoError(t, tw.WriteHeader(&tar.Header{
+		Name:     "repo/refs/heads/rhel/8/x86_64/edge",
+		Mode:     0644,
+		Size:     int64(len(commit)),
+		Typeflag: tar.TypeReg,
+	}))
+	_, err = tw.Write([]byte(commit))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, f.Close())
+
+	err = workers.FinishJob(token, &worker.OSBuildJobResult{OSBuildOutput: &osbuild.Result{Success: true}})
+	require.NoError(t, err)
+
+	// the commit is imported in the background
+	require.Eventually(t, func() bool {
+		_, exists := repo.History("rhel/8/x86_64/edge")
+		return exists
+	}, 5*time.Second, 10*time.Millisecond)
+	history, _ := repo.History("rhel/8/x86_64/edge")
+	require.Len(t, history, 1)
+	require.Equal(t, commit, history[0].Checksum)
+	require.Equal(t, composeId, history[0].ComposeID)
+}
+
+func TestOSTreeRepoImportQueue(t *testing.T) {
+	api, s := createWeldrAPI(rpmmd_mock.NoComposesFixture)
+	arch, err := test_distro.New().GetArch("x86_64")
+	require.NoError(t, err)
+	imageType, err := arch.GetImageType("qcow2")
+	require.NoError(t, err)
+
+	jobId := uuid.New()
+	composeId := uuid.New()
+	targets := []*target.Target{
+		target.NewLocalTarget(&target.LocalTargetOptions{ComposeId: uuid.New()}),
+		target.NewLocalTarget(&target.LocalTargetOptions{ComposeId: composeId}),
+	}
+	err = s.PushCompose(composeId, distro.Manifest("{}"), imageType, &blueprint.Blueprint{Name: "test"}, 0, targets, jobId)
+	require.NoError(t, err)
+
+	// the imports are not consumed, so that the queue fills up
+	api.ostreeImports = make(chan ostreeImport, 1)
+	result := &worker.OSBuildJobResult{OSBuildOutput: &osbuild.Result{Success: true}}
+	api.queueOSTreeCommit(jobId, targets, result)
+	require.Len(t, api.ostreeImports, 1)
+	require.Equal(t, composeId, (<-api.ostreeImports).composeId)
+
+	api.queueOSTreeCommit(jobId, targets, result)
+	api.queueOSTreeCommit(jobId, targets, result)
+	require.Len(t, api.ostreeImports, 1)
+}
+
+func TestComposeImageMultipleFiles(t *testing.T) {
+	artifactsDir, err := ioutil.TempDir("", "weldr-test-artifacts-")
+	require.NoError(t, err)
+	defer os.RemoveAll(artifactsDir)
+
+	d := rhel8.New()
+	arch, err := d.GetArch("x86_64")
+	require.NoError(t, err)
+	imageType, err := arch.GetImageType("pxe")
+	require.NoError(t, err)
+
+	fixture := rpmmd_mock.BaseFixture()
+	workers := worker.NewServer(nil, testjobqueue.New(), artifactsDir)
+	api := New(rpmmd_mock.NewRPMMDMock(fixture), arch, d, nil, nil, fixture.Store, workers, "")
+
+	jobId, err := workers.Enqueue(arch.Name(), distro.Manifest("{}"), nil)
+	require.NoError(t, err)
+	composeId := uuid.New()
+	err = fixture.Store.PushCompose(composeId, distro.Manifest("{}"), imageType, &blueprint.Blueprint{Name: "test"}, 0, nil, jobId)
+	require.NoError(t, err)
+
+	token, _, _, err := workers.RequestOSBuildJob(context.Background(), arch.Name())
+	require.NoError(t, err)
+
+	// contents of different lengths to check the padding of the tarball
+	files := map[string]string{}
+	for i, name := range imageType.Filenames() {
+		files[name] = strings.Repeat(name, 100*i+1)
+		err = ioutil.WriteFile(path.Join(artifactsDir, "tmp", token.String(), name), []byte(files[name]), 0644)
+		require.NoError(t, err)
+	}
+
+	err = workers.FinishJob(token, &worker.OSBuildJobResult{OSBuildOutput: &osbuild.Result{Success: true}})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("GET", "/api/v0/compose/image/"+composeId.String(), nil)
+	recorder := httptest.NewRecorder()
+	api.ServeHTTP(recorder, req)
+
+	resp := recorder.Result()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "attachment; filename="+composeId.String()+"-pxe.tar", resp.Header.Get("Content-Disposition"))
+	require.Equal(t, "application/x-tar", resp.Header.Get("Content-Type"))
+	require.Equal(t, strconv.Itoa(recorder.Body.Len()), resp.Header.Get("Content-Length"))
+
+	tr := tar.NewReader(recorder.Body)
+	var names []string
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		names = append(names, hdr.Name)
+		data, err := ioutil.ReadAll(tr)
+		require.NoError(t, err)
+		require.Equal(t, files[hdr.Name], string(data))
+	}
+	require.Equal(t, imageType.Filenames(), names)
+}
+
+func TestComposeInfoContainerDigest(t *testing.T) {
+	artifactsDir, err := ioutil.TempDir("", "weldr-test-artifacts-")
+	require.NoError(t, err)
+	defer os.RemoveAll(artifactsDir)
+
+	d := rhel8.New()
+	arch, err := d.GetArch("x86_64")
+	require.NoError(t, err)
+	imageType, err := arch.GetImageType("container")
+	require.NoError(t, err)
+
+	fixture := rpmmd_mock.BaseFixture()
+	workers := worker.NewServer(nil, testjobqueue.New(), artifactsDir)
+	api := New(rpmmd_mock.NewRPMMDMock(fixture), arch, d, nil, nil, fixture.Store, workers, "")
+
+	targets := []*target.Target{
+		target.NewContainerRegistryTarget(&target.ContainerRegistryTargetOptions{
+			Filename:   imageType.Filename(),
+			Registry:   "registry.example.com",
+			Repository: "library/test",
+			Tag:        "latest",
+		}),
+	}
+	jobId, err := workers.Enqueue(arch.Name(), distro.Manifest("{}"), targets)
+	require.NoError(t, err)
+	composeId := uuid.New()
+	err = fixture.Store.PushCompose(composeId, distro.Manifest("{}"), imageType, &blueprint.Blueprint{Name: "test"}, 0, targets, jobId)
+	require.NoError(t, err)
+
+	token, _, _, err := workers.RequestOSBuildJob(context.Background(), arch.Name())
+	require.NoError(t, err)
+
+	digest := "sha256:0a6f1ef2c8e1c8d8d3f1b9c2e4d5a6b7c8d9e0f1a2b3c4d5e6f7a8b9c0d1e2f3"
+	err = workers.FinishJob(token, &worker.OSBuildJobResult{
+		OSBuildOutput: &osbuild.Result{Success: true},
+		TargetResults: []*target.TargetResult{
+			target.NewContainerRegistryTargetResult(&target.ContainerRegistryTargetResultOptions{
+				Registry:   "registry.example.com",
+				Repository: "library/test",
+				Tag:        "latest",
+				Digest:     digest,
+			}),
+		},
+	})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("GET", "/api/v1/compose/info/"+composeId.String(), nil)
+	recorder := httptest.NewRecorder()
+	api.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var info struct {
+		Uploads []struct {
+			ProviderName string                 `json:"provider_name"`
+			Settings     map[string]interface{} `json:"settings"`
+		} `json:"uploads"`
+	}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &info))
+	require.Len(t, info.Uploads, 1)
+	require.Equal(t, "container-registry", info.Uploads[0].ProviderName)
+	require.Equal(t, digest, info.Uploads[0].Settings["digest"])
+}
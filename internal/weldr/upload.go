@@ -43,6 +43,20 @@ type azureUploadSettings struct {
 
 func (azureUploadSettings) isUploadSettings() {}
 
+type containerRegistryUploadSettings struct {
+	Registry   string `json:"registry"`
+	Repository string `json:"repository"`
+	Tag        string `json:"tag"`
+	Username   string `json:"username,omitempty"`
+	Password   string `json:"password,omitempty"`
+	AuthFile   string `json:"auth_file,omitempty"`
+
+	// Digest is only returned, once the image was pushed
+	Digest string `json:"digest,omitempty"`
+}
+
+func (containerRegistryUploadSettings) isUploadSettings() {}
+
 type uploadRequest struct {
 	Provider  string         `json:"provider"`
 	ImageName string         `json:"image_name"`
@@ -68,6 +82,8 @@ func (u *uploadRequest) UnmarshalJSON(data []byte) error {
 		settings = new(azureUploadSettings)
 	case "aws":
 		settings = new(awsUploadSettings)
+	case "container-registry":
+		settings = new(containerRegistryUploadSettings)
 	default:
 		return errors.New("unexpected provider name")
 	}
@@ -91,7 +107,9 @@ func (u *uploadRequest) UnmarshalJSON(data []byte) error {
 //
 // This also ignores any sensitive data passed into targets. Access keys may
 // be passed as input to composer, but should not be possible to be queried.
-func targetsToUploadResponses(targets []*target.Target, state common.ComposeState) []uploadResponse {
+// Results the worker reported for targets, such as the digest of a pushed
+// container image, are included.
+func targetsToUploadResponses(targets []*target.Target, results []*target.TargetResult, state common.ComposeState) []uploadResponse {
 	var uploads []uploadResponse
 	for _, t := range targets {
 		upload := uploadResponse{
@@ -128,6 +146,23 @@ func targetsToUploadResponses(targets []*target.Target, state common.ComposeStat
 				// StorageAccount and StorageAccessKey are intentionally not included.
 			}
 			uploads = append(uploads, upload)
+		case *target.ContainerRegistryTargetOptions:
+			upload.ProviderName = "container-registry"
+			settings := &containerRegistryUploadSettings{
+				Registry:   options.Registry,
+				Repository: options.Repository,
+				Tag:        options.Tag,
+				AuthFile:   options.AuthFile,
+				// Username and Password are intentionally not included.
+			}
+			for _, result := range results {
+				r, ok := result.Options.(*target.ContainerRegistryTargetResultOptions)
+				if ok && r.Registry == options.Registry && r.Repository == options.Repository && r.Tag == options.Tag {
+					settings.Digest = r.Digest
+				}
+			}
+			upload.Settings = settings
+			uploads = append(uploads, upload)
 		}
 	}
 
@@ -161,6 +196,17 @@ func uploadRequestToTarget(u uploadRequest, imageType distro.ImageType) *target.
 			StorageAccessKey: options.StorageAccessKey,
 			Container:        options.Container,
 		}
+	case *containerRegistryUploadSettings:
+		t.Name = "org.osbuild.container-registry"
+		t.Options = &target.ContainerRegistryTargetOptions{
+			Filename:   imageType.Filename(),
+			Registry:   options.Registry,
+			Repository: options.Repository,
+			Tag:        options.Tag,
+			Username:   options.Username,
+			Password:   options.Password,
+			AuthFile:   options.AuthFile,
+		}
 	}
 
 	return &t
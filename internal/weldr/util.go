@@ -1,9 +1,15 @@
 package weldr
 
 import (
+	"encoding/json"
 	"errors"
 	"net/url"
 	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/osbuild/osbuild-composer/internal/blueprint"
+	"github.com/osbuild/osbuild-composer/internal/common"
 )
 
 func parseOffsetAndLimit(query url.Values) (uint, uint, error) {
@@ -33,3 +39,36 @@ func min(a, b uint) uint {
 	}
 	return b
 }
+
+// customizationsSections splits blueprint customizations into their top-level
+// sections (as named in the blueprint), each marshalled to JSON.
+func customizationsSections(c *blueprint.Customizations) map[string]json.RawMessage {
+	sections := map[string]json.RawMessage{}
+	if c == nil {
+		return sections
+	}
+
+	data, err := json.Marshal(c)
+	common.PanicOnError(err)
+	err = json.Unmarshal(data, &sections)
+	common.PanicOnError(err)
+
+	return sections
+}
+
+// containsPackage returns true if the package is in the list of package
+// names, with or without a version.
+func containsPackage(packages []string, name string) bool {
+	for _, pkg := range packages {
+		if pkg == name {
+			return true
+		}
+		if strings.HasPrefix(pkg, name+"-") {
+			version := []rune(strings.TrimPrefix(pkg, name+"-"))
+			if len(version) > 0 && (unicode.IsDigit(version[0]) || version[0] == '*') {
+				return true
+			}
+		}
+	}
+	return false
+}
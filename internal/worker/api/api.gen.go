@@ -23,8 +23,9 @@ type RequestJobJSONBody struct {
 
 // UpdateJobJSONBody defines parameters for UpdateJob.
 type UpdateJobJSONBody struct {
-	Result interface{} `json:"result"`
-	Status string      `json:"status"`
+	Result        interface{}    `json:"result"`
+	Status        string         `json:"status"`
+	TargetResults *[]interface{} `json:"target_results,omitempty"`
 }
 
 // RequestJobRequestBody defines body for RequestJob for application/json ContentType.
@@ -47,6 +48,9 @@ type ServerInterface interface {
 	// Upload an artifact
 	// (PUT /jobs/{token}/artifacts/{name})
 	UploadJobArtifact(ctx echo.Context, token string, name string) error
+	// Download an input artifact
+	// (GET /jobs/{token}/inputs/{name})
+	GetJobInput(ctx echo.Context, token string, name string) error
 	// status
 	// (GET /status)
 	GetStatus(ctx echo.Context) error
@@ -122,6 +126,30 @@ func (w *ServerInterfaceWrapper) UploadJobArtifact(ctx echo.Context) error {
 	return err
 }
 
+// GetJobInput converts echo context to params.
+func (w *ServerInterfaceWrapper) GetJobInput(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "token" -------------
+	var token string
+
+	err = runtime.BindStyledParameter("simple", false, "token", ctx.Param("token"), &token)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter token: %s", err))
+	}
+
+	// ------------- Path parameter "name" -------------
+	var name string
+
+	err = runtime.BindStyledParameter("simple", false, "name", ctx.Param("name"), &name)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter name: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetJobInput(ctx, token, name)
+	return err
+}
+
 // GetStatus converts echo context to params.
 func (w *ServerInterfaceWrapper) GetStatus(ctx echo.Context) error {
 	var err error
@@ -157,6 +185,7 @@ func RegisterHandlers(router EchoRouter, si ServerInterface) {
 	router.GET("/jobs/:token", wrapper.GetJob)
 	router.PATCH("/jobs/:token", wrapper.UpdateJob)
 	router.PUT("/jobs/:token/artifacts/:name", wrapper.UploadJobArtifact)
+	router.GET("/jobs/:token/inputs/:name", wrapper.GetJobInput)
 	router.GET("/status", wrapper.GetStatus)
 
 }
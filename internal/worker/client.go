@@ -28,9 +28,11 @@ type Client struct {
 type Job interface {
 	Id() uuid.UUID
 	OSBuildArgs() (distro.Manifest, []*target.Target, error)
-	Update(status common.ImageBuildState, result *osbuild.Result) error
+	OSBuildInputs() ([]OSBuildJobInput, error)
+	Update(status common.ImageBuildState, result *osbuild.Result, targetResults []*target.TargetResult) error
 	Canceled() (bool, error)
 	UploadArtifact(name string, reader io.Reader) error
+	DownloadInput(name string, writer io.Writer) error
 }
 
 type job struct {
@@ -38,6 +40,7 @@ type job struct {
 	id               uuid.UUID
 	location         string
 	artifactLocation string
+	inputLocation    string
 	jobType          string
 	args             json.RawMessage
 }
@@ -126,6 +129,11 @@ func (c *Client) RequestJob() (Job, error) {
 		return nil, fmt.Errorf("error parsing artifact location url in response: %v", err)
 	}
 
+	inputLocation, err := c.server.Parse(jr.InputLocation)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing input location url in response: %v", err)
+	}
+
 	return &job{
 		requester:        c.requester,
 		id:               jr.Id,
@@ -133,6 +141,7 @@ func (c *Client) RequestJob() (Job, error) {
 		args:             jr.Args,
 		location:         location.String(),
 		artifactLocation: artifactLocation.String(),
+		inputLocation:    inputLocation.String(),
 	}, nil
 }
 
@@ -154,11 +163,30 @@ func (j *job) OSBuildArgs() (distro.Manifest, []*target.Target, error) {
 	return args.Manifest, args.Targets, nil
 }
 
-func (j *job) Update(status common.ImageBuildState, result *osbuild.Result) error {
+func (j *job) OSBuildInputs() ([]OSBuildJobInput, error) {
+	if j.jobType != "osbuild" {
+		return nil, errors.New("not an osbuild job")
+	}
+
+	var args OSBuildJob
+	err := json.Unmarshal(j.args, &args)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing osbuild job arguments: %v", err)
+	}
+
+	return args.Inputs, nil
+}
+
+func (j *job) Update(status common.ImageBuildState, result *osbuild.Result, targetResults []*target.TargetResult) error {
 	var buf bytes.Buffer
+	results := make([]interface{}, len(targetResults))
+	for i, r := range targetResults {
+		results[i] = r
+	}
 	err := json.NewEncoder(&buf).Encode(api.UpdateJobJSONRequestBody{
-		Result: result,
-		Status: status.ToString(),
+		Result:        result,
+		Status:        status.ToString(),
+		TargetResults: &results,
 	})
 	if err != nil {
 		panic(err)
@@ -238,6 +266,39 @@ func (j *job) UploadArtifact(name string, reader io.Reader) error {
 	return nil
 }
 
+func (j *job) DownloadInput(name string, writer io.Writer) error {
+	if j.inputLocation == "" {
+		return fmt.Errorf("server does not provide inputs for this job")
+	}
+
+	loc, err := url.Parse(j.inputLocation)
+	if err != nil {
+		return fmt.Errorf("error parsing job location: %v", err)
+	}
+
+	loc, err = loc.Parse(url.PathEscape(name))
+	if err != nil {
+		panic(err)
+	}
+
+	response, err := j.requester.Get(loc.String())
+	if err != nil {
+		return fmt.Errorf("error downloading input: %v", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return errorFromResponse(response, "error downloading input")
+	}
+
+	_, err = io.Copy(writer, response.Body)
+	if err != nil {
+		return fmt.Errorf("error downloading input: %v", err)
+	}
+
+	return nil
+}
+
 // Parses an api.Error from a response and returns it as a golang error. Other
 // errors, such failing to parse the response, are returned as golang error as
 // well. If client code expects an error, it gets one.
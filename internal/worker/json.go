@@ -15,12 +15,22 @@ import (
 //
 
 type OSBuildJob struct {
-	Manifest distro.Manifest  `json:"manifest"`
-	Targets  []*target.Target `json:"targets,omitempty"`
+	Manifest distro.Manifest   `json:"manifest"`
+	Targets  []*target.Target  `json:"targets,omitempty"`
+	Inputs   []OSBuildJobInput `json:"inputs,omitempty"`
+}
+
+// An OSBuildJobInput is an artifact of another job, which the job depends
+// on. The worker downloads it before running osbuild and resolves it in the
+// manifest, see osbuild.InputChecksum().
+type OSBuildJobInput struct {
+	JobID uuid.UUID `json:"job_id"`
+	Name  string    `json:"name"`
 }
 
 type OSBuildJobResult struct {
-	OSBuildOutput *osbuild.Result `json:"osbuild_output,omitempty"`
+	OSBuildOutput *osbuild.Result        `json:"osbuild_output,omitempty"`
+	TargetResults []*target.TargetResult `json:"target_results,omitempty"`
 }
 
 //
@@ -35,6 +45,7 @@ type requestJobResponse struct {
 	Id               uuid.UUID       `json:"id"`
 	Location         string          `json:"location"`
 	ArtifactLocation string          `json:"artifact_location"`
+	InputLocation    string          `json:"input_location"`
 	Type             string          `json:"type"`
 	Args             json.RawMessage `json:"args,omitempty"`
 }
@@ -44,8 +55,9 @@ type getJobResponse struct {
 }
 
 type updateJobRequest struct {
-	Status common.ImageBuildState `json:"status"`
-	Result *osbuild.Result        `json:"result"`
+	Status        common.ImageBuildState `json:"status"`
+	Result        *osbuild.Result        `json:"result"`
+	TargetResults []*target.TargetResult `json:"target_results"`
 }
 
 type updateJobResponse struct {
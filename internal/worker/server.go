@@ -35,12 +35,26 @@ type Server struct {
 	// race-free uploading of artifacts and makes restarting composer more
 	// robust (workers from an old run cannot report results for jobs
 	// composer thinks are not running).
-	// This map maps these tokens to job ids. Artifacts are stored in
-	// `$STATE_DIRECTORY/artifacts/tmp/$TOKEN` while the worker is running,
-	// and renamed to `$STATE_DIRECTORY/artifacts/$JOB_ID` once the job is
-	// reported as done.
-	running      map[uuid.UUID]uuid.UUID
+	// This map maps these tokens to job ids and the inputs of the jobs.
+	// Artifacts are stored in `$STATE_DIRECTORY/artifacts/tmp/$TOKEN`
+	// while the worker is running, and renamed to
+	// `$STATE_DIRECTORY/artifacts/$JOB_ID` once the job is reported as
+	// done.
+	running      map[uuid.UUID]runningJob
 	runningMutex sync.Mutex
+
+	finishedHandlers []JobFinishedFunc
+}
+
+// A JobFinishedFunc is called with the id, the targets and the result of a
+// finished job. It is called while the worker waits for the response, so
+// it must not block.
+type JobFinishedFunc func(id uuid.UUID, targets []*target.Target, result *OSBuildJobResult)
+
+type runningJob struct {
+	id      uuid.UUID
+	inputs  []OSBuildJobInput
+	targets []*target.Target
 }
 
 type JobStatus struct {
@@ -53,12 +67,13 @@ type JobStatus struct {
 }
 
 var ErrTokenNotExist = errors.New("worker token does not exist")
+var ErrInputNotExist = errors.New("job input does not exist")
 
 func NewServer(logger *log.Logger, jobs jobqueue.JobQueue, artifactsDir string) *Server {
 	s := &Server{
 		jobs:         jobs,
 		artifactsDir: artifactsDir,
-		running:      make(map[uuid.UUID]uuid.UUID),
+		running:      make(map[uuid.UUID]runningJob),
 	}
 
 	e := echo.New()
@@ -89,12 +104,25 @@ func (s *Server) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
 }
 
 func (s *Server) Enqueue(arch string, manifest distro.Manifest, targets []*target.Target) (uuid.UUID, error) {
+	return s.EnqueueWithInputs(arch, manifest, targets, nil)
+}
+
+// EnqueueWithInputs enqueues an osbuild job, which takes artifacts of other
+// jobs as inputs. The job depends on these jobs and is not run before all of
+// them have finished.
+func (s *Server) EnqueueWithInputs(arch string, manifest distro.Manifest, targets []*target.Target, inputs []OSBuildJobInput) (uuid.UUID, error) {
 	job := OSBuildJob{
 		Manifest: manifest,
 		Targets:  targets,
+		Inputs:   inputs,
 	}
 
-	return s.jobs.Enqueue("osbuild:"+arch, job, nil)
+	var dependencies []uuid.UUID
+	for _, input := range inputs {
+		dependencies = append(dependencies, input.JobID)
+	}
+
+	return s.jobs.Enqueue("osbuild:"+arch, job, dependencies)
 }
 
 func (s *Server) JobStatus(id uuid.UUID) (*JobStatus, error) {
@@ -132,8 +160,9 @@ func (s *Server) Cancel(id uuid.UUID) error {
 	return s.jobs.CancelJob(id)
 }
 
-// Provides access to artifacts of a job. Returns an io.Reader for the artifact
-// and the artifact's size.
+// Provides access to artifacts of a job. A job can upload several artifacts,
+// which are distinguished by `name`. Returns an io.Reader for the artifact and
+// the artifact's size.
 func (s *Server) JobArtifact(id uuid.UUID, name string) (io.Reader, int64, error) {
 	status, err := s.JobStatus(id)
 	if err != nil {
@@ -158,6 +187,27 @@ func (s *Server) JobArtifact(id uuid.UUID, name string) (io.Reader, int64, error
 	return f, info.Size(), nil
 }
 
+// Provides access to the input artifact `name` of the job that is running
+// with `token`. Returns an io.Reader for the artifact and the artifact's
+// size.
+func (s *Server) JobInput(token uuid.UUID, name string) (io.Reader, int64, error) {
+	s.runningMutex.Lock()
+	job, ok := s.running[token]
+	s.runningMutex.Unlock()
+
+	if !ok {
+		return nil, 0, ErrTokenNotExist
+	}
+
+	for _, input := range job.inputs {
+		if input.Name == name {
+			return s.JobArtifact(input.JobID, input.Name)
+		}
+	}
+
+	return nil, 0, ErrInputNotExist
+}
+
 // Deletes all artifacts for job `id`.
 func (s *Server) DeleteArtifacts(id uuid.UUID) error {
 	status, err := s.JobStatus(id)
@@ -193,7 +243,11 @@ func (s *Server) RequestOSBuildJob(ctx context.Context, arch string) (uuid.UUID,
 
 	s.runningMutex.Lock()
 	defer s.runningMutex.Unlock()
-	s.running[token] = jobId
+	s.running[token] = runningJob{
+		id:      jobId,
+		inputs:  args.Inputs,
+		targets: args.Targets,
+	}
 
 	return token, jobId, &args, nil
 }
@@ -202,22 +256,43 @@ func (s *Server) RunningJob(token uuid.UUID) (uuid.UUID, error) {
 	s.runningMutex.Lock()
 	defer s.runningMutex.Unlock()
 
-	jobId, ok := s.running[token]
+	job, ok := s.running[token]
 	if !ok {
 		return uuid.Nil, ErrTokenNotExist
 	}
 
-	return jobId, nil
+	return job.id, nil
+}
+
+// OnJobFinished registers f to be called whenever a job finished. At that
+// point, the job's artifacts are available from JobArtifact(). Handlers must
+// be registered before the server starts serving workers.
+func (s *Server) OnJobFinished(f JobFinishedFunc) {
+	s.finishedHandlers = append(s.finishedHandlers, f)
 }
 
 func (s *Server) FinishJob(token uuid.UUID, result *OSBuildJobResult) error {
+	job, err := s.finishJob(token, result)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range s.finishedHandlers {
+		f(job.id, job.targets, result)
+	}
+
+	return nil
+}
+
+func (s *Server) finishJob(token uuid.UUID, result *OSBuildJobResult) (*runningJob, error) {
 	s.runningMutex.Lock()
 	defer s.runningMutex.Unlock()
 
-	jobId, ok := s.running[token]
+	job, ok := s.running[token]
 	if !ok {
-		return ErrTokenNotExist
+		return nil, ErrTokenNotExist
 	}
+	jobId := job.id
 
 	// Always delete the running job, even if there are errors finishing
 	// the job, because callers won't call this a second time on error.
@@ -225,7 +300,7 @@ func (s *Server) FinishJob(token uuid.UUID, result *OSBuildJobResult) error {
 
 	err := s.jobs.FinishJob(jobId, result)
 	if err != nil {
-		return fmt.Errorf("error finishing job: %v", err)
+		return nil, fmt.Errorf("error finishing job: %v", err)
 	}
 
 	// Move artifacts from the temporary location to the final job
@@ -238,7 +313,7 @@ func (s *Server) FinishJob(token uuid.UUID, result *OSBuildJobResult) error {
 		}
 	}
 
-	return nil
+	return &job, nil
 }
 
 // apiHandlers implements api.ServerInterface - the http api route handlers
@@ -279,6 +354,7 @@ func (h *apiHandlers) RequestJob(ctx echo.Context) error {
 		Id:               jobId,
 		Location:         fmt.Sprintf("%s/jobs/%v", api.BasePath, token),
 		ArtifactLocation: fmt.Sprintf("%s/jobs/%v/artifacts/", api.BasePath, token),
+		InputLocation:    fmt.Sprintf("%s/jobs/%v/inputs/", api.BasePath, token),
 		Type:             "osbuild",
 		Args:             serializedArgs,
 	})
@@ -333,7 +409,7 @@ func (h *apiHandlers) UpdateJob(ctx echo.Context, idstr string) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "setting status of a job to waiting or running is not supported")
 	}
 
-	err = h.server.FinishJob(token, &OSBuildJobResult{OSBuildOutput: body.Result})
+	err = h.server.FinishJob(token, &OSBuildJobResult{OSBuildOutput: body.Result, TargetResults: body.TargetResults})
 	if err != nil {
 		switch err {
 		case ErrTokenNotExist:
@@ -366,6 +442,7 @@ func (h *apiHandlers) UploadJobArtifact(ctx echo.Context, tokenstr string, name
 	if err != nil {
 		return fmt.Errorf("cannot create artifact file: %v", err)
 	}
+	defer f.Close()
 
 	_, err = io.Copy(f, request.Body)
 	if err != nil {
@@ -375,6 +452,28 @@ func (h *apiHandlers) UploadJobArtifact(ctx echo.Context, tokenstr string, name
 	return ctx.NoContent(http.StatusOK)
 }
 
+func (h *apiHandlers) GetJobInput(ctx echo.Context, tokenstr string, name string) error {
+	token, err := uuid.Parse(tokenstr)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "cannot parse job token")
+	}
+
+	reader, _, err := h.server.JobInput(token, name)
+	if err != nil {
+		switch err {
+		case ErrTokenNotExist, ErrInputNotExist:
+			return echo.NewHTTPError(http.StatusNotFound, "not found")
+		default:
+			return err
+		}
+	}
+	if closer, ok := reader.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	return ctx.Stream(http.StatusOK, "application/octet-stream", reader)
+}
+
 // A simple echo.Binder(), which only accepts application/json, but is more
 // strict than echo's DefaultBinder. It does not handle binding query
 // parameters either.
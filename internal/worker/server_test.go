@@ -3,7 +3,10 @@ package worker_test
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"os"
+	"path"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -11,6 +14,8 @@ import (
 	"github.com/osbuild/osbuild-composer/internal/distro"
 	"github.com/osbuild/osbuild-composer/internal/distro/fedoratest"
 	"github.com/osbuild/osbuild-composer/internal/jobqueue/testjobqueue"
+	"github.com/osbuild/osbuild-composer/internal/osbuild"
+	"github.com/osbuild/osbuild-composer/internal/target"
 	"github.com/osbuild/osbuild-composer/internal/test"
 	"github.com/osbuild/osbuild-composer/internal/worker"
 )
@@ -68,7 +73,7 @@ func TestCreate(t *testing.T) {
 	require.NoError(t, err)
 
 	test.TestRoute(t, server, false, "POST", "/api/worker/v1/jobs", `{"types":["osbuild"],"arch":"x86_64"}`, http.StatusCreated,
-		`{"type":"osbuild","args":{"manifest":{"pipeline":{},"sources":{}}}}`, "id", "location", "artifact_location")
+		`{"type":"osbuild","args":{"manifest":{"pipeline":{},"sources":{}}}}`, "id", "location", "artifact_location", "input_location")
 }
 
 func TestCancel(t *testing.T) {
@@ -100,3 +105,91 @@ func TestCancel(t *testing.T) {
 	test.TestRoute(t, server, false, "GET", fmt.Sprintf("/api/worker/v1/jobs/%s", token), `{}`, http.StatusOK,
 		`{"canceled":true}`)
 }
+
+func TestInputs(t *testing.T) {
+	distroStruct := fedoratest.New()
+	arch, err := distroStruct.GetArch("x86_64")
+	if err != nil {
+		t.Fatalf("error getting arch from distro")
+	}
+	imageType, err := arch.GetImageType("qcow2")
+	if err != nil {
+		t.Fatalf("error getting image type from arch")
+	}
+	manifest, err := imageType.Manifest(nil, distro.ImageOptions{Size: imageType.Size(0)}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("error creating osbuild manifest")
+	}
+
+	artifactsDir, err := ioutil.TempDir("", "worker-test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(artifactsDir)
+
+	server := worker.NewServer(nil, testjobqueue.New(), artifactsDir)
+
+	dependency, err := server.Enqueue(arch.Name(), manifest, nil)
+	require.NoError(t, err)
+
+	inputs := []worker.OSBuildJobInput{{JobID: dependency, Name: "commit.tar"}}
+	jobId, err := server.EnqueueWithInputs(arch.Name(), manifest, nil, inputs)
+	require.NoError(t, err)
+
+	token, j, _, err := server.RequestOSBuildJob(context.Background(), arch.Name())
+	require.NoError(t, err)
+	require.Equal(t, dependency, j)
+
+	err = ioutil.WriteFile(path.Join(artifactsDir, "tmp", token.String(), "commit.tar"), []byte("commit"), 0600)
+	require.NoError(t, err)
+
+	err = server.FinishJob(token, &worker.OSBuildJobResult{OSBuildOutput: &osbuild.Result{Success: true}})
+	require.NoError(t, err)
+
+	token, j, args, err := server.RequestOSBuildJob(context.Background(), arch.Name())
+	require.NoError(t, err)
+	require.Equal(t, jobId, j)
+	require.Equal(t, inputs, args.Inputs)
+
+	test.TestRoute(t, server, false, "GET", fmt.Sprintf("/api/worker/v1/jobs/%s/inputs/foo", token), ``, http.StatusNotFound, `{}`, "message")
+	test.TestRoute(t, server, false, "GET", "/api/worker/v1/jobs/aaaaaaaa-aaaa-aaaa-aaaa-aaaaaaaaaaaa/inputs/commit.tar", ``, http.StatusNotFound, `{}`, "message")
+
+	reader, _, err := server.JobInput(token, "commit.tar")
+	require.NoError(t, err)
+	content, err := ioutil.ReadAll(reader)
+	require.NoError(t, err)
+	require.Equal(t, []byte("commit"), content)
+}
+
+func TestTargetResults(t *testing.T) {
+	distroStruct := fedoratest.New()
+	arch, err := distroStruct.GetArch("x86_64")
+	if err != nil {
+		t.Fatalf("error getting arch from distro")
+	}
+	imageType, err := arch.GetImageType("qcow2")
+	if err != nil {
+		t.Fatalf("error getting image type from arch")
+	}
+	manifest, err := imageType.Manifest(nil, distro.ImageOptions{Size: imageType.Size(0)}, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("error creating osbuild manifest")
+	}
+	server := worker.NewServer(nil, testjobqueue.New(), "")
+
+	jobId, err := server.Enqueue(arch.Name(), manifest, nil)
+	require.NoError(t, err)
+
+	token, j, _, err := server.RequestOSBuildJob(context.Background(), arch.Name())
+	require.NoError(t, err)
+	require.Equal(t, jobId, j)
+
+	const digest = "sha256:6d3b1b9c0a8a3f0e3c9a8b7d6e5f4a3b2c1d0e9f8a7b6c5d4e3f2a1b0c9d8e7f"
+	test.TestRoute(t, server, false, "PATCH", fmt.Sprintf("/api/worker/v1/jobs/%s", token),
+		`{"status":"FINISHED","result":{"success":true},"target_results":[{"name":"org.osbuild.container-registry","options":{"digest":"`+digest+`"}}]}`,
+		http.StatusOK, `{}`)
+
+	status, err := server.JobStatus(jobId)
+	require.NoError(t, err)
+	require.Equal(t, []*target.TargetResult{
+		target.NewContainerRegistryTargetResult(&target.ContainerRegistryTargetResultOptions{Digest: digest}),
+	}, status.Result.TargetResults)
+}